@@ -0,0 +1,154 @@
+package adspath
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// GUIDs of the well-known objects of a domain, as listed in the
+// wellKnownObjects and otherWellKnownObjects attributes of the domain head.
+// They are used with WellKnownPath to bind to an object regardless of its
+// name.
+const (
+	WellKnownUsers                     = "a9d1ca15768811d1aded00c04fd8d5cd"
+	WellKnownComputers                 = "aa312825768811d1aded00c04fd8d5cd"
+	WellKnownSystem                    = "ab1d30f3768811d1aded00c04fd8d5cd"
+	WellKnownDomainControllers         = "a361b2ffffd211d1aa4b00c04fd7d83a"
+	WellKnownInfrastructure            = "2fbac1870ade11d297c400c04fd8d5cd"
+	WellKnownDeletedObjects            = "18e2ea80684f11d2b9aa00c04f79f805"
+	WellKnownLostAndFound              = "ab8153b7768811d1aded00c04fd8d5cd"
+	WellKnownForeignSecurityPrincipals = "22b70c67d56e4efb91e9300fca3dc1aa"
+	WellKnownProgramData               = "09460c08ae1e4a4ea0f64aee7daa1e5a"
+	WellKnownMicrosoftProgramData      = "f4be92a4c777485e878e9421d53087db"
+	WellKnownNTDSQuotas                = "6227f0af1fc2410d8e3bb10615bb5b0f"
+	WellKnownKeys                      = "683a24e2e8164bd3af86ac3c2cf3f981"
+	WellKnownManagedServiceAccounts    = "1eb93889e40c45df9f0c64d23bbb6237"
+)
+
+// GUIDPath returns the path that binds to the object with the given
+// objectGUID, in the form "LDAP://host/<GUID=...>". If host is empty, the
+// path uses serverless binding.
+func GUIDPath(scheme, host string, guid uuid.UUID) *Path {
+	b := WindowsGUID(guid)
+	return &Path{Scheme: scheme, Host: host, Path: "<GUID=" + hex.EncodeToString(b[:]) + ">"}
+}
+
+// SIDPath returns the path that binds to the object with the given objectSid,
+// in the form "LDAP://host/<SID=S-1-5-...>". If host is empty, the path uses
+// serverless binding.
+func SIDPath(scheme, host, sid string) *Path {
+	return &Path{Scheme: scheme, Host: host, Path: "<SID=" + sid + ">"}
+}
+
+// WellKnownPath returns the path that binds to the well-known object with the
+// given GUID in the domain or naming context with the given distinguished
+// name, in the form "LDAP://host/<WKGUID=...,DC=example,DC=com>". The GUID is
+// one of the WellKnown values. If host is empty, the path uses serverless
+// binding.
+func WellKnownPath(scheme, host, guid, dn string) *Path {
+	return &Path{Scheme: scheme, Host: host, Path: "<WKGUID=" + strings.ToLower(guid) + "," + dn + ">"}
+}
+
+// GUID returns the objectGUID that the path binds to, if it is of the form
+// returned by GUIDPath. The GUID may be given either as the hexadecimal
+// encoding of the objectGUID or in its string form.
+func (p *Path) GUID() (guid uuid.UUID, ok bool) {
+	value, ok := p.binding("GUID")
+	if !ok {
+		return uuid.UUID{}, false
+	}
+	if len(value) == 32 {
+		b, err := hex.DecodeString(value)
+		if err != nil {
+			return uuid.UUID{}, false
+		}
+		return WindowsGUID(uuid.UUID(b)), true
+	}
+	guid, err := uuid.Parse(strings.Trim(value, "{}"))
+	if err != nil {
+		return uuid.UUID{}, false
+	}
+	return guid, true
+}
+
+// SID returns the objectSid that the path binds to in string form, if it is
+// of the form returned by SIDPath. SIDs that are given in the path as the
+// hexadecimal encoding of the objectSid are converted to string form.
+func (p *Path) SID() (sid string, ok bool) {
+	value, ok := p.binding("SID")
+	if !ok {
+		return "", false
+	}
+	if strings.HasPrefix(strings.ToUpper(value), "S-") {
+		return value, true
+	}
+	b, err := hex.DecodeString(value)
+	if err != nil {
+		return "", false
+	}
+	return FormatSID(b)
+}
+
+// WellKnown returns the well-known object GUID and the distinguished name of
+// the container that the path binds to, if it is of the form returned by
+// WellKnownPath.
+func (p *Path) WellKnown() (guid, dn string, ok bool) {
+	value, ok := p.binding("WKGUID")
+	if !ok {
+		return "", "", false
+	}
+	guid, dn, ok = strings.Cut(value, ",")
+	if !ok || len(guid) != 32 {
+		return "", "", false
+	}
+	if _, err := hex.DecodeString(guid); err != nil {
+		return "", "", false
+	}
+	return strings.ToLower(guid), dn, true
+}
+
+// binding returns the value of a path of the form "<NAME=value>".
+func (p *Path) binding(name string) (string, bool) {
+	path := p.Path
+	if len(path) < len(name)+3 || path[0] != '<' || path[len(path)-1] != '>' {
+		return "", false
+	}
+	if !strings.EqualFold(path[1:len(name)+1], name) || path[len(name)+1] != '=' {
+		return "", false
+	}
+	return path[len(name)+2 : len(path)-1], true
+}
+
+// WindowsGUID converts a GUID between the order of its string form and the
+// order in which Windows stores it, as in the objectGUID attribute, in which
+// the first three fields are little-endian. Converting twice returns the
+// original GUID.
+func WindowsGUID(u uuid.UUID) uuid.UUID {
+	u[0], u[1], u[2], u[3] = u[3], u[2], u[1], u[0]
+	u[4], u[5] = u[5], u[4]
+	u[6], u[7] = u[7], u[6]
+	return u
+}
+
+// FormatSID returns the string form of a binary security identifier, as held
+// by the objectSid attribute, such as "S-1-5-32-544". It reports false if b
+// is not a security identifier of revision 1 whose length matches its number
+// of sub-authorities.
+func FormatSID(b []byte) (string, bool) {
+	if len(b) < 8 || b[0] != 1 || len(b) != 8+4*int(b[1]) {
+		return "", false
+	}
+	var authority uint64
+	for _, c := range b[2:8] {
+		authority = authority<<8 | uint64(c)
+	}
+	s := "S-1-" + strconv.FormatUint(authority, 10)
+	for i := 8; i < len(b); i += 4 {
+		s += "-" + strconv.FormatUint(uint64(binary.LittleEndian.Uint32(b[i:])), 10)
+	}
+	return s, true
+}
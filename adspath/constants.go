@@ -10,6 +10,14 @@ var (
 	// path that has a user information section. User info cannot be specified in
 	// ADS paths.
 	ErrUserInfoNotPermitted = errors.New("The URL contains a username or password, which is not permitted within Active Directory paths.")
+	// ErrInvalidLDAPURL is returned when an attempt is made to parse a string
+	// that is not a valid LDAP URL.
+	ErrInvalidLDAPURL = errors.New("invalid LDAP URL")
+	// ErrQueryNotPermitted is returned when an attempt is made to parse an
+	// LDAP URL that has a query, such as the attributes and scope in
+	// "ldap://host/DC=example,DC=com?cn?sub", as a path. ADS paths cannot
+	// express a query. Such URLs are parsed with ParseLDAPURL.
+	ErrQueryNotPermitted = errors.New("The path contains an LDAP URL query, which is not permitted within Active Directory paths; use ParseLDAPURL.")
 )
 
 const (
@@ -0,0 +1,157 @@
+package adspath
+
+import "strings"
+
+// FromDN returns the path of the object with the given distinguished name,
+// such as "LDAP://host/CN=a\/b,DC=example,DC=com" for the distinguished name
+// "CN=a/b,DC=example,DC=com". Forward slashes in the distinguished name are
+// escaped. If host is empty, the path uses serverless binding.
+func FromDN(scheme, host, dn string) *Path {
+	return &Path{Scheme: scheme, Host: host, Path: EscapeDN(dn)}
+}
+
+// DN returns the distinguished name held by an LDAP or GC path, with the
+// escaping of forward slashes removed.
+func (p *Path) DN() string {
+	return UnescapeDN(p.Path)
+}
+
+// EqualDN reports whether a and b are the same distinguished name, as the
+// directory compares them: without regard to case, to the escaping of
+// forward slashes or to insignificant spaces around components.
+func EqualDN(a, b string) bool {
+	return strings.EqualFold(normalizeDN(EscapeDN(a)), normalizeDN(EscapeDN(b)))
+}
+
+// WithinDN reports whether the distinguished name dn is base or is below it.
+// The names are compared component by component, as the directory compares
+// them, so that an escaped comma within a value is not mistaken for the
+// boundary between two components.
+func WithinDN(dn, base string) bool {
+	a, b := splitDN(normalizeDN(EscapeDN(dn))), splitDN(normalizeDN(EscapeDN(base)))
+	if len(b) > len(a) {
+		return false
+	}
+	a = a[len(a)-len(b):]
+	for i := range b {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// EscapeDN escapes the forward slashes in a distinguished name with a
+// backslash so that it can be used in an ADsPath, as IADsPathname does.
+// Slashes that are already escaped are left as they are.
+func EscapeDN(dn string) string {
+	if !strings.Contains(dn, "/") {
+		return dn
+	}
+	var b strings.Builder
+	for i := 0; i < len(dn); i++ {
+		switch c := dn[i]; c {
+		case '\\':
+			b.WriteByte(c)
+			if i+1 < len(dn) {
+				i++
+				b.WriteByte(dn[i])
+			}
+		case '/':
+			b.WriteString(`\/`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// UnescapeDN removes the escaping of forward slashes that EscapeDN adds to a
+// distinguished name. Other escape sequences are left as they are.
+func UnescapeDN(path string) string {
+	if !strings.Contains(path, `\/`) {
+		return path
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c == '\\' && i+1 < len(path) {
+			i++
+			if path[i] != '/' {
+				b.WriteByte(c)
+			}
+			b.WriteByte(path[i])
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// indexUnescaped returns the index of the first instance of c in s that is
+// not escaped with a backslash, or -1 if there is none.
+func indexUnescaped(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+	return -1
+}
+
+// splitDN splits a distinguished name into its components at the commas that
+// are not escaped.
+func splitDN(dn string) []string {
+	var rdns []string
+	for dn != "" {
+		i := indexUnescaped(dn, ',')
+		if i < 0 {
+			return append(rdns, dn)
+		}
+		rdns = append(rdns, dn[:i])
+		dn = dn[i+1:]
+	}
+	return rdns
+}
+
+// normalizeDN returns the distinguished name with the attribute types of its
+// components in upper case and the insignificant spaces around its
+// components removed.
+func normalizeDN(dn string) string {
+	var rdns []string
+	for dn != "" {
+		rdn := dn
+		if i := indexUnescaped(dn, ','); i >= 0 {
+			rdn, dn = dn[:i], dn[i+1:]
+		} else {
+			dn = ""
+		}
+		typ, value, ok := strings.Cut(rdn, "=")
+		if !ok {
+			rdns = append(rdns, strings.TrimSpace(rdn))
+			continue
+		}
+		rdns = append(rdns, strings.ToUpper(strings.TrimSpace(typ))+"="+trimValue(value))
+	}
+	return strings.Join(rdns, ",")
+}
+
+// trimValue trims the spaces around an attribute value, except for a
+// trailing space that is escaped.
+func trimValue(value string) string {
+	value = strings.TrimLeft(value, " ")
+	for strings.HasSuffix(value, " ") {
+		n := 0
+		for i := len(value) - 2; i >= 0 && value[i] == '\\'; i-- {
+			n++
+		}
+		if n%2 == 1 {
+			break
+		}
+		value = value[:len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,230 @@
+package adspath
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// Schemes of LDAP URLs.
+const (
+	// SchemeLDAP is the scheme of LDAP URLs.
+	SchemeLDAP = "ldap"
+	// SchemeLDAPS is the scheme of LDAP URLs that connect with TLS.
+	SchemeLDAPS = "ldaps"
+	// SchemeLDAPI is the scheme of LDAP URLs that connect through a local
+	// socket.
+	SchemeLDAPI = "ldapi"
+)
+
+// Scopes of LDAP URLs.
+const (
+	ScopeBase = "base"
+	ScopeOne  = "one"
+	ScopeSub  = "sub"
+)
+
+// LDAPURL is an LDAP URL as described in RFC 4516, such as
+// "ldap://dc1.example.com/DC=example,DC=com?cn,mail?sub?(objectClass=user)".
+// It describes a search of an LDAP directory.
+type LDAPURL struct {
+	// Scheme is SchemeLDAP, SchemeLDAPS or SchemeLDAPI.
+	Scheme string
+	// Host is the server in the form "host" or "host:port", or nothing for
+	// the default server. For SchemeLDAPI it is the path of the socket.
+	Host string
+	// DN is the distinguished name of the base object of the search.
+	DN string
+	// Attributes holds the names of the attributes to return. If it is empty,
+	// every attribute is returned.
+	Attributes []string
+	// Scope is ScopeBase, ScopeOne, ScopeSub or empty, which is equivalent to
+	// ScopeBase.
+	Scope string
+	// Filter is the search filter. If it is empty, every object matches.
+	Filter string
+	// Extensions holds the extensions of the URL.
+	Extensions []LDAPURLExtension
+}
+
+// LDAPURLExtension is an extension of an LDAP URL, such as "!bindname=...".
+type LDAPURLExtension struct {
+	// Critical is true if the URL must not be processed by a client that
+	// does not support the extension.
+	Critical bool
+	// Type is the name or object identifier of the extension.
+	Type string
+	// Value is the value of the extension, if any.
+	Value string
+}
+
+// ParseLDAPURL parses an LDAP URL of the form
+// "ldap://host:port/dn?attributes?scope?filter?extensions", in which every
+// component after the scheme is optional. The scheme is case-insensitive and
+// is returned in lower case, so that the LDAP scheme of an ADsPath is
+// accepted too.
+func ParseLDAPURL(rawurl string) (*LDAPURL, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %q %s", ErrInvalidLDAPURL, rawurl, reason)
+	}
+	scheme, rest, ok := strings.Cut(rawurl, "://")
+	if !ok {
+		return nil, invalid("has no scheme")
+	}
+	u := &LDAPURL{Scheme: strings.ToLower(scheme)}
+	switch u.Scheme {
+	case SchemeLDAP, SchemeLDAPS, SchemeLDAPI:
+	default:
+		return nil, invalid("does not have an LDAP scheme")
+	}
+	if strings.ContainsRune(rest, '#') {
+		return nil, ErrFragmentNotPermitted
+	}
+
+	// The hostport ends at the slash before the distinguished name or, if
+	// there is none, at the question mark before the attributes.
+	host := rest
+	if i := strings.IndexAny(rest, "/?"); i >= 0 {
+		host, rest = rest[:i], rest[i:]
+		rest = strings.TrimPrefix(rest, "/")
+	} else {
+		rest = ""
+	}
+	if strings.ContainsRune(host, '@') {
+		return nil, ErrUserInfoNotPermitted
+	}
+	var err error
+	if u.Host, err = url.PathUnescape(host); err != nil {
+		return nil, invalid("has an invalid host")
+	}
+
+	parts := strings.Split(rest, "?")
+	if len(parts) > 5 {
+		return nil, invalid("has too many components")
+	}
+	parts = append(parts, make([]string, 5-len(parts))...)
+	if u.DN, err = url.PathUnescape(parts[0]); err != nil {
+		return nil, invalid("has an invalid distinguished name")
+	}
+	if parts[1] != "" {
+		for _, attr := range strings.Split(parts[1], ",") {
+			if attr, err = url.PathUnescape(attr); err != nil || attr == "" {
+				return nil, invalid("has an invalid attribute list")
+			}
+			u.Attributes = append(u.Attributes, attr)
+		}
+	}
+	switch scope := strings.ToLower(parts[2]); scope {
+	case "", ScopeBase, ScopeOne, ScopeSub:
+		u.Scope = scope
+	default:
+		return nil, invalid("has an invalid scope")
+	}
+	if u.Filter, err = url.PathUnescape(parts[3]); err != nil {
+		return nil, invalid("has an invalid filter")
+	}
+	if parts[4] != "" {
+		for _, ext := range strings.Split(parts[4], ",") {
+			var e LDAPURLExtension
+			if strings.HasPrefix(ext, "!") {
+				e.Critical, ext = true, ext[1:]
+			}
+			typ, value, _ := strings.Cut(ext, "=")
+			if e.Type, err = url.PathUnescape(typ); err != nil || e.Type == "" {
+				return nil, invalid("has an invalid extension")
+			}
+			if e.Value, err = url.PathUnescape(value); err != nil {
+				return nil, invalid("has an invalid extension")
+			}
+			u.Extensions = append(u.Extensions, e)
+		}
+	}
+	return u, nil
+}
+
+// String returns the URL in the form described by RFC 4516, with the
+// characters that are not permitted in its components percent-encoded.
+// Trailing empty components are omitted.
+func (u *LDAPURL) String() string {
+	var b strings.Builder
+	b.WriteString(u.Scheme)
+	b.WriteString("://")
+	if u.Scheme == SchemeLDAPI {
+		b.WriteString(escapeURLComponent(u.Host, "/:"))
+	} else {
+		b.WriteString(u.Host)
+	}
+	b.WriteByte('/')
+
+	attrs := make([]string, len(u.Attributes))
+	for i, attr := range u.Attributes {
+		attrs[i] = escapeURLComponent(attr, ",")
+	}
+	exts := make([]string, len(u.Extensions))
+	for i, e := range u.Extensions {
+		if e.Critical {
+			exts[i] = "!"
+		}
+		exts[i] += escapeURLComponent(e.Type, ",=")
+		if e.Value != "" {
+			exts[i] += "=" + escapeURLComponent(e.Value, ",")
+		}
+	}
+	parts := []string{
+		escapeURLComponent(u.DN, ""),
+		strings.Join(attrs, ","),
+		u.Scope,
+		escapeURLComponent(u.Filter, ""),
+		strings.Join(exts, ","),
+	}
+	for len(parts) > 1 && parts[len(parts)-1] == "" {
+		parts = parts[:len(parts)-1]
+	}
+	b.WriteString(strings.Join(parts, "?"))
+	return b.String()
+}
+
+// ADsPath returns the ADsPath of the base object of the search, which uses
+// the LDAP provider. For SchemeLDAPS the port defaults to 636, and the
+// connection must be made with the ADS_USE_SSL flag. Local socket URLs
+// cannot be expressed as ADsPaths.
+func (u *LDAPURL) ADsPath() (*Path, error) {
+	host := u.Host
+	switch u.Scheme {
+	case SchemeLDAP:
+	case SchemeLDAPS:
+		if _, _, err := net.SplitHostPort(host); host != "" && err != nil {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), "636")
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s URLs cannot be expressed as ADsPaths", ErrInvalidLDAPURL, u.Scheme)
+	}
+	return FromDN(LDAP, host, u.DN), nil
+}
+
+// escapeURLComponent percent-encodes the characters of a component of an
+// LDAP URL that are not permitted in it, including question marks and the
+// given additional characters.
+func escapeURLComponent(s, special string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if urlSafe(c) && strings.IndexByte(special, c) < 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// urlSafe reports whether c may appear unencoded in a component of an LDAP
+// URL, other than a question mark.
+func urlSafe(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("-._~!$&'()*+,;=:@/", c) >= 0
+}
@@ -21,12 +21,28 @@ type Path struct {
 	// be in the form "host" or "host:port".
 	Host string
 	// Path is the address of the resource within the namespace. The rules for its
-	// interpretation vary between schemes.
+	// interpretation vary between schemes. For the LDAP and GC schemes it is a
+	// distinguished name in which forward slashes are escaped, as returned by
+	// EscapeDN.
 	Path string
+	// Class is the class suffix of a WinNT path, such as "user" in
+	// "WinNT://DOMAIN/jsmith,user". It is only used by the WinNT scheme.
+	Class string
 }
 
 // Parse will attempt to interpret the raw path provided as a string in URL
 // form.
+//
+// LDAP URLs with a query in their lower case form, such as
+// "ldap://host/DC=example,DC=com?cn?sub", are not ADS paths and return
+// ErrQueryNotPermitted. They are parsed with ParseLDAPURL instead. In the
+// LDAP and GC schemes of ADsPaths, a question mark is part of the
+// distinguished name.
+//
+// The scheme of the well-known namespace providers is corrected to its
+// canonical capitalization, such as "LDAP" for "ldap", because the providers
+// are registered under case-sensitive names and a path with any other
+// capitalization cannot be opened.
 func Parse(rawpath string) (path *Path, err error) {
 	var rest string
 
@@ -42,18 +58,8 @@ func Parse(rawpath string) (path *Path, err error) {
 
 	// Enforce correct capitalization of the scheme, because many ADSI schemes are
 	// case-sensitive.
-	//
-	// TODO: Decide whether it's really appropriate to do this auto-correction.
-	switch strings.ToLower(path.Scheme) {
-	case "ldap":
-		path.Scheme = LDAP
-	case "winnt":
-		path.Scheme = WinNT
-	case "iis":
-		path.Scheme = IIS
-	case "gc":
-		path.Scheme = GC
-	}
+	raw := path.Scheme
+	path.Scheme = canonicalScheme(path.Scheme)
 
 	if rest == "" {
 		// We're binding to the root of the namespace
@@ -66,8 +72,26 @@ func Parse(rawpath string) (path *Path, err error) {
 
 	rest = rest[2:]
 
-	authority, rest := split(rest, "/")
-	if rest == "" && path.Scheme == LDAP && strings.ContainsRune(authority, '=') {
+	if (raw == "ldap" || raw == "ldaps") && strings.ContainsRune(rest, '?') {
+		// Question marks need not be escaped in distinguished names, so
+		// they only start a query in the lower case form of an LDAP URL
+		return nil, ErrQueryNotPermitted
+	}
+
+	if strings.HasPrefix(rest, "<") {
+		// This is serverless binding by GUID, SID or well-known GUID, which
+		// may contain a distinguished name with slashes
+		path.Path = rest
+		return
+	}
+
+	// Slashes in distinguished names are escaped, so the host ends at the
+	// first unescaped slash
+	authority, rest := rest, ""
+	if i := indexUnescaped(authority, '/'); i >= 0 {
+		authority, rest = authority[:i], authority[i+1:]
+	}
+	if rest == "" && isDNScheme(path.Scheme) && strings.ContainsRune(authority, '=') {
 		// This is serverless LDAP binding
 		rest = authority
 		authority = ""
@@ -75,9 +99,49 @@ func Parse(rawpath string) (path *Path, err error) {
 
 	path.Host, path.Path = authority, rest
 
+	if path.Scheme == WinNT {
+		// The class suffix follows the last component
+		if path.Path != "" {
+			path.Path, path.Class = splitClass(path.Path)
+		} else {
+			path.Host, path.Class = splitClass(path.Host)
+		}
+	}
+
 	return
 }
 
+// canonicalScheme returns the scheme of a well-known namespace provider with
+// its canonical capitalization, or the scheme unchanged if it is not one.
+func canonicalScheme(scheme string) string {
+	switch strings.ToLower(scheme) {
+	case "ldap":
+		return LDAP
+	case "winnt":
+		return WinNT
+	case "iis":
+		return IIS
+	case "gc":
+		return GC
+	}
+	return scheme
+}
+
+// isDNScheme reports whether paths of the scheme hold distinguished names.
+func isDNScheme(scheme string) bool {
+	return strings.EqualFold(scheme, LDAP) || strings.EqualFold(scheme, GC)
+}
+
+// splitClass splits the class suffix from the last component of a WinNT
+// path.
+func splitClass(s string) (string, string) {
+	last := strings.LastIndexByte(s, '/')
+	if i := strings.LastIndexByte(s, ','); i > last {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
+
 func parseScheme(rawpath string) (scheme, path string, err error) {
 	for i := 0; i < len(rawpath); i++ {
 		c := rawpath[i]
@@ -102,14 +166,6 @@ func parseScheme(rawpath string) (scheme, path string, err error) {
 	return "", rawpath, nil
 }
 
-func split(input string, cut string) (string, string) {
-	i := strings.Index(input, cut)
-	if i < 0 {
-		return input, ""
-	}
-	return input[:i], input[i+len(cut):]
-}
-
 // URL converts the path to a url.URL.
 func (p *Path) URL() (u *url.URL) {
 	return &url.URL{
@@ -136,5 +192,9 @@ func (p *Path) String() string {
 		}
 	}
 	buf.WriteString(p.Path)
+	if p.Class != "" {
+		buf.WriteByte(',')
+		buf.WriteString(p.Class)
+	}
 	return buf.String()
 }
@@ -0,0 +1,78 @@
+package adspath
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseQuestionMark(t *testing.T) {
+	// A question mark in the lower case form of an LDAP URL starts a query.
+	for _, s := range []string{
+		"ldap://srv:389/DC=x?cn?sub",
+		"ldap://srv?cn",
+		"ldaps://srv/DC=example,DC=com?objectGUID?base",
+	} {
+		if _, err := Parse(s); !errors.Is(err, ErrQueryNotPermitted) {
+			t.Errorf("Parse(%q) error = %v, want ErrQueryNotPermitted", s, err)
+		}
+	}
+
+	// In ADsPaths it is part of the distinguished name.
+	for _, tt := range []struct {
+		s, host, path string
+	}{
+		{"LDAP://CN=Who?,DC=example,DC=com", "", "CN=Who?,DC=example,DC=com"},
+		{"LDAP://srv/CN=Who?,DC=example,DC=com", "srv", "CN=Who?,DC=example,DC=com"},
+		{`LDAP://srv/CN=Why\?,DC=x`, "srv", `CN=Why\?,DC=x`},
+		{"GC://srv/CN=a?b?c,DC=x", "srv", "CN=a?b?c,DC=x"},
+		{"IIS://localhost/W3SVC?", "localhost", "W3SVC?"},
+	} {
+		p, err := Parse(tt.s)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.s, err)
+			continue
+		}
+		if p.Host != tt.host || p.Path != tt.path {
+			t.Errorf("Parse(%q) = %q, %q, want %q, %q", tt.s, p.Host, p.Path, tt.host, tt.path)
+		}
+		if got := p.String(); got != tt.s {
+			t.Errorf("Parse(%q).String() = %q", tt.s, got)
+		}
+	}
+}
+
+func TestParseLDAPURL(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want LDAPURL
+	}{
+		{"ldap://srv", LDAPURL{Scheme: "ldap", Host: "srv"}},
+		{"ldap://srv?cn", LDAPURL{Scheme: "ldap", Host: "srv", Attributes: []string{"cn"}}},
+		{"ldap://srv:389/?cn,mail?SUB", LDAPURL{Scheme: "ldap", Host: "srv:389", Attributes: []string{"cn", "mail"}, Scope: ScopeSub}},
+		{"LDAP://srv:389/DC=x?cn?sub", LDAPURL{Scheme: "ldap", Host: "srv:389", DN: "DC=x", Attributes: []string{"cn"}, Scope: ScopeSub}},
+		{"ldap:///DC=example,DC=com??One?(cn=a%20b)", LDAPURL{Scheme: "ldap", DN: "DC=example,DC=com", Scope: ScopeOne, Filter: "(cn=a b)"}},
+		{"ldap://srv/CN=Who%3F,DC=x?cn", LDAPURL{Scheme: "ldap", Host: "srv", DN: "CN=Who?,DC=x", Attributes: []string{"cn"}}},
+	} {
+		u, err := ParseLDAPURL(tt.s)
+		if err != nil {
+			t.Errorf("ParseLDAPURL(%q): %v", tt.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(*u, tt.want) {
+			t.Errorf("ParseLDAPURL(%q) = %+v, want %+v", tt.s, *u, tt.want)
+		}
+	}
+
+	for _, s := range []string{
+		"http://srv/DC=x",
+		"ldap://srv/DC=x?cn?subtree",
+		"ldap://srv/DC=x?a?b?c?d?e",
+		"ldap://user@srv/DC=x",
+		"ldap://srv/DC=x#frag",
+	} {
+		if _, err := ParseLDAPURL(s); err == nil {
+			t.Errorf("ParseLDAPURL(%q) succeeded", s)
+		}
+	}
+}
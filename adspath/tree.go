@@ -0,0 +1,192 @@
+package adspath
+
+import (
+	"errors"
+	"strings"
+)
+
+// Join returns the path of a descendant of the object at the path. For the
+// LDAP and GC schemes each element is a relative distinguished name, such as
+// "OU=Sales" or "CN=Jeff Smith", in which forward slashes need not be
+// escaped. For other schemes each element is the name of a child, such as a
+// computer or user name in a WinNT path. The class suffix of the path is not
+// retained.
+//
+// Join is not meaningful for paths that bind by GUID, SID or well-known GUID.
+func (p *Path) Join(elem ...string) *Path {
+	q := *p
+	q.Class = ""
+	for _, e := range elem {
+		if e == "" {
+			continue
+		}
+		switch {
+		case isDNScheme(q.Scheme):
+			if q.Path == "" {
+				q.Path = EscapeDN(e)
+			} else {
+				q.Path = EscapeDN(e) + "," + q.Path
+			}
+		case q.Path == "" && q.Host == "":
+			q.Host = e
+		case q.Path == "":
+			q.Path = e
+		default:
+			q.Path = strings.TrimSuffix(q.Path, "/") + "/" + e
+		}
+	}
+	return &q
+}
+
+// Parent returns the path of the container of the object at the path. The
+// parent of an object at the top of a server or domain is the server or
+// domain itself, and the parent of that is the root of the namespace, such as
+// "LDAP:". Parent returns nil for the root of a namespace and for paths that
+// bind by GUID, SID or well-known GUID.
+func (p *Path) Parent() *Path {
+	if p.isBinding() {
+		return nil
+	}
+	q := *p
+	q.Class = ""
+	switch {
+	case q.Path != "" && isDNScheme(q.Scheme):
+		if i := indexUnescaped(q.Path, ','); i >= 0 {
+			q.Path = strings.TrimLeft(q.Path[i+1:], " ")
+		} else {
+			q.Path = ""
+		}
+	case q.Path != "":
+		path := strings.TrimSuffix(q.Path, "/")
+		q.Path = ""
+		if i := strings.LastIndexByte(path, '/'); i >= 0 {
+			q.Path = path[:i]
+		}
+	case q.Host != "":
+		q.Host = ""
+	default:
+		return nil
+	}
+	return &q
+}
+
+// Base returns the name of the object at the path within its container. For
+// the LDAP and GC schemes it is the relative distinguished name of the
+// object, such as "CN=Jeff Smith", without the escaping of forward slashes.
+// For other schemes it is the last component of the path, such as "jsmith"
+// for "WinNT://EXAMPLE/jsmith,user". Base returns an empty string for the
+// root of a namespace.
+func (p *Path) Base() string {
+	switch {
+	case p.isBinding():
+		return p.Path
+	case p.Path != "" && isDNScheme(p.Scheme):
+		rdn := p.Path
+		if i := indexUnescaped(rdn, ','); i >= 0 {
+			rdn = rdn[:i]
+		}
+		return UnescapeDN(strings.TrimSpace(rdn))
+	case p.Path != "":
+		path := strings.TrimSuffix(p.Path, "/")
+		return path[strings.LastIndexByte(path, '/')+1:]
+	default:
+		return p.Host
+	}
+}
+
+// Normalize returns the path in a canonical form, in which the scheme of a
+// well-known namespace provider has its canonical capitalization, the
+// attribute types of a distinguished name are in upper case, insignificant
+// spaces and trailing slashes are removed, forward slashes in a
+// distinguished name are escaped and the class suffix is in lower case. The
+// case of names is preserved.
+func (p *Path) Normalize() *Path {
+	q := *p
+	q.Scheme = canonicalScheme(q.Scheme)
+	q.Class = strings.ToLower(q.Class)
+	switch {
+	case q.isBinding():
+	case isDNScheme(q.Scheme):
+		q.Path = normalizeDN(EscapeDN(q.Path))
+	default:
+		q.Path = strings.TrimSuffix(q.Path, "/")
+	}
+	return &q
+}
+
+// Equal reports whether the paths refer to the same object through the same
+// server. The paths are compared in their normalized form without regard to
+// case. A class suffix is only compared if both paths have one.
+func (p *Path) Equal(q *Path) bool {
+	if p == nil || q == nil {
+		return p == q
+	}
+	a, b := p.Normalize(), q.Normalize()
+	if a.Class != "" && b.Class != "" && a.Class != b.Class {
+		return false
+	}
+	return a.Scheme == b.Scheme && strings.EqualFold(a.Host, b.Host) && strings.EqualFold(a.Path, b.Path)
+}
+
+// Key returns a string that identifies the object at the given ADsPath
+// regardless of case and of insignificant differences in form. For the LDAP
+// and GC schemes the server that the object is addressed through is ignored,
+// as every server holds the same distinguished names, so paths with equal
+// keys refer to the same object. A path that cannot be parsed is its own key,
+// in lower case.
+func Key(rawpath string) string {
+	p, err := Parse(rawpath)
+	if err != nil {
+		return strings.ToLower(rawpath)
+	}
+	q := p.Normalize()
+	if isDNScheme(q.Scheme) {
+		return strings.ToLower(q.Scheme + ":" + q.Path)
+	}
+	q.Class = ""
+	return strings.ToLower(q.String())
+}
+
+// NT4Name returns the Windows NT 4.0 account name of the principal at a WinNT
+// path, such as `EXAMPLE\jsmith` for "WinNT://EXAMPLE/jsmith,user" and
+// `HOST\admin` for the local account "WinNT://EXAMPLE/HOST/admin". The name
+// of a computer account, identified by the "computer" class suffix, ends with
+// a dollar sign, and the name of the domain "WinNT://EXAMPLE" is `EXAMPLE\`.
+func (p *Path) NT4Name() (string, bool) {
+	if p.Scheme != WinNT || p.Host == "" {
+		return "", false
+	}
+	parts := []string{p.Host}
+	if path := strings.Trim(p.Path, "/"); path != "" {
+		parts = append(parts, strings.Split(path, "/")...)
+	}
+	if len(parts) == 1 {
+		return parts[0] + `\`, true
+	}
+	name := parts[len(parts)-2] + `\` + parts[len(parts)-1]
+	if strings.EqualFold(p.Class, "computer") {
+		name += "$"
+	}
+	return name, true
+}
+
+// FromNT4 returns the WinNT path of the principal with the given Windows NT
+// 4.0 account name, such as `EXAMPLE\jsmith`, and class suffix, which may be
+// empty. The dollar sign that ends the name of a computer account is removed
+// if the class is "computer". It is the inverse of NT4Name for principals of
+// a domain.
+func FromNT4(name, class string) (*Path, error) {
+	domain, account, ok := strings.Cut(name, `\`)
+	if !ok || domain == "" || strings.Contains(account, `\`) {
+		return nil, errors.New("invalid NT4 account name")
+	}
+	if strings.EqualFold(class, "computer") {
+		account = strings.TrimSuffix(account, "$")
+	}
+	return &Path{Scheme: WinNT, Host: domain, Path: account, Class: class}, nil
+}
+
+// isBinding reports whether the path binds by GUID, SID or well-known GUID.
+func (p *Path) isBinding() bool {
+	return strings.HasPrefix(p.Path, "<") && strings.HasSuffix(p.Path, ">")
+}
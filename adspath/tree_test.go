@@ -0,0 +1,164 @@
+package adspath
+
+import "testing"
+
+func mustParse(t *testing.T, s string) *Path {
+	t.Helper()
+	p, err := Parse(s)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", s, err)
+	}
+	return p
+}
+
+func TestJoin(t *testing.T) {
+	for _, tt := range []struct {
+		path string
+		elem []string
+		want string
+	}{
+		{"LDAP://srv/DC=example,DC=com", []string{"OU=Sales", "CN=Jeff Smith"}, "LDAP://srv/CN=Jeff Smith,OU=Sales,DC=example,DC=com"},
+		{"LDAP://DC=example,DC=com", []string{"CN=a/b"}, `LDAP://CN=a\/b,DC=example,DC=com`},
+		{"LDAP://srv", []string{"DC=example,DC=com"}, "LDAP://srv/DC=example,DC=com"},
+		{"GC://srv/DC=example,DC=com", []string{"", "CN=Users"}, "GC://srv/CN=Users,DC=example,DC=com"},
+		{"WinNT:", []string{"EXAMPLE", "HOST", "admin"}, "WinNT://EXAMPLE/HOST/admin"},
+		{"WinNT://EXAMPLE,domain", []string{"jsmith"}, "WinNT://EXAMPLE/jsmith"},
+		{"IIS://localhost/W3SVC/", []string{"1"}, "IIS://localhost/W3SVC/1"},
+	} {
+		if got := mustParse(t, tt.path).Join(tt.elem...).String(); got != tt.want {
+			t.Errorf("Join(%q, %q) = %q, want %q", tt.path, tt.elem, got, tt.want)
+		}
+	}
+}
+
+func TestParent(t *testing.T) {
+	for _, tt := range []struct {
+		path, want string
+	}{
+		{"LDAP://srv/CN=Jeff Smith,OU=Sales,DC=example,DC=com", "LDAP://srv/OU=Sales,DC=example,DC=com"},
+		{`LDAP://srv/CN=Smith\, Jeff, OU=Sales,DC=example,DC=com`, "LDAP://srv/OU=Sales,DC=example,DC=com"},
+		{`LDAP://CN=a\/b,DC=example,DC=com`, "LDAP://DC=example,DC=com"},
+		{"LDAP://srv/DC=com", "LDAP://srv"},
+		{"LDAP://srv", "LDAP:"},
+		{"WinNT://EXAMPLE/HOST/admin,user", "WinNT://EXAMPLE/HOST"},
+		{"WinNT://EXAMPLE/jsmith", "WinNT://EXAMPLE"},
+		{"WinNT://EXAMPLE,domain", "WinNT:"},
+	} {
+		got := mustParse(t, tt.path).Parent()
+		if got == nil {
+			t.Errorf("Parent(%q) = nil, want %q", tt.path, tt.want)
+			continue
+		}
+		if got.String() != tt.want {
+			t.Errorf("Parent(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	for _, path := range []string{"LDAP:", "LDAP://<GUID=0123456789abcdef0123456789abcdef>", "LDAP://srv/<SID=S-1-5-32-544>"} {
+		if got := mustParse(t, path).Parent(); got != nil {
+			t.Errorf("Parent(%q) = %q, want nil", path, got)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for _, tt := range []struct {
+		path, want string
+	}{
+		{"ldap://srv/cn=Jeff Smith , ou=Sales,dc=example,dc=com", "LDAP://srv/CN=Jeff Smith,OU=Sales,DC=example,DC=com"},
+		{`LDAP://srv/CN=Trailing\ ,DC=example`, `LDAP://srv/CN=Trailing\ ,DC=example`},
+		{"gc://DC=example,DC=com", "GC://DC=example,DC=com"},
+		{"winnt://EXAMPLE/jsmith,User", "WinNT://EXAMPLE/jsmith,user"},
+		{"IIS://localhost/W3SVC/", "IIS://localhost/W3SVC"},
+		{"LDAP://<GUID=0123456789ABCDEF0123456789ABCDEF>", "LDAP://<GUID=0123456789ABCDEF0123456789ABCDEF>"},
+	} {
+		if got := mustParse(t, tt.path).Normalize().String(); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	// Slashes are escaped in distinguished names.
+	p := &Path{Scheme: LDAP, Path: "CN=a/b,DC=example"}
+	if got, want := p.Normalize().Path, `CN=a\/b,DC=example`; got != want {
+		t.Errorf("Normalize(%q).Path = %q, want %q", p.Path, got, want)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := mustParse(t, "LDAP://srv/CN=Jeff Smith,DC=example,DC=com")
+	for _, tt := range []struct {
+		path string
+		want bool
+	}{
+		{"ldap://SRV/cn=jeff smith, dc=example,dc=com", true},
+		{"LDAP://other/CN=Jeff Smith,DC=example,DC=com", false},
+		{"GC://srv/CN=Jeff Smith,DC=example,DC=com", false},
+	} {
+		if got := a.Equal(mustParse(t, tt.path)); got != tt.want {
+			t.Errorf("Equal(%q, %q) = %v, want %v", a, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestNT4Name(t *testing.T) {
+	for _, tt := range []struct {
+		path, want string
+		ok         bool
+	}{
+		{"WinNT://EXAMPLE/jsmith,user", `EXAMPLE\jsmith`, true},
+		{"WinNT://EXAMPLE/HOST/admin", `HOST\admin`, true},
+		{"WinNT://EXAMPLE/HOST,computer", `EXAMPLE\HOST$`, true},
+		{"WinNT://EXAMPLE", `EXAMPLE\`, true},
+		{"WinNT:", "", false},
+		{"LDAP://CN=Jeff Smith,DC=example,DC=com", "", false},
+	} {
+		got, ok := mustParse(t, tt.path).NT4Name()
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NT4Name(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFromNT4(t *testing.T) {
+	for _, tt := range []struct {
+		name, class, want string
+	}{
+		{`EXAMPLE\jsmith`, "user", "WinNT://EXAMPLE/jsmith,user"},
+		{`EXAMPLE\HOST$`, "computer", "WinNT://EXAMPLE/HOST,computer"},
+		{`EXAMPLE\jsmith`, "", "WinNT://EXAMPLE/jsmith"},
+	} {
+		p, err := FromNT4(tt.name, tt.class)
+		if err != nil {
+			t.Errorf("FromNT4(%q, %q): %v", tt.name, tt.class, err)
+			continue
+		}
+		if got := p.String(); got != tt.want {
+			t.Errorf("FromNT4(%q, %q) = %q, want %q", tt.name, tt.class, got, tt.want)
+		}
+		if name, _ := p.NT4Name(); name != tt.name {
+			t.Errorf("NT4Name(FromNT4(%q)) = %q", tt.name, name)
+		}
+	}
+	for _, name := range []string{"jsmith", `\jsmith`, `A\B\C`} {
+		if _, err := FromNT4(name, ""); err == nil {
+			t.Errorf("FromNT4(%q) succeeded", name)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		same bool
+	}{
+		{"LDAP://srv1/CN=Jeff Smith,DC=example,DC=com", "ldap://srv2/cn=jeff smith, dc=example,dc=com", true},
+		{`LDAP://CN=a\/b,DC=example`, `LDAP://srv/CN=a\/b,DC=example`, true},
+		{"LDAP://srv/CN=Jeff Smith,DC=example,DC=com", "GC://srv/CN=Jeff Smith,DC=example,DC=com", false},
+		{"WinNT://EXAMPLE/jsmith,user", "winnt://example/JSMITH", true},
+		{"WinNT://EXAMPLE/jsmith", "WinNT://OTHER/jsmith", false},
+	} {
+		if got := Key(tt.a) == Key(tt.b); got != tt.same {
+			t.Errorf("Key(%q) == Key(%q) is %v, want %v", tt.a, tt.b, got, tt.same)
+		}
+	}
+}
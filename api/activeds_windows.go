@@ -0,0 +1,22 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modactiveds    = syscall.NewLazyDLL("activeds.dll")
+	procFreeADsMem = modactiveds.NewProc("FreeADsMem")
+)
+
+// freeADsMem frees memory that was allocated by ADSI and returned to the
+// caller.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/adshlp/nf-adshlp-freeadsmem
+func freeADsMem(p unsafe.Pointer) {
+	procFreeADsMem.Call(uintptr(p))
+}
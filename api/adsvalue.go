@@ -0,0 +1,278 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// ADSValue represents the ADSVALUE structure, which holds a single attribute
+// value along with its ADSTYPE.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ns-iads-adsvalue
+type ADSValue struct {
+	Type uint32
+	_    uint32
+	data [16]byte
+}
+
+// ADSAttrInfo represents the ADS_ATTR_INFO structure, which describes an
+// attribute and its values for the IDirectoryObject interface.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ns-iads-ads_attr_info
+type ADSAttrInfo struct {
+	Name        *uint16
+	ControlCode uint32
+	Type        uint32
+	Values      *ADSValue
+	NumValues   uint32
+}
+
+// AttrModification describes a modification of an attribute that is applied
+// by IDirectoryObject.SetObjectAttributes.
+type AttrModification struct {
+	// Name is the name of the attribute.
+	Name string
+
+	// ControlCode is one of the ADS_ATTR values and determines how the
+	// values are applied.
+	ControlCode uint32
+
+	// Values holds the values to apply. Each value must be a string, bool,
+	// int, int32, uint32, int64, []byte or SecurityDescriptor.
+	Values []interface{}
+}
+
+// SecurityDescriptor holds a security descriptor in self-relative binary
+// form. It is read from and written to attributes with the
+// ADSTYPE_NT_SECURITY_DESCRIPTOR syntax, such as nTSecurityDescriptor.
+type SecurityDescriptor []byte
+
+// DNWithBinary holds a value with the ADSTYPE_DN_WITH_BINARY syntax, such as
+// the values of wellKnownObjects, which associate binary data with a
+// distinguished name.
+type DNWithBinary struct {
+	Binary []byte
+	DN     string
+}
+
+// DNWithString holds a value with the ADSTYPE_DN_WITH_STRING syntax, which
+// associates a string with a distinguished name.
+type DNWithString struct {
+	String string
+	DN     string
+}
+
+// AttrValues holds the values of an attribute that were returned by
+// IDirectoryObject.GetObjectAttributes.
+type AttrValues struct {
+	// Name is the name of the attribute.
+	Name string
+
+	// Type is the ADSTYPE of the values.
+	Type uint32
+
+	// Values holds the values of the attribute. Strings are returned as
+	// string, booleans as bool, integers as int32, large integers as int64,
+	// octet strings as []byte, times as time.Time, security descriptors as
+	// SecurityDescriptor and distinguished names with binary or string data
+	// as DNWithBinary or DNWithString. Values of other types are returned as
+	// nil.
+	Values []interface{}
+}
+
+// adsAttrInfoList holds a set of ADS_ATTR_INFO structures along with the
+// memory that they refer to. It must remain reachable for as long as the
+// structures are in use.
+type adsAttrInfoList struct {
+	infos []ADSAttrInfo
+	refs  []interface{}
+}
+
+// newADSAttrInfoList converts the given modifications into ADS_ATTR_INFO
+// structures.
+func newADSAttrInfoList(mods []AttrModification) (*adsAttrInfoList, error) {
+	list := &adsAttrInfoList{infos: make([]ADSAttrInfo, len(mods))}
+	for i, mod := range mods {
+		info := &list.infos[i]
+		info.Name = list.utf16(mod.Name)
+		info.ControlCode = mod.ControlCode
+		info.Type = ADSTYPE_CASE_IGNORE_STRING
+		if len(mod.Values) == 0 {
+			continue
+		}
+		values := make([]ADSValue, len(mod.Values))
+		for j, value := range mod.Values {
+			if err := list.set(&values[j], value); err != nil {
+				return nil, fmt.Errorf("attribute \"%s\" value %d: %v", mod.Name, j, err)
+			}
+		}
+		list.refs = append(list.refs, values)
+		info.Type = values[0].Type
+		info.Values = &values[0]
+		info.NumValues = uint32(len(values))
+	}
+	return list, nil
+}
+
+// utf16 returns a pointer to a null-terminated UTF-16 copy of s that is kept
+// alive by the list.
+func (list *adsAttrInfoList) utf16(s string) *uint16 {
+	u := utf16.Encode([]rune(s + "\x00"))
+	list.refs = append(list.refs, u)
+	return &u[0]
+}
+
+// set stores the given Go value in v.
+func (list *adsAttrInfoList) set(v *ADSValue, value interface{}) error {
+	switch x := value.(type) {
+	case string:
+		v.Type = ADSTYPE_CASE_IGNORE_STRING
+		*(**uint16)(unsafe.Pointer(&v.data[0])) = list.utf16(x)
+	case bool:
+		v.Type = ADSTYPE_BOOLEAN
+		if x {
+			*(*uint32)(unsafe.Pointer(&v.data[0])) = 1
+		}
+	case int:
+		v.Type = ADSTYPE_INTEGER
+		*(*uint32)(unsafe.Pointer(&v.data[0])) = uint32(x)
+	case int32:
+		v.Type = ADSTYPE_INTEGER
+		*(*uint32)(unsafe.Pointer(&v.data[0])) = uint32(x)
+	case uint32:
+		v.Type = ADSTYPE_INTEGER
+		*(*uint32)(unsafe.Pointer(&v.data[0])) = x
+	case int64:
+		v.Type = ADSTYPE_LARGE_INTEGER
+		*(*int64)(unsafe.Pointer(&v.data[0])) = x
+	case []byte:
+		v.Type = ADSTYPE_OCTET_STRING
+		list.setBytes(v, x)
+	case SecurityDescriptor:
+		v.Type = ADSTYPE_NT_SECURITY_DESCRIPTOR
+		list.setBytes(v, x)
+	default:
+		return errors.New("unsupported value type")
+	}
+	return nil
+}
+
+// setBytes stores a copy of b in v, which must hold an octet string or a
+// security descriptor. Both are represented by a length followed by a
+// pointer.
+func (list *adsAttrInfoList) setBytes(v *ADSValue, b []byte) {
+	*(*uint32)(unsafe.Pointer(&v.data[0])) = uint32(len(b))
+	if len(b) > 0 {
+		b = append([]byte(nil), b...)
+		list.refs = append(list.refs, b)
+		*(**byte)(unsafe.Pointer(&v.data[unsafe.Sizeof(uintptr(0))])) = &b[0]
+	}
+}
+
+// decodeAttrInfos converts ADS_ATTR_INFO structures that were allocated by
+// ADSI into Go values.
+func decodeAttrInfos(infos *ADSAttrInfo, n uint32) []AttrValues {
+	if infos == nil || n == 0 {
+		return nil
+	}
+	attrs := make([]AttrValues, 0, n)
+	for _, info := range unsafe.Slice(infos, n) {
+		attrs = append(attrs, AttrValues{
+			Name:   utf16PtrToString(info.Name),
+			Type:   info.Type,
+			Values: decodeADSValues(info.Values, info.NumValues),
+		})
+	}
+	return attrs
+}
+
+// decodeADSValues converts ADSVALUE structures that were allocated by ADSI
+// into Go values.
+func decodeADSValues(values *ADSValue, n uint32) []interface{} {
+	if values == nil || n == 0 {
+		return nil
+	}
+	slice := unsafe.Slice(values, n)
+	result := make([]interface{}, len(slice))
+	for i := range slice {
+		result[i] = slice[i].value()
+	}
+	return result
+}
+
+// value returns the Go form of the value.
+func (v *ADSValue) value() interface{} {
+	switch v.Type {
+	case ADSTYPE_DN_STRING, ADSTYPE_CASE_EXACT_STRING, ADSTYPE_CASE_IGNORE_STRING,
+		ADSTYPE_PRINTABLE_STRING, ADSTYPE_NUMERIC_STRING, ADSTYPE_OBJECT_CLASS:
+		return utf16PtrToString(*(**uint16)(unsafe.Pointer(&v.data[0])))
+	case ADSTYPE_BOOLEAN:
+		return *(*uint32)(unsafe.Pointer(&v.data[0])) != 0
+	case ADSTYPE_INTEGER:
+		return *(*int32)(unsafe.Pointer(&v.data[0]))
+	case ADSTYPE_LARGE_INTEGER:
+		return *(*int64)(unsafe.Pointer(&v.data[0]))
+	case ADSTYPE_OCTET_STRING:
+		return v.bytes()
+	case ADSTYPE_NT_SECURITY_DESCRIPTOR:
+		return SecurityDescriptor(v.bytes())
+	case ADSTYPE_UTC_TIME:
+		// SYSTEMTIME
+		st := (*[8]uint16)(unsafe.Pointer(&v.data[0]))
+		return time.Date(int(st[0]), time.Month(st[1]), int(st[3]), int(st[4]), int(st[5]), int(st[6]), int(st[7])*int(time.Millisecond), time.UTC)
+	case ADSTYPE_DN_WITH_BINARY:
+		// ADS_DN_WITH_BINARY
+		p := *(**struct {
+			Length uint32
+			Value  *byte
+			DN     *uint16
+		})(unsafe.Pointer(&v.data[0]))
+		if p == nil {
+			return nil
+		}
+		b := []byte{}
+		if p.Length > 0 && p.Value != nil {
+			b = append(b, unsafe.Slice(p.Value, p.Length)...)
+		}
+		return DNWithBinary{Binary: b, DN: utf16PtrToString(p.DN)}
+	case ADSTYPE_DN_WITH_STRING:
+		// ADS_DN_WITH_STRING
+		p := *(**struct {
+			Value *uint16
+			DN    *uint16
+		})(unsafe.Pointer(&v.data[0]))
+		if p == nil {
+			return nil
+		}
+		return DNWithString{String: utf16PtrToString(p.Value), DN: utf16PtrToString(p.DN)}
+	default:
+		return nil
+	}
+}
+
+// bytes returns a copy of the octet string or security descriptor held by
+// the value.
+func (v *ADSValue) bytes() []byte {
+	length := *(*uint32)(unsafe.Pointer(&v.data[0]))
+	ptr := *(**byte)(unsafe.Pointer(&v.data[unsafe.Sizeof(uintptr(0))]))
+	if length == 0 || ptr == nil {
+		return []byte{}
+	}
+	return append([]byte(nil), unsafe.Slice(ptr, length)...)
+}
+
+// utf16PtrToString converts a null-terminated UTF-16 string into a Go
+// string.
+func utf16PtrToString(p *uint16) string {
+	if p == nil {
+		return ""
+	}
+	n := 0
+	for ptr := unsafe.Pointer(p); *(*uint16)(ptr) != 0; n++ {
+		ptr = unsafe.Add(ptr, 2)
+	}
+	return string(utf16.Decode(unsafe.Slice(p, n)))
+}
@@ -3,11 +3,15 @@ package api
 import "errors"
 
 const (
+	S_OK    = 0x00000000
+	S_FALSE = 0x00000001
+
 	E_INVALID_NAMESPACE = 0x8004100E
 	E_ACCESS_DENIED     = 0x80041003
 
 	// See https://msdn.microsoft.com/en-us/library/aa705940
 
+	S_ADS_NOMORE_ITEMS            = 0x00005004
 	S_ADS_ERRORSOCCURRED          = 0x00005011
 	S_ADS_NOMORE_ROWS             = 0x00005012
 	S_ADS_NOMORE_COLUMNS          = 0x00005013
@@ -29,6 +33,27 @@ const (
 	E_ADS_SCHEMA_VIOLATION        = 0x8000500F
 	E_ADS_COLUMN_NOT_SET          = 0x80005010
 	E_ADS_INVALID_FILTER          = 0x80005014
+
+	// Directory service errors returned by the LDAP provider. These are
+	// Win32 error codes wrapped as HRESULT values.
+
+	E_DS_NO_ATTRIBUTE_OR_VALUE  = 0x80072016
+	E_DS_ATT_VAL_ALREADY_EXISTS = 0x8007200D
+	E_DS_NO_SUCH_OBJECT         = 0x80072030
+	E_DS_OBJECT_ALREADY_EXISTS  = 0x80071392
+
+	// Name translation errors returned by IADsNameTranslate.
+
+	E_DS_NAME_ERROR_RESOLVING              = 0x80072115
+	E_DS_NAME_ERROR_NOT_FOUND              = 0x80072116
+	E_DS_NAME_ERROR_NOT_UNIQUE             = 0x80072117
+	E_DS_NAME_ERROR_NO_MAPPING             = 0x80072118
+	E_DS_NAME_ERROR_DOMAIN_ONLY            = 0x80072119
+	E_DS_NAME_ERROR_NO_SYNTACTICAL_MAPPING = 0x8007211A
+
+	// E_USER_EXISTS is returned when an account is created with a
+	// sAMAccountName that is already in use in the domain.
+	E_USER_EXISTS = 0x80070524
 )
 
 const (
@@ -49,6 +74,151 @@ const (
 	ADS_AUTH_RESERVED         = 0x80000000
 )
 
+// The ADS_PROPERTY_OPERATION_ENUM enumeration specifies how values are
+// applied to a property by IADs.PutEx.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_property_operation_enum
+const (
+	ADS_PROPERTY_CLEAR uint32 = iota + 1
+	ADS_PROPERTY_UPDATE
+	ADS_PROPERTY_APPEND
+	ADS_PROPERTY_DELETE
+)
+
+// The ADS_ATTR control codes specify how values are applied to an attribute
+// by IDirectoryObject.SetObjectAttributes.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ns-iads-ads_attr_info
+const (
+	ADS_ATTR_CLEAR uint32 = iota + 1
+	ADS_ATTR_UPDATE
+	ADS_ATTR_APPEND
+	ADS_ATTR_DELETE
+)
+
+// The ADS_USER_FLAG_ENUM enumeration defines the flags of the
+// userAccountControl attribute.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_user_flag_enum
+const (
+	ADS_UF_SCRIPT                                 = 0x1
+	ADS_UF_ACCOUNTDISABLE                         = 0x2
+	ADS_UF_HOMEDIR_REQUIRED                       = 0x8
+	ADS_UF_LOCKOUT                                = 0x10
+	ADS_UF_PASSWD_NOTREQD                         = 0x20
+	ADS_UF_PASSWD_CANT_CHANGE                     = 0x40
+	ADS_UF_ENCRYPTED_TEXT_PASSWORD_ALLOWED        = 0x80
+	ADS_UF_TEMP_DUPLICATE_ACCOUNT                 = 0x100
+	ADS_UF_NORMAL_ACCOUNT                         = 0x200
+	ADS_UF_INTERDOMAIN_TRUST_ACCOUNT              = 0x800
+	ADS_UF_WORKSTATION_TRUST_ACCOUNT              = 0x1000
+	ADS_UF_SERVER_TRUST_ACCOUNT                   = 0x2000
+	ADS_UF_DONT_EXPIRE_PASSWD                     = 0x10000
+	ADS_UF_MNS_LOGON_ACCOUNT                      = 0x20000
+	ADS_UF_SMARTCARD_REQUIRED                     = 0x40000
+	ADS_UF_TRUSTED_FOR_DELEGATION                 = 0x80000
+	ADS_UF_NOT_DELEGATED                          = 0x100000
+	ADS_UF_USE_DES_KEY_ONLY                       = 0x200000
+	ADS_UF_DONT_REQUIRE_PREAUTH                   = 0x400000
+	ADS_UF_PASSWORD_EXPIRED                       = 0x800000
+	ADS_UF_TRUSTED_TO_AUTHENTICATE_FOR_DELEGATION = 0x1000000
+)
+
+// The ADS_OPTION_ENUM enumeration identifies the options of an object that
+// are accessed through the IADsObjectOptions interface.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_option_enum
+const (
+	ADS_OPTION_SERVERNAME                = 0
+	ADS_OPTION_REFERRALS                 = 1
+	ADS_OPTION_PAGE_SIZE                 = 2
+	ADS_OPTION_SECURITY_MASK             = 3
+	ADS_OPTION_MUTUAL_AUTH_STATUS        = 4
+	ADS_OPTION_QUOTA                     = 5
+	ADS_OPTION_PASSWORD_PORTNUMBER       = 6
+	ADS_OPTION_PASSWORD_METHOD           = 7
+	ADS_OPTION_ACCUMULATIVE_MODIFICATION = 8
+	ADS_OPTION_SKIP_SID_LOOKUP           = 9
+)
+
+// The ADS_SECURITY_INFO_ENUM enumeration specifies the parts of a security
+// descriptor that are read or written.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_security_info_enum
+const (
+	ADS_SECURITY_INFO_OWNER = 0x1
+	ADS_SECURITY_INFO_GROUP = 0x2
+	ADS_SECURITY_INFO_DACL  = 0x4
+	ADS_SECURITY_INFO_SACL  = 0x8
+)
+
+// The ADS_RIGHTS_ENUM enumeration specifies the access rights of access
+// control entries on directory objects.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_rights_enum
+const (
+	ADS_RIGHT_DS_CREATE_CHILD   = 0x1
+	ADS_RIGHT_DS_DELETE_CHILD   = 0x2
+	ADS_RIGHT_ACTRL_DS_LIST     = 0x4
+	ADS_RIGHT_DS_SELF           = 0x8
+	ADS_RIGHT_DS_READ_PROP      = 0x10
+	ADS_RIGHT_DS_WRITE_PROP     = 0x20
+	ADS_RIGHT_DS_DELETE_TREE    = 0x40
+	ADS_RIGHT_DS_LIST_OBJECT    = 0x80
+	ADS_RIGHT_DS_CONTROL_ACCESS = 0x100
+	ADS_RIGHT_DELETE            = 0x10000
+	ADS_RIGHT_READ_CONTROL      = 0x20000
+	ADS_RIGHT_WRITE_DAC         = 0x40000
+	ADS_RIGHT_WRITE_OWNER       = 0x80000
+	ADS_RIGHT_GENERIC_ALL       = 0x10000000
+)
+
+// The ADS_SEARCHPREF_ENUM enumeration specifies the preferences of a search
+// made with the IDirectorySearch interface.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_searchpref_enum
+const (
+	ADS_SEARCHPREF_ASYNCHRONOUS uint32 = iota
+	ADS_SEARCHPREF_DEREF_ALIASES
+	ADS_SEARCHPREF_SIZE_LIMIT
+	ADS_SEARCHPREF_TIME_LIMIT
+	ADS_SEARCHPREF_ATTRIBTYPES_ONLY
+	ADS_SEARCHPREF_SEARCH_SCOPE
+	ADS_SEARCHPREF_TIMEOUT
+	ADS_SEARCHPREF_PAGESIZE
+	ADS_SEARCHPREF_PAGED_TIME_LIMIT
+	ADS_SEARCHPREF_CHASE_REFERRALS
+	ADS_SEARCHPREF_SORT_ON
+	ADS_SEARCHPREF_CACHE_RESULTS
+	ADS_SEARCHPREF_DIRSYNC
+	ADS_SEARCHPREF_TOMBSTONE
+	ADS_SEARCHPREF_VLV
+	ADS_SEARCHPREF_ATTRIBUTE_QUERY
+	ADS_SEARCHPREF_SECURITY_MASK
+	ADS_SEARCHPREF_DIRSYNC_FLAG
+	ADS_SEARCHPREF_EXTENDED_DN
+)
+
+// The ADS_SCOPEENUM enumeration specifies the scope of a search.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_scopeenum
+const (
+	ADS_SCOPE_BASE     = 0
+	ADS_SCOPE_ONELEVEL = 1
+	ADS_SCOPE_SUBTREE  = 2
+)
+
+// The ADS_CHASE_REFERRALS_ENUM enumeration specifies whether a search chases
+// referrals.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_chase_referrals_enum
+const (
+	ADS_CHASE_REFERRALS_NEVER       = 0x0
+	ADS_CHASE_REFERRALS_SUBORDINATE = 0x20
+	ADS_CHASE_REFERRALS_EXTERNAL    = 0x40
+	ADS_CHASE_REFERRALS_ALWAYS      = 0x60
+)
+
 // The ADS_NAME_INITTYPE_ENUM enumeration specifies the types of initialization to perform
 // on a NameTranslate object. It is used in the IADsNameTranslate interface.
 //
@@ -59,7 +229,7 @@ const (
 	ADS_NAME_INITTYPE_GC
 )
 
-//The ADS_NAME_TYPE_ENUM enumeration specifies the formats used for representing distinguished
+// The ADS_NAME_TYPE_ENUM enumeration specifies the formats used for representing distinguished
 // names. It is used by the IADsNameTranslate interface to convert the format of a distinguished name.
 //
 // See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-ads_name_type_enum
@@ -78,12 +248,49 @@ const (
 	ADS_NAME_TYPE_SID_OR_SID_HISTORY_NAME
 )
 
+// The ADSTYPEENUM enumeration identifies the data types used to interpret
+// ADSI property values.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ne-iads-adstypeenum
+const (
+	ADSTYPE_INVALID uint32 = iota
+	ADSTYPE_DN_STRING
+	ADSTYPE_CASE_EXACT_STRING
+	ADSTYPE_CASE_IGNORE_STRING
+	ADSTYPE_PRINTABLE_STRING
+	ADSTYPE_NUMERIC_STRING
+	ADSTYPE_BOOLEAN
+	ADSTYPE_INTEGER
+	ADSTYPE_OCTET_STRING
+	ADSTYPE_UTC_TIME
+	ADSTYPE_LARGE_INTEGER
+	ADSTYPE_PROV_SPECIFIC
+	ADSTYPE_OBJECT_CLASS
+	ADSTYPE_CASEIGNORE_LIST
+	ADSTYPE_OCTET_LIST
+	ADSTYPE_PATH
+	ADSTYPE_POSTALADDRESS
+	ADSTYPE_TIMESTAMP
+	ADSTYPE_BACKLINK
+	ADSTYPE_TYPEDNAME
+	ADSTYPE_HOLD
+	ADSTYPE_NETADDRESS
+	ADSTYPE_REPLICAPOINTER
+	ADSTYPE_FAXNUMBER
+	ADSTYPE_EMAIL
+	ADSTYPE_NT_SECURITY_DESCRIPTOR
+	ADSTYPE_UNKNOWN
+	ADSTYPE_DN_WITH_BINARY
+	ADSTYPE_DN_WITH_STRING
+)
+
 var (
 	ErrInvalidNamespace = errors.New("The provided name or namespace is invalid.")
 	ErrAccessDenied     = errors.New("Access denied.")
 
 	// See https://msdn.microsoft.com/en-us/library/aa705940
 
+	ErrNoMoreItems           = errors.New("The end of the enumeration has been reached.")
 	ErrQueryFailed           = errors.New("During a query, one or more errors occurred.")
 	ErrNoMoreRows            = errors.New("The search operation has reached the last row.")
 	ErrNoMoreColumns         = errors.New("The search operation has reached the last column for the current row.")
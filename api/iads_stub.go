@@ -54,12 +54,32 @@ func (v *IADs) GetEx(name string) (prop *ole.VARIANT, err error) {
 	return nil, ole.NewError(ole.E_NOTIMPL)
 }
 
+// GetInfo loads the values of all supported properties of the object into the
+// cache, discarding any values that have been cached but not yet committed.
+func (v *IADs) GetInfo() (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
 // GetInfoEx loads the given set of property names into the cache. The given
 // variant must be a safe array of null-terminated unicode strings.
 func (v *IADs) GetInfoEx(variant *ole.VARIANT) (err error) {
 	return ole.NewError(ole.E_NOTIMPL)
 }
 
+// Put sets the value of a property in the ADSI attribute cache. The value must
+// be commited with SetInfo to be made persistent.
+func (v *IADs) Put(name string, val *ole.VARIANT) error {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// PutEx modifies the values of a property in the ADSI attribute cache. The
+// control code is one of the ADS_PROPERTY values and determines how the
+// given values are applied. The values must be provided as a VARIANT array.
+// The modification must be commited with SetInfo to be made persistent.
+func (v *IADs) PutEx(controlCode uint32, name string, vals *ole.VARIANT) error {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
 // PutInt sets the values of an int attribute in the ADSI attribute
 // cache. The value must be commited with SetInfo to be made persistent.
 func (v *IADs) PutInt(name string, val int) error {
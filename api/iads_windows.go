@@ -180,6 +180,21 @@ func (v *IADs) GetEx(name string) (prop *ole.VARIANT, err error) {
 	return
 }
 
+// GetInfo loads the values of all supported properties of the object into the
+// cache, discarding any values that have been cached but not yet committed.
+func (v *IADs) GetInfo() (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().GetInfo),
+		1,
+		uintptr(unsafe.Pointer(v)),
+		0,
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
+
 // GetInfoEx loads the given set of property names into the cache. The given
 // variant must be a safe array of null-terminated unicode strings.
 func (v *IADs) GetInfoEx(variant *ole.VARIANT) (err error) {
@@ -195,6 +210,53 @@ func (v *IADs) GetInfoEx(variant *ole.VARIANT) (err error) {
 	return nil
 }
 
+// Put sets the value of a property in the ADSI attribute cache. The value must
+// be commited with SetInfo to be made persistent.
+func (v *IADs) Put(name string, val *ole.VARIANT) error {
+	bname := ole.SysAllocStringLen(name)
+	if bname == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bname)
+
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Put),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bname)),
+		uintptr(unsafe.Pointer(val)))
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
+
+// PutEx modifies the values of a property in the ADSI attribute cache. The
+// control code is one of the ADS_PROPERTY values and determines how the
+// given values are applied. The values must be provided as a VARIANT array.
+// The modification must be commited with SetInfo to be made persistent.
+func (v *IADs) PutEx(controlCode uint32, name string, vals *ole.VARIANT) error {
+	bname := ole.SysAllocStringLen(name)
+	if bname == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bname)
+
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().PutEx),
+		4,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(controlCode),
+		uintptr(unsafe.Pointer(bname)),
+		uintptr(unsafe.Pointer(vals)),
+		0,
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
+
 // PutInt sets the values of an int attribute in the ADSI attribute
 // cache. The value must be commited with SetInfo to be made persistent.
 func (v *IADs) PutInt(name string, val int) error {
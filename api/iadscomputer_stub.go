@@ -18,3 +18,148 @@ func (v *IADsComputer) Site() (site string, err error) {
 func (v *IADsComputer) OperatingSystem() (os string, err error) {
 	return "", ole.NewError(ole.E_NOTIMPL)
 }
+
+// Description retrieves the description of the computer.
+func (v *IADsComputer) Description() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// Location retrieves the physical location of the computer.
+func (v *IADsComputer) Location() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// PrimaryUser retrieves the name of the contact person for the computer.
+func (v *IADsComputer) PrimaryUser() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// Owner retrieves the name of the owner of the computer.
+func (v *IADsComputer) Owner() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// Division retrieves the division of the organization that owns the computer.
+func (v *IADsComputer) Division() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// Department retrieves the department of the organization that owns the computer.
+func (v *IADsComputer) Department() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// Role retrieves the role of the computer.
+func (v *IADsComputer) Role() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// OperatingSystemVersion retrieves the version of the operating system of the computer.
+func (v *IADsComputer) OperatingSystemVersion() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// Model retrieves the make and model of the computer.
+func (v *IADsComputer) Model() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// Processor retrieves the type of processor of the computer.
+func (v *IADsComputer) Processor() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// ProcessorCount retrieves the number of processors of the computer.
+func (v *IADsComputer) ProcessorCount() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// MemorySize retrieves the size of the random access memory of the computer in megabytes.
+func (v *IADsComputer) MemorySize() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// StorageCapacity retrieves the disk space of the computer in megabytes.
+func (v *IADsComputer) StorageCapacity() (value string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// NetAddresses retrieves the network addresses of the computer. The addresses
+// are returned as a VARIANT array of strings. It is the caller's
+// responsibility to clear the returned VARIANT.
+func (v *IADsComputer) NetAddresses() (addrs *ole.VARIANT, err error) {
+	return nil, ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetDescription sets the description of the computer.
+func (v *IADsComputer) SetDescription(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetLocation sets the physical location of the computer.
+func (v *IADsComputer) SetLocation(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetPrimaryUser sets the name of the contact person for the computer.
+func (v *IADsComputer) SetPrimaryUser(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetOwner sets the name of the owner of the computer.
+func (v *IADsComputer) SetOwner(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetDivision sets the division of the organization that owns the computer.
+func (v *IADsComputer) SetDivision(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetDepartment sets the department of the organization that owns the
+// computer.
+func (v *IADsComputer) SetDepartment(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetRole sets the role of the computer.
+func (v *IADsComputer) SetRole(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetOperatingSystem sets the operating system of the computer.
+func (v *IADsComputer) SetOperatingSystem(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetOperatingSystemVersion sets the version of the operating system of the
+// computer.
+func (v *IADsComputer) SetOperatingSystemVersion(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetModel sets the make and model of the computer.
+func (v *IADsComputer) SetModel(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetProcessor sets the type of processor of the computer.
+func (v *IADsComputer) SetProcessor(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetProcessorCount sets the number of processors of the computer.
+func (v *IADsComputer) SetProcessorCount(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetMemorySize sets the size of the random access memory of the computer
+// in megabytes.
+func (v *IADsComputer) SetMemorySize(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetStorageCapacity sets the disk space of the computer in megabytes.
+func (v *IADsComputer) SetStorageCapacity(value string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
@@ -69,3 +69,362 @@ func (v *IADsComputer) OperatingSystem() (os string, err error) {
 	}
 	return
 }
+
+// Description retrieves the description of the computer.
+func (v *IADsComputer) Description() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Description),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// Location retrieves the physical location of the computer.
+func (v *IADsComputer) Location() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Location),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// PrimaryUser retrieves the name of the contact person for the computer.
+func (v *IADsComputer) PrimaryUser() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().PrimaryUser),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// Owner retrieves the name of the owner of the computer.
+func (v *IADsComputer) Owner() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Owner),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// Division retrieves the division of the organization that owns the computer.
+func (v *IADsComputer) Division() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Division),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// Department retrieves the department of the organization that owns the computer.
+func (v *IADsComputer) Department() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Department),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// Role retrieves the role of the computer.
+func (v *IADsComputer) Role() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Role),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// OperatingSystemVersion retrieves the version of the operating system of the computer.
+func (v *IADsComputer) OperatingSystemVersion() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().OperatingSystemVersion),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// Model retrieves the make and model of the computer.
+func (v *IADsComputer) Model() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Model),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// Processor retrieves the type of processor of the computer.
+func (v *IADsComputer) Processor() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Processor),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// ProcessorCount retrieves the number of processors of the computer.
+func (v *IADsComputer) ProcessorCount() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().ProcessorCount),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// MemorySize retrieves the size of the random access memory of the computer in megabytes.
+func (v *IADsComputer) MemorySize() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().MemorySize),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// StorageCapacity retrieves the disk space of the computer in megabytes.
+func (v *IADsComputer) StorageCapacity() (value string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().StorageCapacity),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr != 0 {
+		return "", convertHresultToError(hr)
+	}
+	value = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	return
+}
+
+// NetAddresses retrieves the network addresses of the computer. The addresses
+// are returned as a VARIANT array of strings. It is the caller's
+// responsibility to clear the returned VARIANT.
+func (v *IADsComputer) NetAddresses() (addrs *ole.VARIANT, err error) {
+	addrs = new(ole.VARIANT)
+	ole.VariantInit(addrs)
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().NetAddresses),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(addrs)),
+		0)
+	if hr != 0 {
+		defer addrs.Clear()
+		return nil, convertHresultToError(hr)
+	}
+	return
+}
+
+// SetDescription sets the description of the computer.
+func (v *IADsComputer) SetDescription(value string) (err error) {
+	return v.setString(v.VTable().SetDescription, value)
+}
+
+// SetLocation sets the physical location of the computer.
+func (v *IADsComputer) SetLocation(value string) (err error) {
+	return v.setString(v.VTable().SetLocation, value)
+}
+
+// SetPrimaryUser sets the name of the contact person for the computer.
+func (v *IADsComputer) SetPrimaryUser(value string) (err error) {
+	return v.setString(v.VTable().SetPrimaryUser, value)
+}
+
+// SetOwner sets the name of the owner of the computer.
+func (v *IADsComputer) SetOwner(value string) (err error) {
+	return v.setString(v.VTable().SetOwner, value)
+}
+
+// SetDivision sets the division of the organization that owns the computer.
+func (v *IADsComputer) SetDivision(value string) (err error) {
+	return v.setString(v.VTable().SetDivision, value)
+}
+
+// SetDepartment sets the department of the organization that owns the
+// computer.
+func (v *IADsComputer) SetDepartment(value string) (err error) {
+	return v.setString(v.VTable().SetDepartment, value)
+}
+
+// SetRole sets the role of the computer.
+func (v *IADsComputer) SetRole(value string) (err error) {
+	return v.setString(v.VTable().SetRole, value)
+}
+
+// SetOperatingSystem sets the operating system of the computer.
+func (v *IADsComputer) SetOperatingSystem(value string) (err error) {
+	return v.setString(v.VTable().SetOperatingSystem, value)
+}
+
+// SetOperatingSystemVersion sets the version of the operating system of the
+// computer.
+func (v *IADsComputer) SetOperatingSystemVersion(value string) (err error) {
+	return v.setString(v.VTable().SetOperatingSystemVersion, value)
+}
+
+// SetModel sets the make and model of the computer.
+func (v *IADsComputer) SetModel(value string) (err error) {
+	return v.setString(v.VTable().SetModel, value)
+}
+
+// SetProcessor sets the type of processor of the computer.
+func (v *IADsComputer) SetProcessor(value string) (err error) {
+	return v.setString(v.VTable().SetProcessor, value)
+}
+
+// SetProcessorCount sets the number of processors of the computer.
+func (v *IADsComputer) SetProcessorCount(value string) (err error) {
+	return v.setString(v.VTable().SetProcessorCount, value)
+}
+
+// SetMemorySize sets the size of the random access memory of the computer
+// in megabytes.
+func (v *IADsComputer) SetMemorySize(value string) (err error) {
+	return v.setString(v.VTable().SetMemorySize, value)
+}
+
+// SetStorageCapacity sets the disk space of the computer in megabytes.
+func (v *IADsComputer) SetStorageCapacity(value string) (err error) {
+	return v.setString(v.VTable().SetStorageCapacity, value)
+}
+
+// setString calls a property setter of the interface with a string value.
+func (v *IADsComputer) setString(method uintptr, value string) (err error) {
+	bvalue := ole.SysAllocStringLen(value)
+	if bvalue == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bvalue)
+
+	hr, _, _ := syscall.Syscall(
+		method,
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bvalue)),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
@@ -1,3 +1,18 @@
+//go:build !windows
 // +build !windows
 
 package api
+
+import "github.com/go-ole/go-ole"
+
+// Status retrieves the status object of the computer. It is the caller's
+// responsibility to release the returned interface.
+func (v *IADsComputerOperations) Status() (status *ole.IDispatch, err error) {
+	return nil, ole.NewError(ole.E_NOTIMPL)
+}
+
+// Shutdown shuts down the computer. If reboot is true the computer is
+// restarted.
+func (v *IADsComputerOperations) Shutdown(reboot bool) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
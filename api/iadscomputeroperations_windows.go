@@ -1,3 +1,45 @@
+//go:build windows
 // +build windows
 
 package api
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+// Status retrieves the status object of the computer. It is the caller's
+// responsibility to release the returned interface.
+func (v *IADsComputerOperations) Status() (status *ole.IDispatch, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Status),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&status)),
+		0)
+	if hr != 0 {
+		return nil, convertHresultToError(hr)
+	}
+	return
+}
+
+// Shutdown shuts down the computer. If reboot is true the computer is
+// restarted.
+func (v *IADsComputerOperations) Shutdown(reboot bool) (err error) {
+	var b int16
+	if reboot {
+		b = -1
+	}
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Shutdown),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(b),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
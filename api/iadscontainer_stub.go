@@ -37,3 +37,26 @@ func (v *IADsContainer) GetObject(class, name string) (obj *ole.IDispatch, err e
 func (v *IADsContainer) SetFilter(variant *ole.VARIANT) (err error) {
 	return ole.NewError(ole.E_NOTIMPL)
 }
+
+// Create creates a new directory object of the given class with the given
+// relative name in the container. The object is not written to the
+// underlying directory store until SetInfo is called on it.
+func (v *IADsContainer) Create(class, name string) (obj *ole.IDispatch, err error) {
+	return nil, ole.NewError(ole.E_NOTIMPL)
+}
+
+// Delete deletes the directory object of the given class with the given
+// relative name from the container.
+//
+// If a class is not provided then the first item matching the relative name
+// will be deleted regardless of its class.
+func (v *IADsContainer) Delete(class, name string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// MoveHere moves the directory object with the given path into the
+// container. If a new relative name is provided the object is renamed as
+// well.
+func (v *IADsContainer) MoveHere(source, name string) (obj *ole.IDispatch, err error) {
+	return nil, ole.NewError(ole.E_NOTIMPL)
+}
@@ -101,3 +101,103 @@ func (v *IADsContainer) GetObject(class, name string) (obj *ole.IDispatch, err e
 	}
 	return
 }
+
+// Create creates a new directory object of the given class with the given
+// relative name in the container. The object is not written to the
+// underlying directory store until SetInfo is called on it.
+func (v *IADsContainer) Create(class, name string) (obj *ole.IDispatch, err error) {
+	bclass := ole.SysAllocStringLen(class)
+	if bclass == nil {
+		return nil, ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bclass)
+
+	bname := ole.SysAllocStringLen(name)
+	if bname == nil {
+		return nil, ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bname)
+
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().Create),
+		4,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bclass)),
+		uintptr(unsafe.Pointer(bname)),
+		uintptr(unsafe.Pointer(&obj)),
+		0,
+		0)
+	if hr != 0 {
+		return nil, convertHresultToError(hr)
+	}
+	return
+}
+
+// Delete deletes the directory object of the given class with the given
+// relative name from the container.
+//
+// If a class is not provided then the first item matching the relative name
+// will be deleted regardless of its class.
+func (v *IADsContainer) Delete(class, name string) (err error) {
+	var bclass *int16
+
+	if len(class) > 0 {
+		bclass = ole.SysAllocStringLen(class)
+		if bclass == nil {
+			return ole.NewError(ole.E_OUTOFMEMORY)
+		}
+		defer ole.SysFreeString(bclass)
+	}
+
+	bname := ole.SysAllocStringLen(name)
+	if bname == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bname)
+
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Delete),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bclass)),
+		uintptr(unsafe.Pointer(bname)))
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
+
+// MoveHere moves the directory object with the given path into the
+// container. If a new relative name is provided the object is renamed as
+// well.
+func (v *IADsContainer) MoveHere(source, name string) (obj *ole.IDispatch, err error) {
+	var bname *int16
+
+	bsource := ole.SysAllocStringLen(source)
+	if bsource == nil {
+		return nil, ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bsource)
+
+	if len(name) > 0 {
+		bname = ole.SysAllocStringLen(name)
+		if bname == nil {
+			return nil, ole.NewError(ole.E_OUTOFMEMORY)
+		}
+		defer ole.SysFreeString(bname)
+	}
+
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().MoveHere),
+		4,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bsource)),
+		uintptr(unsafe.Pointer(bname)),
+		uintptr(unsafe.Pointer(&obj)),
+		0,
+		0)
+	if hr != 0 {
+		return nil, convertHresultToError(hr)
+	}
+	return
+}
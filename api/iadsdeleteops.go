@@ -0,0 +1,26 @@
+package api
+
+import (
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+// IADsDeleteOpsVtbl represents the component object model virtual
+// function table for the IADsDeleteOps interface.
+type IADsDeleteOpsVtbl struct {
+	ole.IDispatchVtbl
+	DeleteObject uintptr
+}
+
+// IADsDeleteOps represents the component object model interface for
+// deleting directory objects.
+type IADsDeleteOps struct {
+	ole.IDispatch
+}
+
+// VTable returns the component object model virtual function table for the
+// delete operations.
+func (v *IADsDeleteOps) VTable() *IADsDeleteOpsVtbl {
+	return (*IADsDeleteOpsVtbl)(unsafe.Pointer(v.RawVTable))
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+import (
+	"github.com/go-ole/go-ole"
+)
+
+// DeleteObject deletes the object from the underlying directory store,
+// along with any objects that it contains.
+func (v *IADsDeleteOps) DeleteObject(flags int32) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
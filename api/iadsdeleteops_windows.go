@@ -0,0 +1,24 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// DeleteObject deletes the object from the underlying directory store,
+// along with any objects that it contains.
+func (v *IADsDeleteOps) DeleteObject(flags int32) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().DeleteObject),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(flags),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
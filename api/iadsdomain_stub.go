@@ -1,3 +1,92 @@
+//go:build !windows
 // +build !windows
 
 package api
+
+import "github.com/go-ole/go-ole"
+
+// IsWorkgroup reports whether the computer belongs to a workgroup rather than
+// to a domain.
+func (v *IADsDomain) IsWorkgroup() (workgroup bool, err error) {
+	return false, ole.NewError(ole.E_NOTIMPL)
+}
+
+// MinPasswordLength retrieves the minimum number of characters in a password.
+func (v *IADsDomain) MinPasswordLength() (value int32, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetMinPasswordLength sets the minimum number of characters in a password.
+func (v *IADsDomain) SetMinPasswordLength(value int32) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// MinPasswordAge retrieves the minimum number of seconds that must pass before a password can be changed.
+func (v *IADsDomain) MinPasswordAge() (value int32, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetMinPasswordAge sets the minimum number of seconds that must pass before a password can be changed.
+func (v *IADsDomain) SetMinPasswordAge(value int32) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// MaxPasswordAge retrieves the maximum number of seconds that a password may be used before it expires.
+func (v *IADsDomain) MaxPasswordAge() (value int32, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetMaxPasswordAge sets the maximum number of seconds that a password may be used before it expires.
+func (v *IADsDomain) SetMaxPasswordAge(value int32) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// MaxBadPasswordsAllowed retrieves the number of failed logon attempts after which an account is locked out.
+func (v *IADsDomain) MaxBadPasswordsAllowed() (value int32, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetMaxBadPasswordsAllowed sets the number of failed logon attempts after which an account is locked out.
+func (v *IADsDomain) SetMaxBadPasswordsAllowed(value int32) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// PasswordHistoryLength retrieves the number of previous passwords that cannot be reused.
+func (v *IADsDomain) PasswordHistoryLength() (value int32, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetPasswordHistoryLength sets the number of previous passwords that cannot be reused.
+func (v *IADsDomain) SetPasswordHistoryLength(value int32) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// PasswordAttributes retrieves the password restriction flags of the domain.
+func (v *IADsDomain) PasswordAttributes() (value int32, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetPasswordAttributes sets the password restriction flags of the domain.
+func (v *IADsDomain) SetPasswordAttributes(value int32) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// AutoUnlockInterval retrieves the number of seconds after which a locked out account is unlocked automatically.
+func (v *IADsDomain) AutoUnlockInterval() (value int32, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetAutoUnlockInterval sets the number of seconds after which a locked out account is unlocked automatically.
+func (v *IADsDomain) SetAutoUnlockInterval(value int32) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// LockoutObservationInterval retrieves the number of seconds after which the count of failed logon attempts is reset.
+func (v *IADsDomain) LockoutObservationInterval() (value int32, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetLockoutObservationInterval sets the number of seconds after which the count of failed logon attempts is reset.
+func (v *IADsDomain) SetLockoutObservationInterval(value int32) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
@@ -1,3 +1,249 @@
+//go:build windows
 // +build windows
 
 package api
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// IsWorkgroup reports whether the computer belongs to a workgroup rather than
+// to a domain.
+func (v *IADsDomain) IsWorkgroup() (workgroup bool, err error) {
+	var b int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().IsWorkgroup),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&b)),
+		0)
+	if hr != 0 {
+		return false, convertHresultToError(hr)
+	}
+	return b != 0, nil
+}
+
+// MinPasswordLength retrieves the minimum number of characters in a password.
+func (v *IADsDomain) MinPasswordLength() (value int32, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().MinPasswordLength),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&value)),
+		0)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
+
+// SetMinPasswordLength sets the minimum number of characters in a password.
+func (v *IADsDomain) SetMinPasswordLength(value int32) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetMinPasswordLength),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(value),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// MinPasswordAge retrieves the minimum number of seconds that must pass before a password can be changed.
+func (v *IADsDomain) MinPasswordAge() (value int32, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().MinPasswordAge),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&value)),
+		0)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
+
+// SetMinPasswordAge sets the minimum number of seconds that must pass before a password can be changed.
+func (v *IADsDomain) SetMinPasswordAge(value int32) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetMinPasswordAge),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(value),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// MaxPasswordAge retrieves the maximum number of seconds that a password may be used before it expires.
+func (v *IADsDomain) MaxPasswordAge() (value int32, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().MaxPasswordAge),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&value)),
+		0)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
+
+// SetMaxPasswordAge sets the maximum number of seconds that a password may be used before it expires.
+func (v *IADsDomain) SetMaxPasswordAge(value int32) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetMaxPasswordAge),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(value),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// MaxBadPasswordsAllowed retrieves the number of failed logon attempts after which an account is locked out.
+func (v *IADsDomain) MaxBadPasswordsAllowed() (value int32, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().MaxBadPasswordsAllowed),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&value)),
+		0)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
+
+// SetMaxBadPasswordsAllowed sets the number of failed logon attempts after which an account is locked out.
+func (v *IADsDomain) SetMaxBadPasswordsAllowed(value int32) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetMaxBadPasswordsAllowed),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(value),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// PasswordHistoryLength retrieves the number of previous passwords that cannot be reused.
+func (v *IADsDomain) PasswordHistoryLength() (value int32, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().PasswordHistoryLength),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&value)),
+		0)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
+
+// SetPasswordHistoryLength sets the number of previous passwords that cannot be reused.
+func (v *IADsDomain) SetPasswordHistoryLength(value int32) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetPasswordHistoryLength),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(value),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// PasswordAttributes retrieves the password restriction flags of the domain.
+func (v *IADsDomain) PasswordAttributes() (value int32, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().PasswordAttributes),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&value)),
+		0)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
+
+// SetPasswordAttributes sets the password restriction flags of the domain.
+func (v *IADsDomain) SetPasswordAttributes(value int32) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetPasswordAttributes),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(value),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// AutoUnlockInterval retrieves the number of seconds after which a locked out account is unlocked automatically.
+func (v *IADsDomain) AutoUnlockInterval() (value int32, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().AutoUnlockInterval),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&value)),
+		0)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
+
+// SetAutoUnlockInterval sets the number of seconds after which a locked out account is unlocked automatically.
+func (v *IADsDomain) SetAutoUnlockInterval(value int32) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetAutoUnlockInterval),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(value),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// LockoutObservationInterval retrieves the number of seconds after which the count of failed logon attempts is reset.
+func (v *IADsDomain) LockoutObservationInterval() (value int32, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().LockoutObservationInterval),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&value)),
+		0)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
+
+// SetLockoutObservationInterval sets the number of seconds after which the count of failed logon attempts is reset.
+func (v *IADsDomain) SetLockoutObservationInterval(value int32) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetLockoutObservationInterval),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(value),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
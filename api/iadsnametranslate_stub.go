@@ -28,3 +28,27 @@ func (v *IADsNameTranslate) Init(adsPath string, initType uint32) (err error) {
 func (v *IADsNameTranslate) Set(adsPath string, setType uint32) (err error) {
 	return ole.NewError(ole.E_NOTIMPL)
 }
+
+// ChaseReferral sets the referral chasing option of the translator, which is
+// one of the ADS_CHASE_REFERRALS_ENUM values.
+func (v *IADsNameTranslate) ChaseReferral(referral int32) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// InitEx initializes a name translate object by binding to a specified directory server, domain,
+// or global catalog, using the given credentials.
+func (v *IADsNameTranslate) InitEx(adsPath string, initType uint32, user, domain, password string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetEx directs the directory service to set up the specified objects for name translation.
+// The names are given as a VARIANT array of BSTR values in the format matching setType.
+func (v *IADsNameTranslate) SetEx(setType uint32, names *ole.VARIANT) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// GetEx retrieves the names of the objects set by SetEx in the specified format, as a VARIANT
+// array. It is the caller's responsibility to clear the returned variant.
+func (v *IADsNameTranslate) GetEx(formatType uint32) (names *ole.VARIANT, err error) {
+	return nil, ole.NewError(ole.E_NOTIMPL)
+}
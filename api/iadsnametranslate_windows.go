@@ -78,3 +78,89 @@ func (v *IADsNameTranslate) Set(adsPath string, setType uint32) (err error) {
 	}
 	return
 }
+
+// ChaseReferral sets the referral chasing option of the translator, which is
+// one of the ADS_CHASE_REFERRALS_ENUM values.
+func (v *IADsNameTranslate) ChaseReferral(referral int32) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().ChaseReferral),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(referral),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// InitEx initializes a name translate object by binding to a specified directory server, domain,
+// or global catalog, using the given credentials.
+func (v *IADsNameTranslate) InitEx(adsPath string, initType uint32, user, domain, password string) (err error) {
+	bname := ole.SysAllocStringLen(adsPath)
+	if bname == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bname)
+	buser := ole.SysAllocStringLen(user)
+	if buser == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(buser)
+	bdomain := ole.SysAllocStringLen(domain)
+	if bdomain == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bdomain)
+	bpassword := ole.SysAllocStringLen(password)
+	if bpassword == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bpassword)
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().InitEx),
+		6,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(initType),
+		uintptr(unsafe.Pointer(bname)),
+		uintptr(unsafe.Pointer(buser)),
+		uintptr(unsafe.Pointer(bdomain)),
+		uintptr(unsafe.Pointer(bpassword)))
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// SetEx directs the directory service to set up the specified objects for name translation.
+// The names are given as a VARIANT array of BSTR values in the format matching setType.
+func (v *IADsNameTranslate) SetEx(setType uint32, names *ole.VARIANT) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetEx),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(setType),
+		uintptr(unsafe.Pointer(names)))
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// GetEx retrieves the names of the objects set by SetEx in the specified format, as a VARIANT
+// array. It is the caller's responsibility to clear the returned variant.
+func (v *IADsNameTranslate) GetEx(formatType uint32) (names *ole.VARIANT, err error) {
+	names = new(ole.VARIANT)
+	ole.VariantInit(names)
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().GetEx),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(formatType),
+		uintptr(unsafe.Pointer(names)))
+	if hr != 0 {
+		defer names.Clear()
+		return nil, convertHresultToError(hr)
+	}
+	return
+}
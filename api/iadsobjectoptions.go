@@ -0,0 +1,27 @@
+package api
+
+import (
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+// IADsObjectOptionsVtbl represents the component object model virtual
+// function table for the IADsObjectOptions interface.
+type IADsObjectOptionsVtbl struct {
+	ole.IDispatchVtbl
+	GetOption uintptr
+	SetOption uintptr
+}
+
+// IADsObjectOptions represents the component object model interface for
+// the provider-specific options of a directory object.
+type IADsObjectOptions struct {
+	ole.IDispatch
+}
+
+// VTable returns the component object model virtual function table for the
+// object options.
+func (v *IADsObjectOptions) VTable() *IADsObjectOptionsVtbl {
+	return (*IADsObjectOptionsVtbl)(unsafe.Pointer(v.RawVTable))
+}
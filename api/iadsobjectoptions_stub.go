@@ -0,0 +1,17 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+import "github.com/go-ole/go-ole"
+
+// GetOption retrieves the value of the given ADS_OPTION of the object. It is
+// the caller's responsibility to clear the returned VARIANT.
+func (v *IADsObjectOptions) GetOption(option int32) (value *ole.VARIANT, err error) {
+	return nil, ole.NewError(ole.E_NOTIMPL)
+}
+
+// SetOption sets the value of the given ADS_OPTION of the object.
+func (v *IADsObjectOptions) SetOption(option int32, value *ole.VARIANT) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
@@ -0,0 +1,43 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+// GetOption retrieves the value of the given ADS_OPTION of the object. It is
+// the caller's responsibility to clear the returned VARIANT.
+func (v *IADsObjectOptions) GetOption(option int32) (value *ole.VARIANT, err error) {
+	value = new(ole.VARIANT)
+	ole.VariantInit(value)
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().GetOption),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(option),
+		uintptr(unsafe.Pointer(value)))
+	if hr != 0 {
+		defer value.Clear()
+		return nil, convertHresultToError(hr)
+	}
+	return
+}
+
+// SetOption sets the value of the given ADS_OPTION of the object.
+func (v *IADsObjectOptions) SetOption(option int32, value *ole.VARIANT) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetOption),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(option),
+		uintptr(unsafe.Pointer(value)))
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+// IADsPropertyEntryVtbl represents the component object model virtual
+// function table for the IADsPropertyEntry interface.
+type IADsPropertyEntryVtbl struct {
+	ole.IDispatchVtbl
+	Clear          uintptr
+	Name           uintptr
+	SetName        uintptr
+	ADsType        uintptr
+	SetADsType     uintptr
+	ControlCode    uintptr
+	SetControlCode uintptr
+	Values         uintptr
+	SetValues      uintptr
+}
+
+// IADsPropertyEntry represents the component object model interface for
+// an entry in a property list.
+type IADsPropertyEntry struct {
+	ole.IDispatch
+}
+
+// VTable returns the component object model virtual function table for the
+// property entry.
+func (v *IADsPropertyEntry) VTable() *IADsPropertyEntryVtbl {
+	return (*IADsPropertyEntryVtbl)(unsafe.Pointer(v.RawVTable))
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+import (
+	"github.com/go-ole/go-ole"
+)
+
+// Name retrieves the name of the property.
+func (v *IADsPropertyEntry) Name() (name string, err error) {
+	return "", ole.NewError(ole.E_NOTIMPL)
+}
+
+// ADsType retrieves the data type of the property. It is one of the
+// ADSTYPE_* values.
+func (v *IADsPropertyEntry) ADsType() (adsType uint32, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
@@ -0,0 +1,46 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+// Name retrieves the name of the property.
+func (v *IADsPropertyEntry) Name() (name string, err error) {
+	var bstr *int16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Name),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&bstr)),
+		0)
+	if bstr != nil {
+		defer ole.SysFreeString(bstr)
+	}
+	if hr == 0 {
+		name = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
+	} else {
+		return "", convertHresultToError(hr)
+	}
+	return
+}
+
+// ADsType retrieves the data type of the property. It is one of the
+// ADSTYPE_* values.
+func (v *IADsPropertyEntry) ADsType() (adsType uint32, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().ADsType),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&adsType)),
+		0)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
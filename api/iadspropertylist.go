@@ -0,0 +1,34 @@
+package api
+
+import (
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+// IADsPropertyListVtbl represents the component object model virtual
+// function table for the IADsPropertyList interface.
+type IADsPropertyListVtbl struct {
+	ole.IDispatchVtbl
+	PropertyCount     uintptr
+	Next              uintptr
+	Skip              uintptr
+	Reset             uintptr
+	Item              uintptr
+	GetPropertyItem   uintptr
+	PutPropertyItem   uintptr
+	ResetPropertyItem uintptr
+	PurgePropertyList uintptr
+}
+
+// IADsPropertyList represents the component object model interface for
+// the property cache of an active directory object.
+type IADsPropertyList struct {
+	ole.IDispatch
+}
+
+// VTable returns the component object model virtual function table for the
+// property list.
+func (v *IADsPropertyList) VTable() *IADsPropertyListVtbl {
+	return (*IADsPropertyListVtbl)(unsafe.Pointer(v.RawVTable))
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+import (
+	"github.com/go-ole/go-ole"
+)
+
+// PropertyCount retrieves the number of properties in the property cache.
+func (v *IADsPropertyList) PropertyCount() (count int32, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
+
+// Next retrieves the next entry in the property list. The entry is returned
+// as a VARIANT holding an IDispatch interface for an IADsPropertyEntry.
+//
+// When the end of the list has been reached ErrNoMoreItems is returned.
+func (v *IADsPropertyList) Next() (entry *ole.VARIANT, err error) {
+	return nil, ole.NewError(ole.E_NOTIMPL)
+}
+
+// Reset moves the cursor back to the start of the property list.
+func (v *IADsPropertyList) Reset() (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// ResetPropertyItem removes the property with the given name from the
+// property cache.
+func (v *IADsPropertyList) ResetPropertyItem(name string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
@@ -0,0 +1,84 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+// PropertyCount retrieves the number of properties in the property cache.
+func (v *IADsPropertyList) PropertyCount() (count int32, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().PropertyCount),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&count)),
+		0)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
+
+// Next retrieves the next entry in the property list. The entry is returned
+// as a VARIANT holding an IDispatch interface for an IADsPropertyEntry.
+//
+// When the end of the list has been reached ErrNoMoreItems is returned.
+func (v *IADsPropertyList) Next() (entry *ole.VARIANT, err error) {
+	entry = new(ole.VARIANT)
+	ole.VariantInit(entry)
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Next),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(entry)),
+		0)
+	switch hr {
+	case S_OK:
+		return entry, nil
+	case S_ADS_NOMORE_ITEMS:
+		return nil, ErrNoMoreItems
+	default:
+		entry.Clear()
+		return nil, convertHresultToError(hr)
+	}
+}
+
+// Reset moves the cursor back to the start of the property list.
+func (v *IADsPropertyList) Reset() (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().Reset),
+		1,
+		uintptr(unsafe.Pointer(v)),
+		0,
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
+
+// ResetPropertyItem removes the property with the given name from the
+// property cache.
+func (v *IADsPropertyList) ResetPropertyItem(name string) (err error) {
+	bname := ole.SysAllocStringLen(name)
+	if bname == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	item := ole.NewVariant(ole.VT_BSTR, int64(uintptr(unsafe.Pointer(bname))))
+	defer item.Clear()
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().ResetPropertyItem),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&item)),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return nil
+}
@@ -19,3 +19,15 @@ func (v *IADsUser) SetAccountDisabled(disabled bool) (err error) {
 func (v *IADsUser) FullName() (name string, err error) {
 	return "", ole.NewError(ole.E_NOTIMPL)
 }
+
+// SetPassword sets the password of the user. The password is written to the
+// underlying directory store immediately.
+func (v *IADsUser) SetPassword(password string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// ChangePassword changes the password of the user from old to new. The
+// password is written to the underlying directory store immediately.
+func (v *IADsUser) ChangePassword(old, new string) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
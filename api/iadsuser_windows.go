@@ -56,3 +56,51 @@ func (v *IADsUser) FullName() (name string, err error) {
 	name = ole.BstrToString((*uint16)(unsafe.Pointer(bstr)))
 	return
 }
+
+// SetPassword sets the password of the user. The password is written to the
+// underlying directory store immediately.
+func (v *IADsUser) SetPassword(password string) (err error) {
+	bpassword := ole.SysAllocStringLen(password)
+	if bpassword == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bpassword)
+
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetPassword),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bpassword)),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// ChangePassword changes the password of the user from old to new. The
+// password is written to the underlying directory store immediately.
+func (v *IADsUser) ChangePassword(old, new string) (err error) {
+	bold := ole.SysAllocStringLen(old)
+	if bold == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bold)
+
+	bnew := ole.SysAllocStringLen(new)
+	if bnew == nil {
+		return ole.NewError(ole.E_OUTOFMEMORY)
+	}
+	defer ole.SysFreeString(bnew)
+
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().ChangePassword),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(bold)),
+		uintptr(unsafe.Pointer(bnew)))
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
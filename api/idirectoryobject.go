@@ -0,0 +1,30 @@
+package api
+
+import (
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+// IDirectoryObjectVtbl represents the component object model virtual
+// function table for the IDirectoryObject interface.
+type IDirectoryObjectVtbl struct {
+	ole.IUnknownVtbl
+	GetObjectInformation uintptr
+	GetObjectAttributes  uintptr
+	SetObjectAttributes  uintptr
+	CreateDSObject       uintptr
+	DeleteDSObject       uintptr
+}
+
+// IDirectoryObject represents the component object model interface for
+// direct access to directory service objects.
+type IDirectoryObject struct {
+	ole.IUnknown
+}
+
+// VTable returns the component object model virtual function table for the
+// directory object.
+func (v *IDirectoryObject) VTable() *IDirectoryObjectVtbl {
+	return (*IDirectoryObjectVtbl)(unsafe.Pointer(v.RawVTable))
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+import (
+	"github.com/go-ole/go-ole"
+)
+
+// SetObjectAttributes applies the given modifications to the object directly
+// on the server. All of the modifications are sent in a single request, so
+// either all of them are applied or none of them are.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectoryobject-setobjectattributes
+func (v *IDirectoryObject) SetObjectAttributes(mods []AttrModification) (modified uint32, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
+
+// GetObjectAttributes retrieves the values of the given attributes of the
+// object directly from the server. If no names are given, every attribute of
+// the object is retrieved. Attributes that are not populated are omitted
+// from the result.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectoryobject-getobjectattributes
+func (v *IDirectoryObject) GetObjectAttributes(names []string) (attrs []AttrValues, err error) {
+	return nil, ole.NewError(ole.E_NOTIMPL)
+}
@@ -0,0 +1,87 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// SetObjectAttributes applies the given modifications to the object directly
+// on the server. All of the modifications are sent in a single request, so
+// either all of them are applied or none of them are.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectoryobject-setobjectattributes
+func (v *IDirectoryObject) SetObjectAttributes(mods []AttrModification) (modified uint32, err error) {
+	if len(mods) == 0 {
+		return 0, nil
+	}
+	list, err := newADSAttrInfoList(mods)
+	if err != nil {
+		return 0, err
+	}
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().SetObjectAttributes),
+		4,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&list.infos[0])),
+		uintptr(len(list.infos)),
+		uintptr(unsafe.Pointer(&modified)),
+		0,
+		0)
+	runtime.KeepAlive(list)
+	if hr != 0 {
+		return modified, convertHresultToError(hr)
+	}
+	return
+}
+
+// GetObjectAttributes retrieves the values of the given attributes of the
+// object directly from the server. If no names are given, every attribute of
+// the object is retrieved. Attributes that are not populated are omitted
+// from the result.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectoryobject-getobjectattributes
+func (v *IDirectoryObject) GetObjectAttributes(names []string) (attrs []AttrValues, err error) {
+	var (
+		list  adsAttrInfoList
+		ptrs  []*uint16
+		count = ^uint32(0)
+	)
+	if len(names) > 0 {
+		ptrs = make([]*uint16, len(names))
+		for i, name := range names {
+			ptrs[i] = list.utf16(name)
+		}
+		count = uint32(len(names))
+	}
+	var namesPtr uintptr
+	if len(ptrs) > 0 {
+		namesPtr = uintptr(unsafe.Pointer(&ptrs[0]))
+	}
+
+	var (
+		infos *ADSAttrInfo
+		n     uint32
+	)
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().GetObjectAttributes),
+		5,
+		uintptr(unsafe.Pointer(v)),
+		namesPtr,
+		uintptr(count),
+		uintptr(unsafe.Pointer(&infos)),
+		uintptr(unsafe.Pointer(&n)),
+		0)
+	runtime.KeepAlive(ptrs)
+	runtime.KeepAlive(&list)
+	if infos != nil {
+		defer freeADsMem(unsafe.Pointer(infos))
+	}
+	if hr != 0 {
+		return nil, convertHresultToError(hr)
+	}
+	return decodeAttrInfos(infos, n), nil
+}
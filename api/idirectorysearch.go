@@ -9,7 +9,7 @@ import (
 // IDirectorySearchVtbl represents the component object model virtual
 // function table for the IDirectorySearch interface.
 type IDirectorySearchVtbl struct {
-	ole.IDispatchVtbl
+	ole.IUnknownVtbl
 	SetSearchPreferences uintptr
 	ExecuteSearch        uintptr
 	AbandonSearch        uintptr
@@ -25,7 +25,7 @@ type IDirectorySearchVtbl struct {
 // IDirectorySearch represents the component object model interface for
 // conducting directory searches.
 type IDirectorySearch struct {
-	ole.IDispatch
+	ole.IUnknown
 }
 
 // VTable returns the component object model virtual function table for the
@@ -33,3 +33,41 @@ type IDirectorySearch struct {
 func (v *IDirectorySearch) VTable() *IDirectorySearchVtbl {
 	return (*IDirectorySearchVtbl)(unsafe.Pointer(v.RawVTable))
 }
+
+// SearchHandle identifies a search that was started by
+// IDirectorySearch.ExecuteSearch. It must be closed with
+// IDirectorySearch.CloseSearchHandle.
+type SearchHandle uintptr
+
+// SearchPreference is a preference of a search that is set by
+// IDirectorySearch.SetSearchPreferences.
+type SearchPreference struct {
+	// Option is one of the ADS_SEARCHPREF values.
+	Option uint32
+
+	// Value is the value of the preference. It must be an int, int32,
+	// uint32 or bool.
+	Value interface{}
+}
+
+// adsSearchPrefInfo represents the ADS_SEARCHPREF_INFO structure.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ns-iads-ads_searchpref_info
+type adsSearchPrefInfo struct {
+	Option uint32
+	_      uint32
+	Value  ADSValue
+	Status uint32
+	_      uint32
+}
+
+// adsSearchColumn represents the ADS_SEARCH_COLUMN structure.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/ns-iads-ads_search_column
+type adsSearchColumn struct {
+	Name      *uint16
+	Type      uint32
+	Values    *ADSValue
+	NumValues uint32
+	_         uintptr
+}
@@ -1,3 +1,61 @@
+//go:build !windows
 // +build !windows
 
 package api
+
+import (
+	"github.com/go-ole/go-ole"
+)
+
+// SetSearchPreferences sets the preferences of subsequent searches.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-setsearchpreferences
+func (v *IDirectorySearch) SetSearchPreferences(prefs []SearchPreference) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// ExecuteSearch starts a search with the given LDAP filter that returns the
+// given attributes. If no attributes are given, every attribute is returned.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-executesearch
+func (v *IDirectorySearch) ExecuteSearch(filter string, attrs []string) (handle SearchHandle, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
+
+// AbandonSearch abandons a search that is in progress.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-abandonsearch
+func (v *IDirectorySearch) AbandonSearch(handle SearchHandle) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
+
+// GetNextRow moves to the next row of the search results. It returns false
+// once there are no more rows.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-getnextrow
+func (v *IDirectorySearch) GetNextRow(handle SearchHandle) (ok bool, err error) {
+	return false, ole.NewError(ole.E_NOTIMPL)
+}
+
+// GetNextColumnName returns the name of the next column of the current row.
+// It returns false once there are no more columns.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-getnextcolumnname
+func (v *IDirectorySearch) GetNextColumnName(handle SearchHandle) (name string, ok bool, err error) {
+	return "", false, ole.NewError(ole.E_NOTIMPL)
+}
+
+// GetColumn returns the values of the named column of the current row.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-getcolumn
+func (v *IDirectorySearch) GetColumn(handle SearchHandle, name string) (attr AttrValues, err error) {
+	return attr, ole.NewError(ole.E_NOTIMPL)
+}
+
+// CloseSearchHandle closes the search and releases the resources that it
+// consumes.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-closesearchhandle
+func (v *IDirectorySearch) CloseSearchHandle(handle SearchHandle) (err error) {
+	return ole.NewError(ole.E_NOTIMPL)
+}
@@ -1,3 +1,198 @@
+//go:build windows
 // +build windows
 
 package api
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// SetSearchPreferences sets the preferences of subsequent searches.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-setsearchpreferences
+func (v *IDirectorySearch) SetSearchPreferences(prefs []SearchPreference) (err error) {
+	if len(prefs) == 0 {
+		return nil
+	}
+	var list adsAttrInfoList
+	infos := make([]adsSearchPrefInfo, len(prefs))
+	for i, pref := range prefs {
+		infos[i].Option = pref.Option
+		if err := list.set(&infos[i].Value, pref.Value); err != nil {
+			return fmt.Errorf("search preference %d: %v", pref.Option, err)
+		}
+	}
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().SetSearchPreferences),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&infos[0])),
+		uintptr(len(infos)))
+	runtime.KeepAlive(&list)
+	if hr == S_ADS_ERRORSOCCURRED {
+		for _, info := range infos {
+			if info.Status != 0 {
+				return fmt.Errorf("search preference %d was not accepted", info.Option)
+			}
+		}
+	}
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// ExecuteSearch starts a search with the given LDAP filter that returns the
+// given attributes. If no attributes are given, every attribute is returned.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-executesearch
+func (v *IDirectorySearch) ExecuteSearch(filter string, attrs []string) (handle SearchHandle, err error) {
+	var (
+		list  adsAttrInfoList
+		ptrs  []*uint16
+		count = ^uint32(0)
+	)
+	if len(attrs) > 0 {
+		ptrs = make([]*uint16, len(attrs))
+		for i, attr := range attrs {
+			ptrs[i] = list.utf16(attr)
+		}
+		count = uint32(len(attrs))
+	}
+	var attrsPtr uintptr
+	if len(ptrs) > 0 {
+		attrsPtr = uintptr(unsafe.Pointer(&ptrs[0]))
+	}
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().ExecuteSearch),
+		5,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(list.utf16(filter))),
+		attrsPtr,
+		uintptr(count),
+		uintptr(unsafe.Pointer(&handle)),
+		0)
+	runtime.KeepAlive(ptrs)
+	runtime.KeepAlive(&list)
+	if hr != 0 {
+		return 0, convertHresultToError(hr)
+	}
+	return
+}
+
+// AbandonSearch abandons a search that is in progress.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-abandonsearch
+func (v *IDirectorySearch) AbandonSearch(handle SearchHandle) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().AbandonSearch),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(handle),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
+
+// GetNextRow moves to the next row of the search results. It returns false
+// once there are no more rows.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-getnextrow
+func (v *IDirectorySearch) GetNextRow(handle SearchHandle) (ok bool, err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().GetNextRow),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(handle),
+		0)
+	switch hr {
+	case S_OK:
+		return true, nil
+	case S_ADS_NOMORE_ROWS:
+		return false, nil
+	default:
+		return false, convertHresultToError(hr)
+	}
+}
+
+// GetNextColumnName returns the name of the next column of the current row.
+// It returns false once there are no more columns.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-getnextcolumnname
+func (v *IDirectorySearch) GetNextColumnName(handle SearchHandle) (name string, ok bool, err error) {
+	var p *uint16
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().GetNextColumnName),
+		3,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(handle),
+		uintptr(unsafe.Pointer(&p)))
+	if p != nil {
+		defer freeADsMem(unsafe.Pointer(p))
+	}
+	switch hr {
+	case S_OK:
+		return utf16PtrToString(p), true, nil
+	case S_ADS_NOMORE_COLUMNS:
+		return "", false, nil
+	default:
+		return "", false, convertHresultToError(hr)
+	}
+}
+
+// GetColumn returns the values of the named column of the current row.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-getcolumn
+func (v *IDirectorySearch) GetColumn(handle SearchHandle, name string) (attr AttrValues, err error) {
+	var (
+		list   adsAttrInfoList
+		column adsSearchColumn
+	)
+	hr, _, _ := syscall.Syscall6(
+		uintptr(v.VTable().GetColumn),
+		4,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(handle),
+		uintptr(unsafe.Pointer(list.utf16(name))),
+		uintptr(unsafe.Pointer(&column)),
+		0,
+		0)
+	runtime.KeepAlive(&list)
+	if hr != 0 {
+		return attr, convertHresultToError(hr)
+	}
+	attr = AttrValues{
+		Name:   utf16PtrToString(column.Name),
+		Type:   column.Type,
+		Values: decodeADSValues(column.Values, column.NumValues),
+	}
+	syscall.Syscall(
+		uintptr(v.VTable().FreeColumn),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(unsafe.Pointer(&column)),
+		0)
+	return
+}
+
+// CloseSearchHandle closes the search and releases the resources that it
+// consumes.
+//
+// See https://docs.microsoft.com/en-us/windows/win32/api/iads/nf-iads-idirectorysearch-closesearchhandle
+func (v *IDirectorySearch) CloseSearchHandle(handle SearchHandle) (err error) {
+	hr, _, _ := syscall.Syscall(
+		uintptr(v.VTable().CloseSearchHandle),
+		2,
+		uintptr(unsafe.Pointer(v)),
+		uintptr(handle),
+		0)
+	if hr != 0 {
+		return convertHresultToError(hr)
+	}
+	return
+}
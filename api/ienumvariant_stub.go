@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package api
+
+import (
+	"github.com/go-ole/go-ole"
+)
+
+// EnumVariantNext retrieves up to len(variants) items from the given
+// enumerator and stores them in variants. It returns the number of items
+// retrieved, which is less than len(variants) when the end of the
+// enumeration has been reached.
+//
+// It is the caller's responsibility to clear each of the retrieved variants.
+//
+// See https://msdn.microsoft.com/library/ms695273
+func EnumVariantNext(enum *ole.IEnumVARIANT, variants []ole.VARIANT) (n int, err error) {
+	return 0, ole.NewError(ole.E_NOTIMPL)
+}
@@ -0,0 +1,39 @@
+//go:build windows
+// +build windows
+
+package api
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+)
+
+// EnumVariantNext retrieves up to len(variants) items from the given
+// enumerator and stores them in variants. It returns the number of items
+// retrieved, which is less than len(variants) when the end of the
+// enumeration has been reached.
+//
+// It is the caller's responsibility to clear each of the retrieved variants.
+//
+// See https://msdn.microsoft.com/library/ms695273
+func EnumVariantNext(enum *ole.IEnumVARIANT, variants []ole.VARIANT) (n int, err error) {
+	if len(variants) == 0 {
+		return 0, nil
+	}
+	var fetched uint32
+	hr, _, _ := syscall.Syscall6(
+		enum.VTable().Next,
+		4,
+		uintptr(unsafe.Pointer(enum)),
+		uintptr(len(variants)),
+		uintptr(unsafe.Pointer(&variants[0])),
+		uintptr(unsafe.Pointer(&fetched)),
+		0,
+		0)
+	if hr != S_OK && hr != S_FALSE {
+		return 0, convertHresultToError(hr)
+	}
+	return int(fetched), nil
+}
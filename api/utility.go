@@ -11,55 +11,82 @@ func convertHresultToError(hr uintptr) (err error) {
 	if hr != 0 {
 		err = ole.NewError(hr)
 		if strings.Contains(err.Error(), "FormatMessage failed") {
-			switch hr {
-			case E_INVALID_NAMESPACE:
-				err = ErrInvalidNamespace
-			case E_ACCESS_DENIED:
-				err = ErrAccessDenied
-			case S_ADS_ERRORSOCCURRED:
-				err = ErrQueryFailed
-			case S_ADS_NOMORE_ROWS:
-				err = ErrNoMoreRows
-			case S_ADS_NOMORE_COLUMNS:
-				err = ErrNoMoreColumns
-			case E_ADS_BAD_PATHNAME:
-				err = ErrBadPathname
-			case E_ADS_INVALID_DOMAIN_OBJECT:
-				err = ErrInvalidDomainObject
-			case E_ADS_INVALID_USER_OBJECT:
-				err = ErrInvalidUserObject
-			case E_ADS_INVALID_COMPUTER_OBJECT:
-				err = ErrInvalidComputerObject
-			case E_ADS_UNKNOWN_OBJECT:
-				err = ErrUnknownObject
-			case E_ADS_PROPERTY_NOT_SET:
-				err = ErrPropertyNotSet
-			case E_ADS_PROPERTY_NOT_SUPPORTED:
-				err = ErrPropertyNotSupported
-			case E_ADS_PROPERTY_INVALID:
-				err = ErrPropertyInvalid
-			case E_ADS_BAD_PARAMETER:
-				err = ErrBadParameter
-			case E_ADS_OBJECT_UNBOUND:
-				err = ErrObjectUnbound
-			case E_ADS_PROPERTY_NOT_MODIFIED:
-				err = ErrPropertyNotModified
-			case E_ADS_PROPERTY_MODIFIED:
-				err = ErrPropertyModified
-			case E_ADS_CANT_CONVERT_DATATYPE:
-				err = ErrCantConvertDatatype
-			case E_ADS_PROPERTY_NOT_FOUND:
-				err = ErrPropertyNotFound
-			case E_ADS_OBJECT_EXISTS:
-				err = ErrObjectExists
-			case E_ADS_SCHEMA_VIOLATION:
-				err = ErrSchemaViolation
-			case E_ADS_COLUMN_NOT_SET:
-				err = ErrColumnNotSet
-			case E_ADS_INVALID_FILTER:
-				err = ErrInvalidFilter
+			if mapped := hresultError(hr); mapped != nil {
+				err = mapped
 			}
 		}
 	}
 	return
 }
+
+// hresultError returns the error value of this package that corresponds to
+// the given HRESULT, or nil if there isn't one.
+func hresultError(hr uintptr) error {
+	switch hr {
+	case E_INVALID_NAMESPACE:
+		return ErrInvalidNamespace
+	case E_ACCESS_DENIED:
+		return ErrAccessDenied
+	case S_ADS_NOMORE_ITEMS:
+		return ErrNoMoreItems
+	case S_ADS_ERRORSOCCURRED:
+		return ErrQueryFailed
+	case S_ADS_NOMORE_ROWS:
+		return ErrNoMoreRows
+	case S_ADS_NOMORE_COLUMNS:
+		return ErrNoMoreColumns
+	case E_ADS_BAD_PATHNAME:
+		return ErrBadPathname
+	case E_ADS_INVALID_DOMAIN_OBJECT:
+		return ErrInvalidDomainObject
+	case E_ADS_INVALID_USER_OBJECT:
+		return ErrInvalidUserObject
+	case E_ADS_INVALID_COMPUTER_OBJECT:
+		return ErrInvalidComputerObject
+	case E_ADS_UNKNOWN_OBJECT:
+		return ErrUnknownObject
+	case E_ADS_PROPERTY_NOT_SET:
+		return ErrPropertyNotSet
+	case E_ADS_PROPERTY_NOT_SUPPORTED:
+		return ErrPropertyNotSupported
+	case E_ADS_PROPERTY_INVALID:
+		return ErrPropertyInvalid
+	case E_ADS_BAD_PARAMETER:
+		return ErrBadParameter
+	case E_ADS_OBJECT_UNBOUND:
+		return ErrObjectUnbound
+	case E_ADS_PROPERTY_NOT_MODIFIED:
+		return ErrPropertyNotModified
+	case E_ADS_PROPERTY_MODIFIED:
+		return ErrPropertyModified
+	case E_ADS_CANT_CONVERT_DATATYPE:
+		return ErrCantConvertDatatype
+	case E_ADS_PROPERTY_NOT_FOUND:
+		return ErrPropertyNotFound
+	case E_ADS_OBJECT_EXISTS:
+		return ErrObjectExists
+	case E_ADS_SCHEMA_VIOLATION:
+		return ErrSchemaViolation
+	case E_ADS_COLUMN_NOT_SET:
+		return ErrColumnNotSet
+	case E_ADS_INVALID_FILTER:
+		return ErrInvalidFilter
+	}
+	return nil
+}
+
+// IsHresult reports whether err was returned by a call that failed with the
+// given HRESULT. It recognizes both raw COM errors and the error values of
+// this package that they may have been converted to.
+func IsHresult(err error, hr uintptr) bool {
+	if err == nil {
+		return false
+	}
+	if oleErr, ok := err.(*ole.OleError); ok {
+		return oleErr.Code() == hr
+	}
+	if mapped := hresultError(hr); mapped != nil {
+		return err == mapped
+	}
+	return false
+}
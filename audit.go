@@ -0,0 +1,364 @@
+package adsi
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WriteOp identifies a kind of directory write.
+type WriteOp string
+
+// Directory write operations that are reported to a WriteInterceptor.
+const (
+	WriteSetInfo        WriteOp = "setinfo"
+	WriteCompareAndSwap WriteOp = "compareandswap"
+	WriteCreate         WriteOp = "create"
+	WriteDelete         WriteOp = "delete"
+	WriteMove           WriteOp = "move"
+	WriteAddMember      WriteOp = "addmember"
+	WriteRemoveMember   WriteOp = "removemember"
+	WriteSetPassword    WriteOp = "setpassword"
+	WriteChangePassword WriteOp = "changepassword"
+	WriteSetSecurity    WriteOp = "setsecurity"
+)
+
+// Redacted replaces the values of secret attributes in the changes reported
+// to a WriteInterceptor.
+const Redacted = "[redacted]"
+
+// WriteEvent describes a directory write made through a Client. The same
+// event is reported before and after the write is made.
+type WriteEvent struct {
+	// ID identifies the write. The events reported before and after a write
+	// share the same ID.
+	ID uint64
+
+	// Time is the time at which the event was reported.
+	Time time.Time
+
+	// Op is the kind of write.
+	Op WriteOp
+
+	// Path is the ADsPath of the object that is written.
+	Path string
+
+	// Class is the schema class of the object that is written.
+	Class string
+
+	// Target is the ADsPath of the destination container for WriteMove and of
+	// the member for WriteAddMember and WriteRemoveMember.
+	Target string
+
+	// Name is the new relative name of the object for WriteMove, if it is
+	// renamed.
+	Name string
+
+	// Changes holds the attribute modifications that are written. The values
+	// of secret attributes, such as unicodePwd, are replaced with Redacted.
+	// Passwords set by password operations are never included.
+	Changes []Change
+
+	// Identity identifies the caller on whose behalf the write is made. It is
+	// the user name of the credentials that the object was opened with, or
+	// the identity of the client if the object was opened with the security
+	// context of the process.
+	Identity string
+
+	// Done is false for the event reported before the write and true for the
+	// event reported after it.
+	Done bool
+
+	// Err is the result of the write. It is always nil before the write.
+	Err error
+}
+
+// WriteInterceptor observes the directory writes made through a Client.
+//
+// BeforeWrite is called before each write. If it returns an error, the write
+// is not made and the error is returned to the caller; AfterWrite is not
+// called in that case. Otherwise AfterWrite is called once the write has been
+// made, with the result of the write.
+//
+// Interceptors may be called concurrently from multiple goroutines.
+type WriteInterceptor interface {
+	BeforeWrite(e WriteEvent) error
+	AfterWrite(e WriteEvent)
+}
+
+// secretAttrs holds the lower case names of attributes whose values are
+// redacted in write events.
+var secretAttrs = map[string]bool{
+	"dbcspwd":                      true,
+	"lmpwdhistory":                 true,
+	"ms-mcs-admpwd":                true,
+	"msds-managedpassword":         true,
+	"mslaps-encrypteddsrmpassword": true,
+	"mslaps-encryptedpassword":     true,
+	"mslaps-password":              true,
+	"ntpwdhistory":                 true,
+	"supplementalcredentials":      true,
+	"unicodepwd":                   true,
+	"userpassword":                 true,
+}
+
+// redactChanges returns a copy of the given changes in which the values of
+// secret attributes are replaced with Redacted.
+func redactChanges(changes []Change) []Change {
+	if len(changes) == 0 {
+		return nil
+	}
+	result := make([]Change, len(changes))
+	for i, c := range changes {
+		result[i] = Change{Name: c.Name, Old: redactValues(c.Name, c.Old), New: redactValues(c.Name, c.New)}
+	}
+	return result
+}
+
+func redactValues(name string, values []interface{}) []interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	if !secretAttrs[strings.ToLower(name)] {
+		return append([]interface{}(nil), values...)
+	}
+	redacted := make([]interface{}, len(values))
+	for i := range redacted {
+		redacted[i] = Redacted
+	}
+	return redacted
+}
+
+// writeID is the ID of the most recent write event.
+var writeID atomic.Uint64
+
+var (
+	processIdentityOnce sync.Once
+	processIdentity     string
+)
+
+// WriteInterceptor returns the write interceptor of the client, or nil if
+// none has been set.
+func (c *Client) WriteInterceptor() WriteInterceptor {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return c.interceptor
+}
+
+// SetWriteInterceptor sets the interceptor that is called before and after
+// every directory write made through objects opened by the client. A nil
+// interceptor removes it.
+func (c *Client) SetWriteInterceptor(i WriteInterceptor) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.interceptor = i
+}
+
+// Identity returns the identity that is reported in write events for objects
+// opened with the security context of the process.
+func (c *Client) Identity() string {
+	c.m.RLock()
+	identity := c.identity
+	c.m.RUnlock()
+	if identity != "" {
+		return identity
+	}
+	processIdentityOnce.Do(func() {
+		if u, err := user.Current(); err == nil {
+			processIdentity = u.Username
+		}
+	})
+	return processIdentity
+}
+
+// SetIdentity sets the identity that is reported in write events for objects
+// opened with the security context of the process, such as the end user on
+// whose behalf a service is acting. By default the user name of the process
+// is reported.
+func (c *Client) SetIdentity(identity string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.identity = identity
+}
+
+// writeEvent returns a write event for the given operation on the object.
+// The caller must hold a lock on the object.
+func (o *object) writeEvent(op WriteOp) (e WriteEvent, err error) {
+	e.Op = op
+	if e.Path, err = o.iface.AdsPath(); err != nil {
+		return
+	}
+	e.Class, err = o.iface.Class()
+	return
+}
+
+// intercept makes a write by calling fn if it is permitted by the write
+// policy of the client, reporting it to the write interceptor of the client
+// before and after. The user is the user name of the credentials that the
+// written object was opened with. It may be called on a nil client.
+func (c *Client) intercept(e WriteEvent, user string, fn func() error) error {
+	if c == nil {
+		return fn()
+	}
+	c.m.RLock()
+	policy := c.policy
+	c.m.RUnlock()
+	if policy != nil {
+		if err := policy.check(e); err != nil {
+			return err
+		}
+	}
+
+	i := c.WriteInterceptor()
+	if i == nil {
+		return fn()
+	}
+
+	e.ID = writeID.Add(1)
+	e.Time = time.Now()
+	e.Changes = redactChanges(e.Changes)
+	if e.Identity = user; e.Identity == "" {
+		e.Identity = c.Identity()
+	}
+	if err := i.BeforeWrite(e); err != nil {
+		return err
+	}
+
+	err := fn()
+	e.Time = time.Now()
+	e.Done = true
+	e.Err = err
+	i.AfterWrite(e)
+	return err
+}
+
+// JSONLinesSink is a WriteInterceptor that records write events as lines of
+// JSON. Each write produces two lines: one with a phase of "before" and one
+// with a phase of "after" that includes the result.
+//
+// If the event that precedes a write cannot be recorded, the write is not
+// made. Failures to record the event that follows a write are retained and
+// can be retrieved with Err.
+type JSONLinesSink struct {
+	m      sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	err    error
+}
+
+// jsonWriteEvent is the JSON form of a WriteEvent.
+type jsonWriteEvent struct {
+	ID       uint64    `json:"id"`
+	Time     time.Time `json:"time"`
+	Phase    string    `json:"phase"`
+	Op       WriteOp   `json:"op"`
+	Path     string    `json:"path"`
+	Class    string    `json:"class,omitempty"`
+	Target   string    `json:"target,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	Identity string    `json:"identity,omitempty"`
+	Changes  []Change  `json:"changes,omitempty"`
+	Result   string    `json:"result,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// NewJSONLinesSink returns a sink that writes events to w.
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{w: w}
+}
+
+// OpenJSONLinesSink opens the file at the given path for appending, creating
+// it if necessary, and returns a sink that writes events to it. The file is
+// synced after each event is written. The sink must be closed when it is no
+// longer needed.
+func OpenJSONLinesSink(path string) (*JSONLinesSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLinesSink{w: f, closer: f}, nil
+}
+
+// BeforeWrite records the event that precedes a write.
+func (s *JSONLinesSink) BeforeWrite(e WriteEvent) error {
+	return s.write(e)
+}
+
+// AfterWrite records the event that follows a write.
+func (s *JSONLinesSink) AfterWrite(e WriteEvent) {
+	if err := s.write(e); err != nil {
+		s.m.Lock()
+		if s.err == nil {
+			s.err = err
+		}
+		s.m.Unlock()
+	}
+}
+
+// Err returns the first error encountered while recording an event that
+// follows a write, if any.
+func (s *JSONLinesSink) Err() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.err
+}
+
+// Close closes the sink. If the sink was opened with OpenJSONLinesSink its
+// file is closed.
+func (s *JSONLinesSink) Close() error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.w = nil
+	if s.closer == nil {
+		return nil
+	}
+	err := s.closer.Close()
+	s.closer = nil
+	return err
+}
+
+func (s *JSONLinesSink) write(e WriteEvent) error {
+	je := jsonWriteEvent{
+		ID:       e.ID,
+		Time:     e.Time.UTC(),
+		Phase:    "before",
+		Op:       e.Op,
+		Path:     e.Path,
+		Class:    e.Class,
+		Target:   e.Target,
+		Name:     e.Name,
+		Identity: e.Identity,
+		Changes:  e.Changes,
+	}
+	if e.Done {
+		je.Phase = "after"
+		je.Result = "ok"
+		if e.Err != nil {
+			je.Result = "error"
+			je.Error = e.Err.Error()
+		}
+	}
+	line, err := json.Marshal(je)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.w == nil {
+		return ErrClosed
+	}
+	if _, err := s.w.Write(line); err != nil {
+		return err
+	}
+	if f, ok := s.closer.(*os.File); ok {
+		return f.Sync()
+	}
+	return nil
+}
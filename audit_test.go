@@ -0,0 +1,182 @@
+package adsi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRedactChanges(t *testing.T) {
+	changes := []Change{
+		{Name: "description", Old: []interface{}{"a"}, New: []interface{}{"b"}},
+		{Name: "unicodePwd", New: []interface{}{[]byte("\"secret\"")}},
+		{Name: "ms-Mcs-AdmPwd", Old: []interface{}{"x", "y"}},
+	}
+	want := []Change{
+		{Name: "description", Old: []interface{}{"a"}, New: []interface{}{"b"}},
+		{Name: "unicodePwd", New: []interface{}{Redacted}},
+		{Name: "ms-Mcs-AdmPwd", Old: []interface{}{Redacted, Redacted}},
+	}
+	got := redactChanges(changes)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("redactChanges() = %v, want %v", got, want)
+	}
+	got[0].New[0] = "modified"
+	if changes[0].New[0] != "b" {
+		t.Error("redactChanges() shares values with its argument")
+	}
+	if got := redactChanges(nil); got != nil {
+		t.Errorf("redactChanges(nil) = %v, want nil", got)
+	}
+}
+
+type recordingInterceptor struct {
+	events []WriteEvent
+	veto   error
+}
+
+func (r *recordingInterceptor) BeforeWrite(e WriteEvent) error {
+	r.events = append(r.events, e)
+	return r.veto
+}
+
+func (r *recordingInterceptor) AfterWrite(e WriteEvent) {
+	r.events = append(r.events, e)
+}
+
+func TestIntercept(t *testing.T) {
+	c := &Client{}
+	c.SetIdentity("EXAMPLE\\svc")
+	r := &recordingInterceptor{}
+	c.SetWriteInterceptor(r)
+
+	e := WriteEvent{
+		Op:      WriteSetInfo,
+		Path:    "LDAP://CN=Jane Doe,DC=example,DC=com",
+		Changes: []Change{{Name: "userPassword", New: []interface{}{"secret"}}},
+	}
+	errWrite := errors.New("write failed")
+	writes := 0
+	err := c.intercept(e, "", func() error {
+		writes++
+		return errWrite
+	})
+	if err != errWrite {
+		t.Errorf("intercept() = %v, want %v", err, errWrite)
+	}
+	if writes != 1 || len(r.events) != 2 {
+		t.Fatalf("got %d writes and %d events, want 1 and 2", writes, len(r.events))
+	}
+	before, after := r.events[0], r.events[1]
+	if before.Done || before.Err != nil || !after.Done || after.Err != errWrite {
+		t.Errorf("events = %+v, want a before and a failed after event", r.events)
+	}
+	if before.ID == 0 || before.ID != after.ID {
+		t.Errorf("event IDs = %d, %d, want the same non-zero ID", before.ID, after.ID)
+	}
+	if before.Identity != "EXAMPLE\\svc" {
+		t.Errorf("Identity = %q, want the client identity", before.Identity)
+	}
+	if got := before.Changes[0].New; !reflect.DeepEqual(got, []interface{}{Redacted}) {
+		t.Errorf("Changes[0].New = %v, want it redacted", got)
+	}
+	if e.Changes[0].New[0] != "secret" {
+		t.Error("intercept() modified the changes of the caller")
+	}
+
+	r.events = nil
+	r.veto = errors.New("vetoed")
+	err = c.intercept(e, "EXAMPLE\\jdoe", func() error {
+		writes++
+		return nil
+	})
+	if err != r.veto {
+		t.Errorf("intercept() = %v, want %v", err, r.veto)
+	}
+	if writes != 1 || len(r.events) != 1 {
+		t.Errorf("got %d writes and %d events after a veto, want 1 and 1", writes, len(r.events))
+	}
+	if r.events[0].Identity != "EXAMPLE\\jdoe" {
+		t.Errorf("Identity = %q, want the user of the object", r.events[0].Identity)
+	}
+}
+
+func TestJSONLinesSink(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewJSONLinesSink(&buf)
+	e := WriteEvent{
+		ID:       7,
+		Op:       WriteMove,
+		Path:     "LDAP://CN=Jane Doe,OU=Old,DC=example,DC=com",
+		Target:   "LDAP://OU=New,DC=example,DC=com",
+		Identity: "EXAMPLE\\svc",
+		Changes:  []Change{{Name: "description", New: []interface{}{"moved", int64(1)}}},
+	}
+	if err := s.BeforeWrite(e); err != nil {
+		t.Fatal(err)
+	}
+	e.Done = true
+	s.AfterWrite(e)
+	e.Err = errors.New("access denied")
+	s.AfterWrite(e)
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	for i, want := range []struct{ phase, result, err string }{
+		{"before", "", ""},
+		{"after", "ok", ""},
+		{"after", "error", "access denied"},
+	} {
+		line := lines[i]
+		if line["phase"] != want.phase || str(line["result"]) != want.result || str(line["error"]) != want.err {
+			t.Errorf("line %d = %v, want phase %q, result %q and error %q", i, line, want.phase, want.result, want.err)
+		}
+		if line["id"] != float64(7) || line["op"] != "move" || line["target"] != e.Target || line["identity"] != e.Identity {
+			t.Errorf("line %d = %v", i, line)
+		}
+	}
+
+	var first struct{ Changes []Change }
+	buf.Reset()
+	if err := s.BeforeWrite(e); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &first); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(first.Changes, e.Changes) {
+		t.Errorf("changes = %v, want %v", first.Changes, e.Changes)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.BeforeWrite(e); err != ErrClosed {
+		t.Errorf("BeforeWrite() after Close = %v, want ErrClosed", err)
+	}
+	s.AfterWrite(e)
+	if err := s.Err(); err != ErrClosed {
+		t.Errorf("Err() = %v, want ErrClosed", err)
+	}
+}
+
+func str(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
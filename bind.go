@@ -0,0 +1,124 @@
+package adsi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"github.com/google/uuid"
+	"github.com/scjalliance/comutil"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+)
+
+// WellKnownObject is an entry of the wellKnownObjects or
+// otherWellKnownObjects attribute of a domain head.
+type WellKnownObject struct {
+	// GUID is the well-known GUID of the object, as a lower case hexadecimal
+	// string like the adspath.WellKnown values.
+	GUID string
+
+	// DN is the current distinguished name of the object.
+	DN string
+
+	// Other is true if the object is listed in otherWellKnownObjects. Such
+	// objects, like the Managed Service Accounts container, cannot be bound
+	// to with adspath.WellKnownPath.
+	Other bool
+}
+
+// OpenByGUID opens the object with the given objectGUID through the LDAP
+// provider. The object is located on the given server or domain, or by
+// serverless binding if server is empty.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+func (c *Client) OpenByGUID(server string, guid uuid.UUID) (*Object, error) {
+	return c.Open(adspath.GUIDPath(adspath.LDAP, server, guid).String())
+}
+
+// OpenBySID opens the object with the given objectSid, in string form,
+// through the LDAP provider. The object is located on the given server or
+// domain, or by serverless binding if server is empty.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+func (c *Client) OpenBySID(server, sid string) (*Object, error) {
+	if _, err := ParseSID(sid); err != nil {
+		return nil, err
+	}
+	return c.Open(adspath.SIDPath(adspath.LDAP, server, sid).String())
+}
+
+// OpenWellKnown opens the well-known object with the given GUID, one of the
+// adspath.WellKnown values, in the domain whose head has the given ADsPath.
+// Objects listed in either the wellKnownObjects or the otherWellKnownObjects
+// attribute of the domain head are found.
+//
+// An error matching ErrWellKnownObjectNotFound is returned if the domain
+// does not list the object.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+func (c *Client) OpenWellKnown(domainPath, guid string) (*Object, error) {
+	objects, err := c.WellKnownObjects(domainPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, wk := range objects {
+		if strings.EqualFold(wk.GUID, guid) {
+			path, err := pathFromDN(domainPath, wk.DN)
+			if err != nil {
+				return nil, err
+			}
+			return c.Open(path)
+		}
+	}
+	return nil, fmt.Errorf("%w: %s in %s", ErrWellKnownObjectNotFound, guid, domainPath)
+}
+
+// WellKnownObjects returns the well-known objects listed in the
+// wellKnownObjects and otherWellKnownObjects attributes of the domain head
+// with the given ADsPath.
+func (c *Client) WellKnownObjects(domainPath string) ([]WellKnownObject, error) {
+	obj, err := c.Open(domainPath)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	obj.m.Lock()
+	defer obj.m.Unlock()
+	if obj.closed() {
+		return nil, ErrClosed
+	}
+	iunknown, err := obj.iface.QueryInterface(comutil.GUID(comiid.IDirectoryObject))
+	if err != nil {
+		return nil, err
+	}
+	dirobj := (*api.IDirectoryObject)(unsafe.Pointer(iunknown))
+	defer dirobj.Release()
+
+	attrs, err := dirobj.GetObjectAttributes([]string{"wellKnownObjects", "otherWellKnownObjects"})
+	if err != nil {
+		return nil, err
+	}
+	var objects []WellKnownObject
+	for _, attr := range attrs {
+		other := strings.EqualFold(attr.Name, "otherWellKnownObjects")
+		for _, value := range attr.Values {
+			v, ok := value.(api.DNWithBinary)
+			if !ok {
+				continue
+			}
+			objects = append(objects, WellKnownObject{GUID: hex.EncodeToString(v.Binary), DN: v.DN, Other: other})
+		}
+	}
+	return objects, nil
+}
@@ -0,0 +1,362 @@
+package adsi
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"unsafe"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+	ole "github.com/go-ole/go-ole"
+	"github.com/scjalliance/comutil"
+)
+
+// Change describes a modification of an attribute that has been staged in
+// the property cache of an object but not yet committed with SetInfo.
+type Change struct {
+	// Name is the name of the modified attribute.
+	Name string
+
+	// Old holds the values of the attribute before it was first modified. It
+	// is empty if the attribute was not populated.
+	Old []interface{}
+
+	// New holds the values that the attribute will have once the change is
+	// committed. It is empty if the attribute will be cleared.
+	New []interface{}
+}
+
+// String returns a human-readable description of the change in the form of
+// a single line of a diff.
+func (c Change) String() string {
+	switch {
+	case len(c.Old) == 0:
+		return fmt.Sprintf("+ %s: %s", c.Name, formatValues(c.New))
+	case len(c.New) == 0:
+		return fmt.Sprintf("- %s: %s", c.Name, formatValues(c.Old))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Name, formatValues(c.Old), formatValues(c.New))
+	}
+}
+
+// Dirty reports whether the object has staged modifications that have not yet
+// been committed with SetInfo.
+//
+// Only modifications made through the Put functions of the object are
+// tracked.
+func (o *object) Dirty() bool {
+	o.m.RLock()
+	defer o.m.RUnlock()
+	return len(o.changes) > 0
+}
+
+// Changes returns the staged modifications of the object in the order in
+// which the attributes were first modified.
+func (o *object) Changes() []Change {
+	o.m.RLock()
+	defer o.m.RUnlock()
+	changes := make([]Change, len(o.changes))
+	for i, c := range o.changes {
+		changes[i] = Change{
+			Name: c.Name,
+			Old:  append([]interface{}(nil), c.Old...),
+			New:  append([]interface{}(nil), c.New...),
+		}
+	}
+	return changes
+}
+
+// Diff returns a human-readable summary of the staged modifications of the
+// object, with one line per modified attribute. Added attributes are prefixed
+// with "+", cleared attributes with "-" and replaced attributes with "~".
+//
+// An empty string is returned if the object has no staged modifications.
+func (o *object) Diff() string {
+	var buf bytes.Buffer
+	for _, c := range o.Changes() {
+		buf.WriteString(c.String())
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// Discard abandons the staged modifications of the object. The modified
+// attributes are removed from the property cache and reloaded from the
+// underlying data store.
+func (o *object) Discard() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	if len(o.changes) == 0 {
+		return nil
+	}
+	names := make([]string, len(o.changes))
+	for i, c := range o.changes {
+		names[i] = c.Name
+	}
+	if err := o.revert(names...); err != nil {
+		return err
+	}
+	o.changes = nil
+	o.conditions = nil
+	return nil
+}
+
+// stage records a modification of the named attribute and applies it to the
+// property cache by calling put. The next function is passed the values that
+// the attribute currently holds in the cache and returns the values that it
+// will hold once the modification is committed. The caller must hold a lock
+// on the object.
+//
+// If the modification leaves the attribute with the values it had before it
+// was first modified, the attribute is reverted so that it isn't sent to the
+// directory by SetInfo.
+func (o *object) stage(name string, next func(current []interface{}) []interface{}, put func() error) error {
+	i := o.changeIndex(name)
+
+	var old, current []interface{}
+	if i >= 0 {
+		old, current = o.changes[i].Old, o.changes[i].New
+	} else {
+		if !o.created {
+			var err error
+			if old, err = o.cachedValues(name); err != nil {
+				return err
+			}
+		}
+		current = old
+	}
+
+	if err := put(); err != nil {
+		return err
+	}
+
+	values := normalizeValues(next(current))
+	if valuesEqual(old, values) {
+		if i >= 0 {
+			o.changes = append(o.changes[:i], o.changes[i+1:]...)
+		}
+		return o.revert(name)
+	}
+
+	if i >= 0 {
+		o.changes[i].New = values
+	} else {
+		o.changes = append(o.changes, Change{Name: name, Old: old, New: values})
+	}
+	return nil
+}
+
+// changeIndex returns the index of the staged modification for the named
+// attribute, or -1 if the attribute has not been modified. Attribute names are
+// compared without regard to case. The caller must hold a lock on the object.
+func (o *object) changeIndex(name string) int {
+	for i := range o.changes {
+		if strings.EqualFold(o.changes[i].Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Values returns the values of the named attribute in a form that can be
+// compared and retained: integers are returned as int64 and large integers
+// are converted to their int64 values. If the attribute is not populated an
+// empty slice is returned.
+//
+// Unlike Attr, Values never returns IUnknown or IDispatch members that must
+// be released; any other interfaces are omitted.
+func (o *object) Values(name string) ([]interface{}, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	if o.created {
+		if i := o.changeIndex(name); i >= 0 {
+			return append([]interface{}(nil), o.changes[i].New...), nil
+		}
+		return nil, nil
+	}
+	return o.cachedValues(name)
+}
+
+// cachedValues returns the normalized values of the named attribute. If the
+// attribute is not populated an empty slice is returned. The caller must hold
+// a lock on the object.
+func (o *object) cachedValues(name string) ([]interface{}, error) {
+	values, err := o.Attr(name)
+	if err != nil {
+		if api.IsHresult(err, api.E_ADS_PROPERTY_NOT_FOUND) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return normalizeValues(values), nil
+}
+
+// revert removes the named attributes from the property cache and reloads
+// them from the underlying data store. The caller must hold a lock on the
+// object.
+func (o *object) revert(names ...string) error {
+	idispatch, err := o.iface.QueryInterface(comutil.GUID(comiid.IADsPropertyList))
+	if err != nil {
+		return err
+	}
+	defer idispatch.Release()
+	list := (*api.IADsPropertyList)(unsafe.Pointer(idispatch))
+
+	for _, name := range names {
+		if err := list.ResetPropertyItem(name); err != nil {
+			return err
+		}
+	}
+	if o.created {
+		// There is nothing to reload for an object that doesn't exist yet.
+		return nil
+	}
+
+	v, err := comutil.BuildVarArrayStr(names...)
+	if err != nil {
+		return err
+	}
+	defer v.Clear()
+	return o.iface.GetInfoEx(v)
+}
+
+// normalizeValues converts attribute values into a form that can be compared
+// and retained after the underlying interfaces have been released. Integers
+// are converted to int64 and large integer interfaces are converted to their
+// int64 values. Any other interfaces are released and omitted.
+func normalizeValues(values []interface{}) []interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	normalized := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		switch v := value.(type) {
+		case int:
+			normalized = append(normalized, int64(v))
+		case int8:
+			normalized = append(normalized, int64(v))
+		case int16:
+			normalized = append(normalized, int64(v))
+		case int32:
+			normalized = append(normalized, int64(v))
+		case uint8:
+			normalized = append(normalized, int64(v))
+		case uint16:
+			normalized = append(normalized, int64(v))
+		case uint32:
+			normalized = append(normalized, int64(v))
+		case uint:
+			normalized = append(normalized, int64(v))
+		case uint64:
+			normalized = append(normalized, int64(v))
+		case *ole.IDispatch:
+			if i, err := dispatchToInt64(v); err == nil {
+				normalized = append(normalized, i)
+			}
+			v.Release()
+		case *ole.IUnknown:
+			v.Release()
+		case nil:
+		default:
+			normalized = append(normalized, v)
+		}
+	}
+	return normalized
+}
+
+// valuesEqual reports whether a and b hold the same set of values, without
+// regard to order.
+func valuesEqual(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, x := range a {
+		found := false
+		for j, y := range b {
+			if !used[j] && reflect.DeepEqual(x, y) {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// formatValues formats a set of attribute values for display.
+func formatValues(values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case string:
+			parts[i] = fmt.Sprintf("%q", v)
+		case []byte:
+			parts[i] = fmt.Sprintf("0x%x", v)
+		default:
+			parts[i] = fmt.Sprintf("%v", v)
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// replaceValues returns a function for stage that replaces the current values
+// of an attribute with the given values.
+func replaceValues(values ...interface{}) func([]interface{}) []interface{} {
+	return func([]interface{}) []interface{} {
+		return values
+	}
+}
+
+// applyControl returns the values that an attribute holding current will
+// hold after the given values are applied with the given ADS_PROPERTY control
+// code.
+func applyControl(control uint32, current, values []interface{}) []interface{} {
+	values = normalizeValues(values)
+	switch control {
+	case api.ADS_PROPERTY_CLEAR:
+		return nil
+	case api.ADS_PROPERTY_APPEND:
+		result := append([]interface{}(nil), current...)
+		for _, v := range values {
+			if !containsValue(result, v) {
+				result = append(result, v)
+			}
+		}
+		return result
+	case api.ADS_PROPERTY_DELETE:
+		var result []interface{}
+		for _, v := range current {
+			if !containsValue(values, v) {
+				result = append(result, v)
+			}
+		}
+		return result
+	default:
+		return values
+	}
+}
+
+// containsValue reports whether values contains v.
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, x := range values {
+		if reflect.DeepEqual(x, v) {
+			return true
+		}
+	}
+	return false
+}
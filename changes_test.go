@@ -0,0 +1,123 @@
+package adsi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+func TestValuesEqual(t *testing.T) {
+	for _, tt := range []struct {
+		a, b []interface{}
+		want bool
+	}{
+		{nil, nil, true},
+		{nil, []interface{}{}, true},
+		{[]interface{}{"a", "b"}, []interface{}{"b", "a"}, true},
+		{[]interface{}{int64(1), []byte{1}}, []interface{}{[]byte{1}, int64(1)}, true},
+		{[]interface{}{"a", "a"}, []interface{}{"a", "b"}, false},
+		{[]interface{}{"a"}, []interface{}{"A"}, false},
+		{[]interface{}{"a"}, []interface{}{"a", "a"}, false},
+		{[]interface{}{int64(1)}, []interface{}{int32(1)}, false},
+	} {
+		if got := valuesEqual(tt.a, tt.b); got != tt.want {
+			t.Errorf("valuesEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeValues(t *testing.T) {
+	got := normalizeValues([]interface{}{1, int32(2), uint64(3), nil, "x", []byte{4}, true})
+	want := []interface{}{int64(1), int64(2), int64(3), "x", []byte{4}, true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("normalizeValues() = %#v, want %#v", got, want)
+	}
+	if got := normalizeValues(nil); got != nil {
+		t.Errorf("normalizeValues(nil) = %#v, want nil", got)
+	}
+}
+
+func TestApplyControl(t *testing.T) {
+	current := []interface{}{"a", "b"}
+	for _, tt := range []struct {
+		control uint32
+		values  []interface{}
+		want    []interface{}
+	}{
+		{api.ADS_PROPERTY_UPDATE, []interface{}{"c"}, []interface{}{"c"}},
+		{api.ADS_PROPERTY_UPDATE, []interface{}{int32(1)}, []interface{}{int64(1)}},
+		{api.ADS_PROPERTY_APPEND, []interface{}{"b", "c"}, []interface{}{"a", "b", "c"}},
+		{api.ADS_PROPERTY_DELETE, []interface{}{"a", "c"}, []interface{}{"b"}},
+		{api.ADS_PROPERTY_DELETE, []interface{}{"a", "b"}, nil},
+		{api.ADS_PROPERTY_CLEAR, nil, nil},
+	} {
+		got := applyControl(tt.control, current, tt.values)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("applyControl(%d, %v, %v) = %#v, want %#v", tt.control, current, tt.values, got, tt.want)
+		}
+	}
+	if !reflect.DeepEqual(current, []interface{}{"a", "b"}) {
+		t.Errorf("applyControl modified the current values: %v", current)
+	}
+}
+
+func TestChangeString(t *testing.T) {
+	for _, tt := range []struct {
+		c    Change
+		want string
+	}{
+		{Change{Name: "description", New: []interface{}{"x"}}, `+ description: "x"`},
+		{Change{Name: "description", Old: []interface{}{"x"}}, `- description: "x"`},
+		{Change{Name: "otherTelephone", Old: []interface{}{"1"}, New: []interface{}{"1", "2"}}, `~ otherTelephone: "1" -> ["1", "2"]`},
+		{Change{Name: "userAccountControl", Old: []interface{}{int64(512)}, New: []interface{}{int64(514)}}, "~ userAccountControl: 512 -> 514"},
+		{Change{Name: "thumbnailPhoto", New: []interface{}{[]byte{0xff, 0xd8}}}, "+ thumbnailPhoto: 0xffd8"},
+	} {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestStage(t *testing.T) {
+	// Attributes of an object that has not been created yet have no values
+	// in the directory, so staging them doesn't read the property cache.
+	o := &object{created: true}
+	puts := 0
+	put := func() error {
+		puts++
+		return nil
+	}
+	steps := []struct {
+		name string
+		next func([]interface{}) []interface{}
+	}{
+		{"description", replaceValues("first")},
+		{"otherTelephone", replaceValues("555-0100")},
+		{"Description", replaceValues("second")},
+		{"otherTelephone", func(current []interface{}) []interface{} {
+			return applyControl(api.ADS_PROPERTY_APPEND, current, []interface{}{"555-0101"})
+		}},
+	}
+	for _, step := range steps {
+		if err := o.stage(step.name, step.next, put); err != nil {
+			t.Fatalf("stage(%q): %v", step.name, err)
+		}
+	}
+	if puts != len(steps) {
+		t.Errorf("put was called %d times, want %d", puts, len(steps))
+	}
+	want := []Change{
+		{Name: "description", New: []interface{}{"second"}},
+		{Name: "otherTelephone", New: []interface{}{"555-0100", "555-0101"}},
+	}
+	if got := o.Changes(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Changes() = %v, want %v", got, want)
+	}
+	if !o.Dirty() {
+		t.Error("Dirty() = false, want true")
+	}
+	if got, want := o.Diff(), "+ description: \"second\"\n+ otherTelephone: [\"555-0100\", \"555-0101\"]\n"; got != want {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+}
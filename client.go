@@ -1,6 +1,7 @@
 package adsi
 
 import (
+	"fmt"
 	"strings"
 	"sync"
 	"unsafe"
@@ -25,9 +26,13 @@ type namespace struct {
 // Client provides access to Active Directory Service Interfaces for
 // any namespace supported by a local or remote COM server.
 type Client struct {
-	m     sync.RWMutex
-	n     []namespace
-	flags uint32
+	m           sync.RWMutex
+	n           []namespace
+	flags       uint32
+	journal     *UndoJournal
+	interceptor WriteInterceptor
+	identity    string
+	policy      *WritePolicy
 }
 
 // NewClient creates a new ADSI client. When done with a client it should be
@@ -104,8 +109,10 @@ func (c *Client) init(server string) (err error) {
 		item.Iface = (*api.IADsOpenDSObject)(unsafe.Pointer(idisp))
 	}
 
-	// TODO: Check the value of iterErr to see if it returned something other than
-	//       io.EOF.
+	if err = iter.Err(); err != nil {
+		c.release()
+		return err
+	}
 
 	return
 }
@@ -123,6 +130,11 @@ func (c *Client) Close() {
 		return
 	}
 	defer comshim.Done()
+	c.release()
+}
+
+// release releases the namespace interfaces held by the client.
+func (c *Client) release() {
 	for i := 0; i < len(c.n); i++ {
 		if c.n[i].Iface != nil {
 			c.n[i].Iface.Release()
@@ -145,6 +157,44 @@ func (c *Client) SetFlags(flags uint32) {
 	c.flags = flags
 }
 
+// UndoJournal returns the undo journal of the client, or nil if none has been
+// set.
+func (c *Client) UndoJournal() *UndoJournal {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return c.journal
+}
+
+// SetUndoJournal sets the journal that records the reverse changes of every
+// modification made through objects opened by the client. A nil journal
+// stops recording. The client does not close the journal.
+//
+// The journal only records modifications made after it has been set. If a
+// reverse change cannot be written to the journal, the operation that
+// produced it returns the error even though the modification itself has
+// been made.
+func (c *Client) SetUndoJournal(j *UndoJournal) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.journal = j
+}
+
+// recordUndo writes reverse changes to the undo journal of the client, if
+// any. It may be called on a nil client.
+func (c *Client) recordUndo(changes []ReverseChange) error {
+	if c == nil {
+		return nil
+	}
+	j := c.UndoJournal()
+	if j == nil {
+		return nil
+	}
+	if err := j.Record(changes...); err != nil {
+		return fmt.Errorf("undo journal: %w", err)
+	}
+	return nil
+}
+
 // Open opens an ADSI object with the given path. The existing security
 // context of the application and any flags specified via SetFlags will be
 // used when making the connection. The default flags specify an encrypted
@@ -191,6 +241,8 @@ func (c *Client) OpenSC(path, user, password string, flags uint32) (obj *Object,
 	}
 	iface := (*api.IADs)(unsafe.Pointer(idispatch))
 	obj = NewObject(iface)
+	obj.client = c
+	obj.user = user
 	return
 }
 
@@ -240,6 +292,8 @@ func (c *Client) OpenContainerSC(path, user, password string, flags uint32) (con
 	}
 	iface := (*api.IADsContainer)(unsafe.Pointer(idispatch))
 	container = NewContainer(iface)
+	container.client = c
+	container.user = user
 	return
 }
 
@@ -289,6 +343,8 @@ func (c *Client) OpenComputerSC(path, user, password string, flags uint32) (comp
 	}
 	iface := (*api.IADsComputer)(unsafe.Pointer(idispatch))
 	computer = NewComputer(iface)
+	computer.client = c
+	computer.user = user
 	return
 }
 
@@ -25,7 +25,7 @@ func main() {
 	}
 	defer root.Close()
 
-	adlist, err := fetchChildren(root, 0)
+	adlist, err := fetchChildren(root)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -34,54 +34,36 @@ func main() {
 	}
 }
 
-func fetchChildren(parent *adsi.Object, depth int) (groups []string, err error) {
-	c, err := parent.ToContainer()
-	if err != nil {
-		return
-	}
-	defer c.Close()
-
-	iter, err := c.Children()
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer iter.Close()
-
-	i := 0
-	for child, err := iter.Next(); err == nil; child, err = iter.Next() {
-		defer child.Close()
-		name, err := child.Name()
+func fetchChildren(root *adsi.Object) (groups []string, err error) {
+	err = adsi.Walk(root, func(path string, obj *adsi.Object, depth int, err error) error {
 		if err != nil {
-			log.Fatal(err)
+			log.Printf("%s: %v", path, err)
+			return nil
 		}
-		guid, err := child.GUID()
+		if depth == 0 {
+			return nil
+		}
+		name, err := obj.Name()
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
-		class, err := child.Class()
+		guid, err := obj.GUID()
 		if err != nil {
-			log.Fatal(err)
+			return err
+		}
+		class, err := obj.Class()
+		if err != nil {
+			return err
 		}
 
-		cdepth := depth + 1
-
-		plen := 55 - len(name) - depth*2
+		plen := 55 - len(name) - (depth-1)*2
 		if plen < 0 {
 			plen = 0
 		}
 		padding := strings.Repeat(" ", plen)
-		groups = append(groups, fmt.Sprintf("%s%s %s %s %s", strings.Repeat("  ", cdepth), name, padding, guid, class))
-
-		sub, err := fetchChildren(child, cdepth)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		groups = append(groups, sub...)
-
-		i++
-	}
-
+		groups = append(groups, fmt.Sprintf("%s%s %s %s %s", strings.Repeat("  ", depth), name, padding, guid, class))
+		return nil
+	}, nil)
 	return
 }
 
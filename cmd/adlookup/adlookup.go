@@ -9,6 +9,8 @@ import (
 	"github.com/go-adsi/adsi/adspath"
 )
 
+var showAttrs = flag.Bool("a", false, "list every populated attribute of the object")
+
 func main() {
 	flag.Parse()
 	if flag.NArg() != 1 {
@@ -32,6 +34,10 @@ func main() {
 	log.Println("--------")
 
 	show(obj)
+
+	if *showAttrs {
+		showAttributes(obj)
+	}
 }
 
 func show(obj *adsi.Object) {
@@ -48,3 +54,16 @@ func show(obj *adsi.Object) {
 	log.Printf("Parent: %v\n", parent)
 	log.Printf("Schema: %v\n", schema)
 }
+
+func showAttributes(obj *adsi.Object) {
+	attrs, err := obj.Attributes()
+	if err != nil {
+		log.Fatalf("Unable to read attributes: %v\n", err)
+	}
+	log.Println("--------")
+	for _, attr := range attrs {
+		for _, value := range attr.Values {
+			log.Printf("%s: %v\n", attr.Name, value)
+		}
+	}
+}
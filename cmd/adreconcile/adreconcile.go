@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/reconcile"
+)
+
+var apply = flag.Bool("apply", false, "apply the plan instead of only printing it")
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	doc, err := reconcile.LoadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatalf("Unable to load document: %v\n", err)
+	}
+
+	client, err := adsi.NewClient()
+	if err != nil {
+		log.Fatalf("Unable to create client: %v\n", err)
+	}
+	defer client.Close()
+	if *apply {
+		// Writes must be sent to a writable domain controller.
+		client.SetFlags(client.Flags() &^ api.ADS_READONLY_SERVER)
+	}
+
+	plan, err := reconcile.Compute(client, doc)
+	if err != nil {
+		log.Fatalf("Unable to compute plan: %v\n", err)
+	}
+	if plan.Empty() {
+		log.Println("The directory is already in the desired state.")
+		return
+	}
+	fmt.Print(plan)
+
+	if !*apply {
+		return
+	}
+	if err := reconcile.Apply(client, plan); err != nil {
+		log.Fatalf("Unable to apply plan: %v\n", err)
+	}
+}
@@ -44,6 +44,20 @@ var (
 	// {EFE3CC70-1D9F-11CF-B1F3-02608C9E7553}
 	IADsComputer = uuid.UUID{0xEF, 0xE3, 0xCC, 0x70, 0x1D, 0x9F, 0x11, 0xCF, 0xB1, 0xF3, 0x02, 0x60, 0x8C, 0x9E, 0x75, 0x53}
 
+	// IADsComputerOperations is the component object model identifier of the
+	// IADsComputerOperations interface.
+	//
+	// IID_IADsComputerOperations
+	// {EF497680-1D9F-11CF-B1F3-02608C9E7553}
+	IADsComputerOperations = uuid.UUID{0xEF, 0x49, 0x76, 0x80, 0x1D, 0x9F, 0x11, 0xCF, 0xB1, 0xF3, 0x02, 0x60, 0x8C, 0x9E, 0x75, 0x53}
+
+	// IADsDomain is the component object model identifier of the
+	// IADsDomain interface.
+	//
+	// IID_IADsDomain
+	// {00E4C220-FD16-11CE-ABC4-02608C9E7553}
+	IADsDomain = uuid.UUID{0x00, 0xE4, 0xC2, 0x20, 0xFD, 0x16, 0x11, 0xCE, 0xAB, 0xC4, 0x02, 0x60, 0x8C, 0x9E, 0x75, 0x53}
+
 	// IADsGroup is the component object model identifier of the
 	// IADsGroup interface.
 	//
@@ -90,4 +104,39 @@ var (
 	// IID_IADsUser
 	// {3e37e320-17e2-11cf-abc4-02608c9e7553}
 	IADsUser = uuid.UUID{0x3e, 0x37, 0xe3, 0x20, 0x17, 0xe2, 0x11, 0xcf, 0xab, 0xc4, 0x02, 0x60, 0x8c, 0x9e, 0x75, 0x53}
+
+	// IADsPropertyList is the component object model identifier of the
+	// IADsPropertyList interface.
+	//
+	// IID_IADsPropertyList
+	// {C6F602B6-8F69-11D0-8528-00C04FD8D503}
+	IADsPropertyList = uuid.UUID{0xC6, 0xF6, 0x02, 0xB6, 0x8F, 0x69, 0x11, 0xD0, 0x85, 0x28, 0x00, 0xC0, 0x4F, 0xD8, 0xD5, 0x03}
+
+	// IADsPropertyEntry is the component object model identifier of the
+	// IADsPropertyEntry interface.
+	//
+	// IID_IADsPropertyEntry
+	// {05792C8E-941F-11D0-8529-00C04FD8D503}
+	IADsPropertyEntry = uuid.UUID{0x05, 0x79, 0x2C, 0x8E, 0x94, 0x1F, 0x11, 0xD0, 0x85, 0x29, 0x00, 0xC0, 0x4F, 0xD8, 0xD5, 0x03}
+
+	// IDirectoryObject is the component object model identifier of the
+	// IDirectoryObject interface.
+	//
+	// IID_IDirectoryObject
+	// {E798DE2C-22E4-11D0-84FE-00C04FD8D503}
+	IDirectoryObject = uuid.UUID{0xE7, 0x98, 0xDE, 0x2C, 0x22, 0xE4, 0x11, 0xD0, 0x84, 0xFE, 0x00, 0xC0, 0x4F, 0xD8, 0xD5, 0x03}
+
+	// IADsObjectOptions is the component object model identifier of the
+	// IADsObjectOptions interface.
+	//
+	// IID_IADsObjectOptions
+	// {46F14FDA-232B-11D1-A808-00C04FD8D5A8}
+	IADsObjectOptions = uuid.UUID{0x46, 0xF1, 0x4F, 0xDA, 0x23, 0x2B, 0x11, 0xD1, 0xA8, 0x08, 0x00, 0xC0, 0x4F, 0xD8, 0xD5, 0xA8}
+
+	// IADsDeleteOps is the component object model identifier of the
+	// IADsDeleteOps interface.
+	//
+	// IID_IADsDeleteOps
+	// {B2BD0902-8878-11D1-8C21-00C04FD8D503}
+	IADsDeleteOps = uuid.UUID{0xB2, 0xBD, 0x09, 0x02, 0x88, 0x78, 0x11, 0xD1, 0x8C, 0x21, 0x00, 0xC0, 0x4F, 0xD8, 0xD5, 0x03}
 )
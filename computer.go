@@ -1,9 +1,17 @@
 package adsi
 
 import (
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
 	"github.com/scjalliance/comshim"
+	"github.com/scjalliance/comutil"
 
 	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
 )
 
 // Computer provides access to Active Directory computers.
@@ -68,3 +76,546 @@ func (c *Computer) OperatingSystem() (kind string, err error) {
 	kind, err = c.iface.OperatingSystem()
 	return
 }
+
+// Location retrieves the physical location of the computer. For providers
+// that do not support the property, such as LDAP, the location attribute is
+// returned instead.
+func (c *Computer) Location() (value string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.fallback(c.iface.Location, "location")
+}
+
+// PrimaryUser retrieves the name of the contact person for the computer.
+func (c *Computer) PrimaryUser() (value string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.iface.PrimaryUser()
+}
+
+// Owner retrieves the name of the owner of the computer.
+func (c *Computer) Owner() (value string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.iface.Owner()
+}
+
+// Division retrieves the division of the organization that owns the computer.
+func (c *Computer) Division() (value string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.iface.Division()
+}
+
+// Department retrieves the department of the organization that owns the
+// computer.
+func (c *Computer) Department() (value string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.iface.Department()
+}
+
+// Role retrieves the role of the computer.
+func (c *Computer) Role() (value string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.iface.Role()
+}
+
+// OperatingSystemVersion retrieves the version of the operating system of the
+// computer. For providers that do not support the property, such as LDAP, the
+// operatingSystemVersion attribute is returned instead.
+func (c *Computer) OperatingSystemVersion() (value string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.fallback(c.iface.OperatingSystemVersion, "operatingSystemVersion")
+}
+
+// Model retrieves the make and model of the computer.
+func (c *Computer) Model() (value string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.iface.Model()
+}
+
+// Processor retrieves the type of processor of the computer.
+func (c *Computer) Processor() (value string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.iface.Processor()
+}
+
+// ProcessorCount retrieves the number of processors of the computer.
+func (c *Computer) ProcessorCount() (value string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.iface.ProcessorCount()
+}
+
+// MemorySize retrieves the size of the random access memory of the computer in
+// megabytes.
+func (c *Computer) MemorySize() (value string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.iface.MemorySize()
+}
+
+// StorageCapacity retrieves the disk space of the computer in megabytes.
+func (c *Computer) StorageCapacity() (value string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.iface.StorageCapacity()
+}
+
+// Description retrieves the description of the computer. For providers that
+// do not support the property, such as LDAP, the description attribute is
+// returned instead.
+func (c *Computer) Description() (desc string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	return c.fallback(c.iface.Description, "description")
+}
+
+// NetAddresses retrieves the network addresses of the computer.
+func (c *Computer) NetAddresses() (addrs []string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return nil, ErrClosed
+	}
+	variant, err := c.iface.NetAddresses()
+	if err != nil {
+		return nil, err
+	}
+	defer variant.Clear()
+
+	if array := variant.ToArray(); array != nil {
+		values, err := comutil.SafeArrayToVariantSlice(array)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range normalizeValues(values) {
+			if s, ok := value.(string); ok {
+				addrs = append(addrs, s)
+			}
+		}
+		return addrs, nil
+	}
+	if s, ok := variant.Value().(string); ok && s != "" {
+		addrs = append(addrs, s)
+	}
+	return addrs, nil
+}
+
+// ComputerStatus is the operational status code of a computer, as reported
+// by the IADsComputerOperations interface. Its meaning is defined by the
+// provider.
+type ComputerStatus int32
+
+// Status retrieves the operational status of the computer from the
+// IADsComputerOperations interface. The status is read from the default
+// property of the status object that the provider returns.
+//
+// Few providers implement the operation; those that don't return an error.
+func (c *Computer) Status() (status ComputerStatus, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return 0, ErrClosed
+	}
+	idispatch, err := c.iface.QueryInterface(comutil.GUID(comiid.IADsComputerOperations))
+	if err != nil {
+		return 0, err
+	}
+	defer idispatch.Release()
+	ops := (*api.IADsComputerOperations)(unsafe.Pointer(idispatch))
+	obj, err := ops.Status()
+	if err != nil {
+		return 0, err
+	}
+	if obj == nil {
+		return 0, ole.NewError(ole.E_POINTER)
+	}
+	defer obj.Release()
+
+	variant, err := obj.Invoke(ole.DISPID_VALUE, ole.DISPATCH_PROPERTYGET)
+	if err != nil {
+		return 0, err
+	}
+	defer variant.Clear()
+	values := normalizeValues([]interface{}{variant.Value()})
+	if len(values) == 0 {
+		return 0, fmt.Errorf("computer status has no value of a supported type (VARIANT type %d)", variant.VT)
+	}
+	v, ok := values[0].(int64)
+	if !ok {
+		return 0, fmt.Errorf("computer status has unexpected type %T", values[0])
+	}
+	return ComputerStatus(v), nil
+}
+
+// SetDescription sets the description of the computer. For providers that do
+// not support the property, such as LDAP, the description attribute is set
+// instead. The value must be committed with SetInfo to be made persistent.
+func (c *Computer) SetDescription(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("Description", "description", value, c.iface.SetDescription)
+}
+
+// SetLocation sets the physical location of the computer. For providers that
+// do not support the property, such as LDAP, the location attribute is set
+// instead. The value must be committed with SetInfo to be made persistent.
+func (c *Computer) SetLocation(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("Location", "location", value, c.iface.SetLocation)
+}
+
+// SetPrimaryUser sets the name of the contact person for the computer. The
+// value must be committed with SetInfo to be made persistent.
+func (c *Computer) SetPrimaryUser(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("PrimaryUser", "", value, c.iface.SetPrimaryUser)
+}
+
+// SetOwner sets the name of the owner of the computer. The value must be
+// committed with SetInfo to be made persistent.
+func (c *Computer) SetOwner(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("Owner", "", value, c.iface.SetOwner)
+}
+
+// SetDivision sets the division of the organization that owns the computer.
+// The value must be committed with SetInfo to be made persistent.
+func (c *Computer) SetDivision(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("Division", "", value, c.iface.SetDivision)
+}
+
+// SetDepartment sets the department of the organization that owns the
+// computer. The value must be committed with SetInfo to be made persistent.
+func (c *Computer) SetDepartment(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("Department", "", value, c.iface.SetDepartment)
+}
+
+// SetRole sets the role of the computer. The value must be committed with
+// SetInfo to be made persistent.
+func (c *Computer) SetRole(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("Role", "", value, c.iface.SetRole)
+}
+
+// SetOperatingSystem sets the operating system of the computer. The value must
+// be committed with SetInfo to be made persistent.
+func (c *Computer) SetOperatingSystem(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("OperatingSystem", "", value, c.iface.SetOperatingSystem)
+}
+
+// SetOperatingSystemVersion sets the version of the operating system of the
+// computer. For providers that do not support the property, such as LDAP, the
+// operatingSystemVersion attribute is set instead. The value must be committed
+// with SetInfo to be made persistent.
+func (c *Computer) SetOperatingSystemVersion(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("OperatingSystemVersion", "operatingSystemVersion", value, c.iface.SetOperatingSystemVersion)
+}
+
+// SetModel sets the make and model of the computer. The value must be
+// committed with SetInfo to be made persistent.
+func (c *Computer) SetModel(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("Model", "", value, c.iface.SetModel)
+}
+
+// SetProcessor sets the type of processor of the computer. The value must be
+// committed with SetInfo to be made persistent.
+func (c *Computer) SetProcessor(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("Processor", "", value, c.iface.SetProcessor)
+}
+
+// SetProcessorCount sets the number of processors of the computer. The value
+// must be committed with SetInfo to be made persistent.
+func (c *Computer) SetProcessorCount(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("ProcessorCount", "", value, c.iface.SetProcessorCount)
+}
+
+// SetMemorySize sets the size of the random access memory in megabytes of the
+// computer. The value must be committed with SetInfo to be made persistent.
+func (c *Computer) SetMemorySize(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("MemorySize", "", value, c.iface.SetMemorySize)
+}
+
+// SetStorageCapacity sets the disk space in megabytes of the computer. The
+// value must be committed with SetInfo to be made persistent.
+func (c *Computer) SetStorageCapacity(value string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	return c.setProperty("StorageCapacity", "", value, c.iface.SetStorageCapacity)
+}
+
+// setProperty stages the modification of a property of the computer, which
+// is applied to the property cache by calling set. If the provider does not
+// support the property and attr is not empty, the named attribute is
+// modified instead, as the getters read it. The caller must hold a lock on
+// the computer.
+func (c *Computer) setProperty(property, attr, value string, set func(string) error) error {
+	err := c.stage(property, replaceValues(value), func() error { return set(value) })
+	if err == nil || attr == "" || !unsupported(err) {
+		return err
+	}
+	return c.stage(attr, replaceValues(value), func() error {
+		return c.iface.PutString(attr, value)
+	})
+}
+
+// DNSHostName retrieves the fully qualified DNS name of the computer from the
+// dNSHostName attribute.
+func (c *Computer) DNSHostName() (name string, err error) {
+	return c.ldapString("dNSHostName")
+}
+
+// ManagedBy retrieves the distinguished name of the user or group that is
+// responsible for the computer from the managedBy attribute.
+func (c *Computer) ManagedBy() (dn string, err error) {
+	return c.ldapString("managedBy")
+}
+
+// ServicePrincipalNames retrieves the service principal names registered for
+// the computer account from the servicePrincipalName attribute.
+func (c *Computer) ServicePrincipalNames() (spns []string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return nil, ErrClosed
+	}
+	if err = c.Pull("servicePrincipalName"); err != nil {
+		return nil, err
+	}
+	return optional(c.AttrStringSlice("servicePrincipalName"))
+}
+
+// LastLogonTimestamp retrieves the time of the last logon of the computer
+// account from the lastLogonTimestamp attribute. The attribute is replicated
+// between domain controllers only when it is more than about two weeks out of
+// date, so the time is approximate. It is zero if the computer has never
+// logged on.
+func (c *Computer) LastLogonTimestamp() (t time.Time, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return t, ErrClosed
+	}
+	if err = c.Pull("lastLogonTimestamp"); err != nil {
+		return t, err
+	}
+	v, err := optional(c.AttrInt64("lastLogonTimestamp"))
+	if err != nil {
+		return t, err
+	}
+	return fileTime(v), nil
+}
+
+// SupportedEncryptionTypes retrieves the Kerberos encryption types that the
+// computer supports from the msDS-SupportedEncryptionTypes attribute. It is
+// zero if the attribute is not set, in which case the domain controller
+// assumes RC4.
+func (c *Computer) SupportedEncryptionTypes() (types EncryptionTypes, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return 0, ErrClosed
+	}
+	if err = c.Pull("msDS-SupportedEncryptionTypes"); err != nil {
+		return 0, err
+	}
+	v, err := optional(c.AttrInt64("msDS-SupportedEncryptionTypes"))
+	return EncryptionTypes(uint32(v)), err
+}
+
+// ldapString reads a single-valued string attribute from the directory. It
+// returns an empty string if the attribute is not set.
+func (c *Computer) ldapString(name string) (string, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	if err := c.Pull(name); err != nil {
+		return "", err
+	}
+	return optional(c.AttrString(name))
+}
+
+// fallback calls the given property getter. If the provider does not support
+// the property, the named attribute is read instead. The caller must hold a
+// lock on the computer.
+func (c *Computer) fallback(get func() (string, error), attr string) (string, error) {
+	value, err := get()
+	if err == nil || !unsupported(err) {
+		return value, err
+	}
+	if err := c.Pull(attr); err != nil {
+		return "", err
+	}
+	return optional(c.AttrString(attr))
+}
+
+// unsupported reports whether err indicates that a provider does not
+// implement a property or method.
+func unsupported(err error) bool {
+	return api.IsHresult(err, api.E_ADS_PROPERTY_NOT_SUPPORTED) ||
+		api.IsHresult(err, api.E_ADS_PROPERTY_NOT_FOUND) ||
+		api.IsHresult(err, ole.E_NOTIMPL)
+}
+
+// EncryptionTypes is a set of Kerberos encryption types, as stored in the
+// msDS-SupportedEncryptionTypes attribute.
+type EncryptionTypes uint32
+
+// Kerberos encryption types.
+const (
+	DESCBCCRC                      EncryptionTypes = 0x1
+	DESCBCMD5                      EncryptionTypes = 0x2
+	RC4HMAC                        EncryptionTypes = 0x4
+	AES128                         EncryptionTypes = 0x8
+	AES256                         EncryptionTypes = 0x10
+	AES256SK                       EncryptionTypes = 0x20
+	FASTSupported                  EncryptionTypes = 0x10000
+	CompoundIdentitySupported      EncryptionTypes = 0x20000
+	ClaimsSupported                EncryptionTypes = 0x40000
+	ResourceSIDCompressionDisabled EncryptionTypes = 0x80000
+)
+
+var encryptionTypeNames = []struct {
+	t    EncryptionTypes
+	name string
+}{
+	{DESCBCCRC, "DES-CBC-CRC"},
+	{DESCBCMD5, "DES-CBC-MD5"},
+	{RC4HMAC, "RC4-HMAC"},
+	{AES128, "AES128-CTS-HMAC-SHA1-96"},
+	{AES256, "AES256-CTS-HMAC-SHA1-96"},
+	{AES256SK, "AES256-CTS-HMAC-SHA1-96-SK"},
+	{FASTSupported, "FAST-supported"},
+	{CompoundIdentitySupported, "Compound-identity-supported"},
+	{ClaimsSupported, "Claims-supported"},
+	{ResourceSIDCompressionDisabled, "Resource-SID-compression-disabled"},
+}
+
+// Has reports whether all of the given types are in the set.
+func (t EncryptionTypes) Has(types EncryptionTypes) bool {
+	return t&types == types
+}
+
+// String returns the names of the types in the set, separated by commas.
+func (t EncryptionTypes) String() string {
+	var names []string
+	for _, e := range encryptionTypeNames {
+		if t&e.t != 0 {
+			names = append(names, e.name)
+			t &^= e.t
+		}
+	}
+	if t != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint32(t)))
+	}
+	return strings.Join(names, ",")
+}
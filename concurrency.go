@@ -0,0 +1,273 @@
+package adsi
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+	"github.com/scjalliance/comutil"
+)
+
+// Version identifies the revision of a directory object that was observed
+// when it was read. It is used to make conditional writes that fail if the
+// object has been modified since.
+//
+// Zero-valued fields are not checked.
+type Version struct {
+	// USNChanged is the update sequence number of the most recent change to
+	// the object on the directory server that was read.
+	USNChanged int64
+
+	// WhenChanged is the time of the most recent change to the object on the
+	// directory server that was read.
+	WhenChanged time.Time
+
+	// Attrs holds the replication version of individual attributes, keyed by
+	// attribute name in lower case.
+	Attrs map[string]uint32
+}
+
+// ConflictError is returned by a conditional write when the object no longer
+// matches the version that was expected. It matches ErrConflict with
+// errors.Is.
+type ConflictError struct {
+	// Attr is the name of the attribute that no longer matches.
+	Attr string
+
+	// Expected is the value that was expected.
+	Expected interface{}
+
+	// Actual is the value that was found, if known.
+	Actual interface{}
+}
+
+// Error returns a description of the conflict.
+func (e *ConflictError) Error() string {
+	if e.Actual == nil {
+		return fmt.Sprintf("%v: %s does not hold the expected value %v", ErrConflict, e.Attr, e.Expected)
+	}
+	return fmt.Sprintf("%v: %s is %v, expected %v", ErrConflict, e.Attr, e.Actual, e.Expected)
+}
+
+// Is reports whether target is ErrConflict.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Version reads the current version of the object from the underlying data
+// store. If attribute names are provided, the replication version of each of
+// them is included as well.
+//
+// Versions are only supported by the LDAP provider.
+func (o *object) Version(attrs ...string) (v Version, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return v, ErrClosed
+	}
+	return o.readVersion(attrs)
+}
+
+// readVersion reads the version of the object. The caller must hold a lock on
+// the object.
+func (o *object) readVersion(attrs []string) (v Version, err error) {
+	names := []string{"uSNChanged", "whenChanged"}
+	if len(attrs) > 0 {
+		names = append(names, "msDS-ReplAttributeMetaData")
+	}
+	if err = o.Pull(names...); err != nil {
+		return
+	}
+
+	if v.USNChanged, err = o.AttrInt64("uSNChanged"); err != nil {
+		return
+	}
+
+	values, err := o.Attr("whenChanged")
+	if err != nil {
+		return
+	}
+	for _, value := range normalizeValues(values) {
+		if t, ok := value.(time.Time); ok {
+			v.WhenChanged = t
+			break
+		}
+	}
+
+	if len(attrs) == 0 {
+		return
+	}
+	metadata, err := o.AttrStringSlice("msDS-ReplAttributeMetaData")
+	if err != nil && !api.IsHresult(err, api.E_ADS_PROPERTY_NOT_FOUND) {
+		return
+	}
+	versions, err := parseReplAttributeMetaData(metadata)
+	if err != nil {
+		return
+	}
+	v.Attrs = make(map[string]uint32, len(attrs))
+	for _, attr := range attrs {
+		// Attributes that have never been written have a version of zero.
+		v.Attrs[strings.ToLower(attr)] = versions[strings.ToLower(attr)]
+	}
+	return v, nil
+}
+
+// replAttrMetaData is the XML form of a DS_REPL_ATTR_META_DATA value of the
+// msDS-ReplAttributeMetaData attribute.
+type replAttrMetaData struct {
+	Name    string `xml:"pszAttributeName"`
+	Version uint32 `xml:"dwVersion"`
+}
+
+// parseReplAttributeMetaData returns the replication version of each
+// attribute described by the given msDS-ReplAttributeMetaData values, keyed
+// by attribute name in lower case.
+func parseReplAttributeMetaData(values []string) (map[string]uint32, error) {
+	versions := make(map[string]uint32, len(values))
+	for _, value := range values {
+		var md replAttrMetaData
+		if err := xml.Unmarshal([]byte(strings.TrimRight(value, "\x00")), &md); err != nil {
+			return nil, fmt.Errorf("invalid replication metadata: %v", err)
+		}
+		versions[strings.ToLower(md.Name)] = md.Version
+	}
+	return versions, nil
+}
+
+// checkVersion returns a *ConflictError if the object no longer matches the
+// expected version. The caller must hold a lock on the object.
+func (o *object) checkVersion(expected Version) error {
+	attrs := make([]string, 0, len(expected.Attrs))
+	for attr := range expected.Attrs {
+		attrs = append(attrs, attr)
+	}
+	actual, err := o.readVersion(attrs)
+	if err != nil {
+		return err
+	}
+	if expected.USNChanged != 0 && actual.USNChanged != expected.USNChanged {
+		return &ConflictError{Attr: "uSNChanged", Expected: expected.USNChanged, Actual: actual.USNChanged}
+	}
+	if !expected.WhenChanged.IsZero() && !actual.WhenChanged.Equal(expected.WhenChanged) {
+		return &ConflictError{Attr: "whenChanged", Expected: expected.WhenChanged, Actual: actual.WhenChanged}
+	}
+	for attr, version := range expected.Attrs {
+		if actual.Attrs[attr] != version {
+			return &ConflictError{Attr: attr, Expected: version, Actual: actual.Attrs[attr]}
+		}
+	}
+	return nil
+}
+
+// SetInfoIf saves the cached property values of the ADSI object to the
+// underlying directory store if the object still matches the expected
+// version. If it does not, a *ConflictError is returned and the staged
+// modifications are retained.
+//
+// The version is checked immediately before the values are saved, which
+// narrows but does not eliminate the window in which a concurrent
+// modification can go unnoticed. Use CompareAndSwap when an atomic check is
+// required.
+func (o *object) SetInfoIf(expected Version) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	if err := o.checkVersion(expected); err != nil {
+		return err
+	}
+	return o.setInfo()
+}
+
+// PutExIf modifies the values of an attribute in the ADSI attribute cache in
+// the same manner as PutEx, and records the expected version as a condition
+// of the next call to SetInfo. If the object no longer matches the expected
+// version when SetInfo is called, SetInfo returns a *ConflictError without
+// saving any values.
+func (o *object) PutExIf(expected Version, name string, control uint32, values ...interface{}) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	if err := o.putEx(name, control, values); err != nil {
+		return err
+	}
+	o.conditions = append(o.conditions, expected)
+	return nil
+}
+
+// CompareAndSwap atomically replaces the old values of an attribute with new
+// values directly in the underlying data store, bypassing the attribute
+// cache. The old values are deleted and the new values are added in a single
+// modify request, which the server rejects if any of the old values are no
+// longer present. In that case a *ConflictError is returned and the
+// attribute is left unmodified.
+//
+// Old must hold every value of the attribute that is to be replaced. Each
+// value must be a string, bool, int, int32, uint32, int64 or []byte. Old must
+// not be empty: a modify request cannot require an attribute to be absent,
+// so the swap would not be conditional. Use SetInfoIf with a Version that
+// includes the attribute to populate an attribute that is expected to be
+// empty.
+//
+// CompareAndSwap is only supported by providers that implement the
+// IDirectoryObject interface, such as the LDAP provider. Any staged
+// modification of the attribute is discarded and its cached values are
+// reloaded. The swap is recorded as a reverse change.
+func (o *object) CompareAndSwap(name string, old, new []interface{}) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	if len(old) == 0 {
+		return fmt.Errorf("compare and swap of %s: no old values to compare", name)
+	}
+
+	iunknown, err := o.iface.QueryInterface(comutil.GUID(comiid.IDirectoryObject))
+	if err != nil {
+		return err
+	}
+	defer iunknown.Release()
+	dirobj := (*api.IDirectoryObject)(unsafe.Pointer(iunknown))
+
+	mods := []api.AttrModification{{Name: name, ControlCode: api.ADS_ATTR_DELETE, Values: old}}
+	if len(new) > 0 {
+		mods = append(mods, api.AttrModification{Name: name, ControlCode: api.ADS_ATTR_APPEND, Values: new})
+	}
+	e, err := o.writeEvent(WriteCompareAndSwap)
+	if err != nil {
+		return err
+	}
+	change := Change{Name: name, Old: normalizeValues(old), New: normalizeValues(new)}
+	e.Changes = []Change{change}
+	err = o.client.intercept(e, o.user, func() error {
+		_, err := dirobj.SetObjectAttributes(mods)
+		return err
+	})
+	if err != nil {
+		if api.IsHresult(err, api.E_DS_NO_ATTRIBUTE_OR_VALUE) || api.IsHresult(err, api.E_DS_ATT_VAL_ALREADY_EXISTS) {
+			return &ConflictError{Attr: name, Expected: old}
+		}
+		return err
+	}
+
+	if i := o.changeIndex(name); i >= 0 {
+		o.changes = append(o.changes[:i], o.changes[i+1:]...)
+	}
+	if err := o.revert(name); err != nil {
+		return err
+	}
+	reverse := modifyReverse(e.Path, []Change{change})
+	if len(reverse.Attrs) == 0 {
+		return nil
+	}
+	return o.record(reverse)
+}
@@ -0,0 +1,75 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const replMetaData = `<DS_REPL_ATTR_META_DATA>
+	<pszAttributeName>%s</pszAttributeName>
+	<dwVersion>%d</dwVersion>
+	<ftimeLastOriginatingChange>2026-10-01T08:30:00Z</ftimeLastOriginatingChange>
+	<uuidLastOriginatingDsaInvocationID>6f3f4b2a-1c2d-4e5f-8a9b-0c1d2e3f4a5b</uuidLastOriginatingDsaInvocationID>
+	<usnOriginatingChange>20481</usnOriginatingChange>
+	<usnLocalChange>20481</usnLocalChange>
+	<pszLastOriginatingDsaDN></pszLastOriginatingDsaDN>
+</DS_REPL_ATTR_META_DATA>
+`
+
+func TestParseReplAttributeMetaData(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		values []string
+		want   map[string]uint32
+	}{
+		{"none", nil, map[string]uint32{}},
+		{
+			"several",
+			[]string{
+				fmt.Sprintf(replMetaData, "description", 3),
+				fmt.Sprintf(replMetaData, "userAccountControl", 12),
+			},
+			map[string]uint32{"description": 3, "useraccountcontrol": 12},
+		},
+		{
+			"null terminated",
+			[]string{fmt.Sprintf(replMetaData, "pwdLastSet", 1) + "\x00"},
+			map[string]uint32{"pwdlastset": 1},
+		},
+	} {
+		got, err := parseReplAttributeMetaData(tt.values)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseReplAttributeMetaData() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{
+		"",
+		"<DS_REPL_ATTR_META_DATA><dwVersion>x</dwVersion></DS_REPL_ATTR_META_DATA>",
+		"<DS_REPL_ATTR_META_DATA>",
+	} {
+		if _, err := parseReplAttributeMetaData([]string{value}); err == nil {
+			t.Errorf("parseReplAttributeMetaData(%q) succeeded", value)
+		}
+	}
+}
+
+func TestConflictError(t *testing.T) {
+	err := error(&ConflictError{Attr: "description", Expected: []interface{}{"a"}})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("errors.Is(%v, ErrConflict) = false", err)
+	}
+	if errors.Is(err, ErrClosed) {
+		t.Errorf("errors.Is(%v, ErrClosed) = true", err)
+	}
+	err = &ConflictError{Attr: "uSNChanged", Expected: int64(1), Actual: int64(2)}
+	if got, want := err.Error(), ErrConflict.Error()+": uSNChanged is 2, expected 1"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -33,8 +33,71 @@ var (
 	// ErrNonVariantArrayAttribute is returned when the array members of a given
 	// attribute are not variants.
 	ErrNonVariantArrayAttribute = errors.New("attribute contains non-variant array members")
+
+	// ErrUnsupportedValue is returned when an attribute value cannot be
+	// converted to a type that can be written to the directory.
+	ErrUnsupportedValue = errors.New("unsupported attribute value type")
+
+	// ErrConflict is returned when a conditional write is rejected because the
+	// object was modified after it was read. The error returned in that case
+	// is a *ConflictError that matches ErrConflict with errors.Is.
+	ErrConflict = errors.New("object was modified after it was read")
+
+	// ErrNoClient is returned when an operation needs to open other objects
+	// but the object it was called on was not opened by a Client.
+	ErrNoClient = errors.New("object was not opened by a client")
+
+	// ErrPolicyViolation is returned when a write is denied by the write
+	// policy of a client. The error returned in that case is a *PolicyError
+	// that matches ErrPolicyViolation with errors.Is.
+	ErrPolicyViolation = errors.New("write denied by policy")
+
+	// ErrInvalidComputerName is returned when a computer account cannot be
+	// provisioned because its name is not a valid NetBIOS and DNS host name.
+	ErrInvalidComputerName = errors.New("invalid computer name")
+
+	// ErrAccountExists is returned when an account cannot be created because
+	// an object with the same name or sAMAccountName already exists.
+	ErrAccountExists = errors.New("account already exists")
+
+	// ErrDuplicateSPN is returned when a service principal name is already
+	// registered on another object in the forest. The error returned in that
+	// case is a *DuplicateSPNError that matches ErrDuplicateSPN with
+	// errors.Is.
+	ErrDuplicateSPN = errors.New("duplicate service principal name")
+
+	// ErrInvalidSID is returned when a value cannot be interpreted as a
+	// security identifier.
+	ErrInvalidSID = errors.New("invalid security identifier")
+
+	// ErrInvalidName is returned when a name cannot be interpreted in the
+	// name format that it is given in.
+	ErrInvalidName = errors.New("invalid name")
+
+	// ErrUnsupportedNameType is returned when a name cannot be converted
+	// between the requested name formats.
+	ErrUnsupportedNameType = errors.New("unsupported name type")
+
+	// ErrNameNotFound is returned when no object has the given name.
+	ErrNameNotFound = errors.New("name not found")
+
+	// ErrNameNotUnique is returned when more than one object has the given
+	// name.
+	ErrNameNotUnique = errors.New("name is not unique")
+
+	// ErrWellKnownObjectNotFound is returned when a domain does not list a
+	// well-known object with the requested GUID.
+	ErrWellKnownObjectNotFound = errors.New("well-known object not found")
+
+	// ErrUnsupportedExtension is returned when an LDAP URL has a critical
+	// extension that is not supported.
+	ErrUnsupportedExtension = errors.New("unsupported critical LDAP URL extension")
 )
 
 const (
 	defaultFlags = api.ADS_READONLY_SERVER | api.ADS_SECURE_AUTHENTICATION | api.ADS_USE_SEALING
+
+	// defaultBatchSize is the number of objects that an ObjectIter retrieves
+	// from the directory at a time unless configured otherwise.
+	defaultBatchSize = 32
 )
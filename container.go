@@ -2,6 +2,7 @@ package adsi
 
 import (
 	"io"
+	"iter"
 	"sync"
 	"unsafe"
 
@@ -14,8 +15,11 @@ import (
 
 // Container provides access to Active Directory container objects.
 type Container struct {
-	m     sync.RWMutex
-	iface *api.IADsContainer
+	m      sync.RWMutex
+	iface  *api.IADsContainer
+	client *Client
+	user   string
+	undo   []ReverseChange
 }
 
 // NewContainer returns a container that manages the given COM interface.
@@ -60,9 +64,32 @@ func (c *Container) Children() (iter *ObjectIter, err error) {
 	}
 	iface := (*ole.IEnumVARIANT)(unsafe.Pointer(idispatch))
 	iter = NewObjectIter(iface)
+	iter.client = c.client
+	iter.user = c.user
 	return
 }
 
+// All returns a sequence of the immediate children of the container. The
+// underlying iterator is closed when the sequence ends.
+//
+// If the children cannot be enumerated, the error is yielded with a nil
+// object and the sequence ends.
+//
+// Each yielded object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on each object when it is no longer
+// needed.
+func (c *Container) All() iter.Seq2[*Object, error] {
+	return func(yield func(*Object, error) bool) {
+		children, err := c.Children()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer children.Close()
+		children.All()(yield)
+	}
+}
+
 // Filter returns the current filter of the container.
 func (c *Container) Filter() (filter []string, err error) {
 	c.m.Lock()
@@ -114,6 +141,8 @@ func (c *Container) Object(class, name string) (obj *Object, err error) {
 	}
 	iface := (*api.IADs)(unsafe.Pointer(iresult))
 	obj = NewObject(iface)
+	obj.client = c.client
+	obj.user = c.user
 	return
 }
 
@@ -130,6 +159,8 @@ func (c *Container) ToObject() (o *Object, err error) {
 	}
 	iface := (*api.IADs)(unsafe.Pointer(idispatch))
 	o = NewObject(iface)
+	o.client = c.client
+	o.user = c.user
 	return
 }
 
@@ -155,28 +186,228 @@ func (c *Container) Container(class, name string) (container *Container, err err
 	}
 	iface := (*api.IADsContainer)(unsafe.Pointer(iresult))
 	container = NewContainer(iface)
+	container.client = c.client
+	container.user = c.user
+	return
+}
+
+// Path retrieves the fully qualified path of the container.
+func (c *Container) Path() (path string, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return "", ErrClosed
+	}
+	idispatch, err := c.iface.QueryInterface(comutil.GUID(comiid.IADs))
+	if err != nil {
+		return
+	}
+	defer idispatch.Release()
+	path, err = (*api.IADs)(unsafe.Pointer(idispatch)).AdsPath()
+	return
+}
+
+// Create returns a new object of the given class with the given relative name
+// in the container, such as "CN=Jane Doe" for the LDAP provider. The object
+// is not written to the underlying directory store until its attributes have
+// been put and SetInfo is called on it.
+//
+// Once created, the object records a reverse change that deletes it.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+func (c *Container) Create(class, name string) (obj *Object, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return nil, ErrClosed
+	}
+	idispatch, err := c.iface.Create(class, name)
+	if err != nil {
+		return
+	}
+	defer idispatch.Release()
+	iresult, err := idispatch.QueryInterface(comutil.GUID(comiid.IADs))
+	if err != nil {
+		return
+	}
+	iface := (*api.IADs)(unsafe.Pointer(iresult))
+	obj = NewObject(iface)
+	obj.client = c.client
+	obj.user = c.user
+	obj.created = true
+	return
+}
+
+// Delete deletes the descendant object with the given class and relative
+// name from the underlying directory store. The LDAP provider only deletes
+// objects that have no children; use Object.Delete to delete a subtree.
+//
+// If a class is not provided then the first item matching the relative name
+// will be deleted regardless of its class.
+//
+// The attributes of the object are recorded as a reverse change that
+// recreates it, which can be retrieved with Undo. They are read only once
+// the write policy and interceptors allow the deletion. See Object.Delete
+// for the limitations of recreating an object.
+func (c *Container) Delete(class, name string) error {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return ErrClosed
+	}
+	idispatch, err := c.iface.GetObject(class, name)
+	if err != nil {
+		return err
+	}
+	iresult, err := idispatch.QueryInterface(comutil.GUID(comiid.IADs))
+	idispatch.Release()
+	if err != nil {
+		return err
+	}
+	obj := NewObject((*api.IADs)(unsafe.Pointer(iresult)))
+	defer obj.Close()
+	path, err := obj.iface.AdsPath()
+	if err != nil {
+		return err
+	}
+	objClass, err := obj.iface.Class()
+	if err != nil {
+		return err
+	}
+
+	var reverse []ReverseChange
+	e := WriteEvent{Op: WriteDelete, Path: path, Class: objClass}
+	err = c.client.intercept(e, c.user, func() error {
+		reverse = obj.deleteImage()
+		return c.iface.Delete(class, name)
+	})
+	if err != nil {
+		return err
+	}
+	c.undo = append(copyReverseChanges(reverse), c.undo...)
+	return c.client.recordUndo(reverse)
+}
+
+// MoveHere moves the object with the given ADsPath into the container and
+// returns the object at its new location. If a relative name is provided the
+// object is renamed as well.
+//
+// MoveHere requires a container that was opened by a Client. The move is
+// recorded as a reverse change on the returned object.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+func (c *Container) MoveHere(source, name string) (obj *Object, err error) {
+	if c.client == nil {
+		return nil, ErrNoClient
+	}
+	src, err := c.client.OpenInterface(source, comiid.IADs)
+	if err != nil {
+		return nil, err
+	}
+	o := NewObject((*api.IADs)(unsafe.Pointer(src)))
+	o.client = c.client
+	o.user = c.user
+	defer o.Close()
+	return o.MoveTo(c, name)
+}
+
+// moveHere moves the object with the given ADsPath into the container without
+// recording a reverse change.
+func (c *Container) moveHere(source, name string) (obj *Object, err error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if c.closed() {
+		return nil, ErrClosed
+	}
+	idispatch, err := c.iface.MoveHere(source, name)
+	if err != nil {
+		return
+	}
+	defer idispatch.Release()
+	iresult, err := idispatch.QueryInterface(comutil.GUID(comiid.IADs))
+	if err != nil {
+		return
+	}
+	iface := (*api.IADs)(unsafe.Pointer(iresult))
+	obj = NewObject(iface)
+	obj.client = c.client
+	obj.user = c.user
 	return
 }
 
+// Undo returns the reverse changes that recreate the objects deleted with
+// Delete, in the order in which they must be replayed.
+func (c *Container) Undo() []ReverseChange {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	return copyReverseChanges(c.undo)
+}
+
 // ObjectIter provides an iterator for a set of objects.
+//
+// Objects are retrieved from the underlying enumerator in batches, which
+// avoids a round-trip to the directory for each object. The batch size can be
+// adjusted with SetBatchSize.
 type ObjectIter struct {
-	m     sync.RWMutex
-	iface *ole.IEnumVARIANT
+	m      sync.RWMutex
+	iface  *ole.IEnumVARIANT
+	client *Client
+	user   string
+	batch  int
+	buf    []iterItem
+	eof    bool
+	err    error
+}
+
+// iterItem is a prefetched object or the error encountered while preparing
+// it.
+type iterItem struct {
+	obj *Object
+	err error
 }
 
 // NewObjectIter returns an object iterator that provides access to the objects
 // contained in the given enumerator.
 func NewObjectIter(enumerator *ole.IEnumVARIANT) *ObjectIter {
 	comshim.Add(1)
-	return &ObjectIter{iface: enumerator}
+	return &ObjectIter{iface: enumerator, batch: defaultBatchSize}
+}
+
+// BatchSize returns the maximum number of objects that the iterator retrieves
+// from the directory at a time.
+func (iter *ObjectIter) BatchSize() int {
+	iter.m.RLock()
+	defer iter.m.RUnlock()
+	return iter.batch
+}
+
+// SetBatchSize sets the maximum number of objects that the iterator retrieves
+// from the directory at a time. Values less than one are treated as one.
+//
+// Objects that have already been prefetched are not affected.
+func (iter *ObjectIter) SetBatchSize(size int) {
+	iter.m.Lock()
+	defer iter.m.Unlock()
+	if size < 1 {
+		size = 1
+	}
+	iter.batch = size
 }
 
 // Next moves the iterator to the next object and returns a pointer to it. If it
 // has reached the end of the set it will return io.EOF. It the iterator has
 // already been closed it will return ErrClosed.
 //
-// FIXME: Make sure that io.EOF is being returned as expected. We might have
-// to intercept an internal error.
+// Any other error indicates that the enumeration failed. The error is also
+// retained and can be retrieved later with Err.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
 func (iter *ObjectIter) Next() (obj *Object, err error) {
 	iter.m.Lock()
 	defer iter.m.Unlock()
@@ -184,30 +415,105 @@ func (iter *ObjectIter) Next() (obj *Object, err error) {
 		return nil, ErrClosed
 	}
 
+	if len(iter.buf) == 0 {
+		if iter.eof {
+			return nil, io.EOF
+		}
+		if err = iter.fetch(); err != nil {
+			iter.setErr(err)
+			return nil, err
+		}
+		if len(iter.buf) == 0 {
+			return nil, io.EOF
+		}
+	}
+
+	item := iter.buf[0]
+	iter.buf[0] = iterItem{}
+	iter.buf = iter.buf[1:]
+	if item.err != nil {
+		iter.setErr(item.err)
+	}
+	return item.obj, item.err
+}
+
+// fetch retrieves the next batch of objects from the enumerator and adds them
+// to the buffer. The caller must hold a lock on the iterator.
+func (iter *ObjectIter) fetch() error {
 	// See https://msdn.microsoft.com/library/aa705990
-	array, length, err := iter.iface.Next(1)
+	variants := make([]ole.VARIANT, iter.batch)
+	n, err := api.EnumVariantNext(iter.iface, variants)
 	if err != nil {
-		return
+		return err
 	}
-	defer array.Clear()
-	if length == 0 {
-		return nil, io.EOF
+	if n < len(variants) {
+		iter.eof = true
 	}
 
-	idispatch := array.ToIDispatch()
-	if idispatch == nil {
-		return nil, ErrNonDispatchVariant
+	for i := 0; i < n; i++ {
+		item := variantToObject(&variants[i])
+		if item.obj != nil {
+			item.obj.client = iter.client
+			item.obj.user = iter.user
+		}
+		iter.buf = append(iter.buf, item)
+		variants[i].Clear()
 	}
-	// Note: Do *not* call idispatch.Release() here, as it will be called
-	//       automatically by array.Clear()
+	return nil
+}
 
+// variantToObject acquires an IADs interface for the dispatch interface held
+// by the variant. The variant retains its own reference and must still be
+// cleared by the caller.
+func variantToObject(variant *ole.VARIANT) iterItem {
+	idispatch := variant.ToIDispatch()
+	if idispatch == nil {
+		return iterItem{err: ErrNonDispatchVariant}
+	}
 	iresult, err := idispatch.QueryInterface(comutil.GUID(comiid.IADs))
 	if err != nil {
-		return
+		return iterItem{err: err}
 	}
 	iface := (*api.IADs)(unsafe.Pointer(iresult))
-	obj = NewObject(iface)
-	return
+	return iterItem{obj: NewObject(iface)}
+}
+
+// setErr records the first enumeration error encountered by the iterator.
+// The caller must hold a lock on the iterator.
+func (iter *ObjectIter) setErr(err error) {
+	if iter.err == nil {
+		iter.err = err
+	}
+}
+
+// Err returns the first error other than io.EOF that was encountered by the
+// iterator, if any.
+func (iter *ObjectIter) Err() error {
+	iter.m.RLock()
+	defer iter.m.RUnlock()
+	return iter.err
+}
+
+// All returns a sequence of the remaining objects in the iterator.
+//
+// If the iterator encounters an error, it yields the error with a nil object
+// and the sequence ends. The error can also be retrieved later with Err.
+//
+// Each yielded object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on each object when it is no longer
+// needed. The iterator itself is not closed when the sequence ends.
+func (iter *ObjectIter) All() iter.Seq2[*Object, error] {
+	return func(yield func(*Object, error) bool) {
+		for {
+			obj, err := iter.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(obj, err) || err != nil {
+				return
+			}
+		}
+	}
 }
 
 func (iter *ObjectIter) closed() bool {
@@ -216,6 +522,8 @@ func (iter *ObjectIter) closed() bool {
 
 // Close will release resources consumed by the iterator. It should be
 // called when the iterator is no longer needed.
+//
+// Any prefetched objects that have not been returned by Next are released.
 func (iter *ObjectIter) Close() {
 	iter.m.Lock()
 	defer iter.m.Unlock()
@@ -223,6 +531,12 @@ func (iter *ObjectIter) Close() {
 		return
 	}
 	defer comshim.Done()
+	for _, item := range iter.buf {
+		if item.obj != nil {
+			item.obj.Close()
+		}
+	}
+	iter.buf = nil
 	iter.iface.Release() // FIXME: What happens if release returns an error?
 	iter.iface = nil
 }
@@ -0,0 +1,308 @@
+package adsi
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/scjalliance/comshim"
+	"github.com/scjalliance/comutil"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+)
+
+// Flags of the pwdProperties attribute of an LDAP domain.
+const (
+	domainPasswordComplex        = 0x1
+	domainPasswordStoreCleartext = 0x10
+)
+
+// Flags of the PasswordAttributes property of a WinNT domain.
+const (
+	passwordAttrMixedCase = 0x1
+	passwordAttrComplex   = 0x2
+)
+
+// PasswordPolicy describes the passwords that are accepted for accounts.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters in a password.
+	MinLength int
+
+	// HistoryLength is the number of previous passwords of an account that
+	// cannot be reused.
+	HistoryLength int
+
+	// MinAge is the time that must pass before a password can be changed.
+	MinAge time.Duration
+
+	// MaxAge is the time after which a password expires. It is zero if
+	// passwords never expire.
+	MaxAge time.Duration
+
+	// ComplexityRequired is true if passwords must meet complexity
+	// requirements.
+	ComplexityRequired bool
+
+	// ReversibleEncryption is true if passwords are stored with reversible
+	// encryption.
+	ReversibleEncryption bool
+}
+
+// LockoutPolicy describes when accounts are locked out after failed logon
+// attempts.
+type LockoutPolicy struct {
+	// Threshold is the number of failed logon attempts after which an account
+	// is locked out. It is zero if accounts are never locked out.
+	Threshold int
+
+	// Duration is the time after which a locked out account is unlocked
+	// automatically. It is zero if accounts remain locked out until an
+	// administrator unlocks them.
+	Duration time.Duration
+
+	// ObservationWindow is the time after which the count of failed logon
+	// attempts of an account is reset.
+	ObservationWindow time.Duration
+}
+
+// Domain provides access to the password and lockout policy of a domain.
+//
+// Domains of the WinNT provider are accessed through the IADsDomain
+// interface. Domains of the LDAP provider, which does not implement
+// IADsDomain, are accessed through the attributes of their domainDNS object.
+type Domain struct {
+	object
+	iface *api.IADsDomain
+}
+
+// NewDomain returns a domain that manages the given COM interface.
+func NewDomain(iface *api.IADsDomain) *Domain {
+	comshim.Add(1)
+	return &Domain{iface: iface, object: object{iface: &iface.IADs}}
+}
+
+// newDirectoryDomain returns a domain that manages the given IADs interface
+// of a domainDNS object.
+func newDirectoryDomain(iface *api.IADs) *Domain {
+	comshim.Add(1)
+	return &Domain{object: object{iface: iface}}
+}
+
+func (d *Domain) closed() bool {
+	return (d.object.iface == nil)
+}
+
+// Close will release resources consumed by the domain. It should be called
+// when the domain is no longer needed.
+func (d *Domain) Close() {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if d.closed() {
+		return
+	}
+	defer comshim.Done()
+	d.object.iface.Release()
+	d.object.iface = nil
+	d.iface = nil
+}
+
+// IsWorkgroup reports whether the domain is a workgroup. It is always false
+// for domains of the LDAP provider.
+func (d *Domain) IsWorkgroup() (workgroup bool, err error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if d.closed() {
+		return false, ErrClosed
+	}
+	if d.iface == nil {
+		return false, nil
+	}
+	return d.iface.IsWorkgroup()
+}
+
+// PasswordPolicy returns the password policy of the domain.
+//
+// The policy applies to accounts that are not subject to a fine-grained
+// password policy.
+func (d *Domain) PasswordPolicy() (p PasswordPolicy, err error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if d.closed() {
+		return p, ErrClosed
+	}
+
+	if d.iface == nil {
+		values, err := d.int64Attrs("minPwdLength", "pwdHistoryLength", "minPwdAge", "maxPwdAge", "pwdProperties")
+		if err != nil {
+			return p, err
+		}
+		p.MinLength = int(values[0])
+		p.HistoryLength = int(values[1])
+		p.MinAge = directoryInterval(values[2])
+		p.MaxAge = directoryInterval(values[3])
+		p.ComplexityRequired = values[4]&domainPasswordComplex != 0
+		p.ReversibleEncryption = values[4]&domainPasswordStoreCleartext != 0
+		return p, nil
+	}
+
+	values, err := d.int32Props(d.iface.MinPasswordLength, d.iface.PasswordHistoryLength, d.iface.MinPasswordAge, d.iface.MaxPasswordAge, d.iface.PasswordAttributes)
+	if err != nil {
+		return p, err
+	}
+	p.MinLength = int(values[0])
+	p.HistoryLength = int(values[1])
+	p.MinAge = secondsInterval(values[2])
+	p.MaxAge = secondsInterval(values[3])
+	p.ComplexityRequired = values[4]&(passwordAttrMixedCase|passwordAttrComplex) != 0
+	return p, nil
+}
+
+// LockoutPolicy returns the account lockout policy of the domain.
+//
+// The policy applies to accounts that are not subject to a fine-grained
+// password policy.
+func (d *Domain) LockoutPolicy() (p LockoutPolicy, err error) {
+	d.m.Lock()
+	defer d.m.Unlock()
+	if d.closed() {
+		return p, ErrClosed
+	}
+
+	if d.iface == nil {
+		values, err := d.int64Attrs("lockoutThreshold", "lockoutDuration", "lockOutObservationWindow")
+		if err != nil {
+			return p, err
+		}
+		p.Threshold = int(values[0])
+		p.Duration = directoryInterval(values[1])
+		p.ObservationWindow = directoryInterval(values[2])
+		return p, nil
+	}
+
+	values, err := d.int32Props(d.iface.MaxBadPasswordsAllowed, d.iface.AutoUnlockInterval, d.iface.LockoutObservationInterval)
+	if err != nil {
+		return p, err
+	}
+	p.Threshold = int(values[0])
+	p.Duration = secondsInterval(values[1])
+	p.ObservationWindow = secondsInterval(values[2])
+	return p, nil
+}
+
+// int64Attrs reads the given integer attributes from the directory. Attributes
+// that are not populated are returned as zero. The caller must hold a lock on
+// the domain.
+func (d *Domain) int64Attrs(names ...string) ([]int64, error) {
+	if err := d.Pull(names...); err != nil {
+		return nil, err
+	}
+	values := make([]int64, len(names))
+	for i, name := range names {
+		v, err := optional(d.AttrInt64(name))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// int32Props calls each of the given property getters. The caller must hold
+// a lock on the domain.
+func (d *Domain) int32Props(getters ...func() (int32, error)) ([]int32, error) {
+	values := make([]int32, len(getters))
+	for i, get := range getters {
+		v, err := get()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// directoryInterval converts a time interval stored in the directory, which
+// is expressed as a negative number of 100-nanosecond intervals, into a
+// duration. The largest negative value, which means forever, and zero are
+// converted to zero.
+func directoryInterval(v int64) time.Duration {
+	if v == math.MinInt64 || v == 0 {
+		return 0
+	}
+	if v < 0 {
+		v = -v
+	}
+	return time.Duration(v) * 100
+}
+
+// secondsInterval converts a time interval expressed in seconds by the WinNT
+// provider into a duration. Negative values, which mean forever, are
+// converted to zero.
+func secondsInterval(v int32) time.Duration {
+	if v < 0 {
+		return 0
+	}
+	return time.Duration(v) * time.Second
+}
+
+// ToDomain attempts to acquire a domain interface for the object. The object
+// must be a WinNT domain or an LDAP domainDNS object.
+func (o *object) ToDomain() (d *Domain, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	idispatch, err := o.iface.QueryInterface(comutil.GUID(comiid.IADsDomain))
+	if err == nil {
+		d = NewDomain((*api.IADsDomain)(unsafe.Pointer(idispatch)))
+	} else {
+		class, classErr := o.iface.Class()
+		if classErr != nil {
+			return nil, classErr
+		}
+		if !strings.EqualFold(class, "domainDNS") {
+			return nil, err
+		}
+		o.iface.AddRef()
+		d = newDirectoryDomain(o.iface)
+		err = nil
+	}
+	d.client = o.client
+	d.user = o.user
+	return
+}
+
+// OpenDomain opens the domain with the given path, which may be a WinNT path
+// such as "WinNT://EXAMPLE" or the LDAP path of a domainDNS object such as
+// "LDAP://DC=example,DC=com". The existing security context of the
+// application and any flags specified via SetFlags will be used when making
+// the connection.
+//
+// The returned domain consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned domain when it is no
+// longer needed.
+func (c *Client) OpenDomain(path string) (domain *Domain, err error) {
+	return c.OpenDomainSC(path, "", "", c.Flags())
+}
+
+// OpenDomainSC opens the domain with the given path. When provided, the
+// username and password are used to establish a security context for the
+// connection. When credentials are not provided the existing security
+// context of the application is used instead. The provided flags will be used
+// when making the connection.
+//
+// The returned domain consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned domain when it is no
+// longer needed.
+func (c *Client) OpenDomainSC(path, user, password string, flags uint32) (domain *Domain, err error) {
+	obj, err := c.OpenSC(path, user, password, flags)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	return obj.ToDomain()
+}
@@ -0,0 +1,44 @@
+package adsi
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDirectoryInterval(t *testing.T) {
+	for _, tt := range []struct {
+		v    int64
+		want time.Duration
+	}{
+		{0, 0},
+		{math.MinInt64, 0},
+		{-36288000000000, 42 * 24 * time.Hour},
+		{-864000000000, 24 * time.Hour},
+		{-18000000000, 30 * time.Minute},
+		{-1, 100 * time.Nanosecond},
+		{18000000000, 30 * time.Minute},
+	} {
+		if got := directoryInterval(tt.v); got != tt.want {
+			t.Errorf("directoryInterval(%d) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestSecondsInterval(t *testing.T) {
+	for _, tt := range []struct {
+		v    int32
+		want time.Duration
+	}{
+		{0, 0},
+		{-1, 0},
+		{math.MinInt32, 0},
+		{1800, 30 * time.Minute},
+		{3628800, 42 * 24 * time.Hour},
+		{math.MaxInt32, math.MaxInt32 * time.Second},
+	} {
+		if got := secondsInterval(tt.v); got != tt.want {
+			t.Errorf("secondsInterval(%d) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,462 @@
+package adsi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/scjalliance/comutil"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+	"github.com/go-adsi/adsi/spn"
+)
+
+// Defaults of group managed service accounts.
+const (
+	// DefaultManagedPasswordInterval is the number of days after which the
+	// password of a group managed service account is changed unless
+	// configured otherwise.
+	DefaultManagedPasswordInterval = 30
+
+	// retrieverAccess is the access granted to the principals that may
+	// retrieve the password of a group managed service account.
+	retrieverAccess = 0xF01FF
+)
+
+// builtinAdministrators is the security identifier of the BUILTIN
+// Administrators group, which owns the msDS-GroupMSAMembership security
+// descriptor.
+var builtinAdministrators = []byte{1, 2, 0, 0, 0, 0, 0, 5, 32, 0, 0, 0, 32, 2, 0, 0}
+
+// ManagedServiceAccount describes a group managed service account that is
+// created by Container.CreateGMSA.
+type ManagedServiceAccount struct {
+	// Name is the name of the account, without the trailing dollar sign of
+	// its sAMAccountName. It must be at most 15 characters long.
+	Name string
+
+	// DNSHostName is the DNS name of the service, such as
+	// "web.example.com".
+	DNSHostName string
+
+	// Retrievers holds the ADsPaths of the computers, or of the groups of
+	// computers, that are permitted to retrieve the password of the account.
+	// It populates PrincipalsAllowedToRetrieveManagedPassword.
+	Retrievers []string
+
+	// PasswordInterval is the number of days after which the password is
+	// changed. If it is zero, DefaultManagedPasswordInterval is used. It
+	// cannot be changed once the account has been created.
+	PasswordInterval int
+
+	// EncryptionTypes holds the Kerberos encryption types that the account
+	// supports. If it is zero, RC4HMAC, AES128 and AES256 are supported.
+	EncryptionTypes EncryptionTypes
+
+	// ServicePrincipalNames holds the service principal names of the
+	// account.
+	ServicePrincipalNames []spn.SPN
+
+	// Description is written to the description attribute of the account,
+	// if it is not empty.
+	Description string
+}
+
+// Retriever is a principal that is permitted to retrieve the password of a
+// group managed service account.
+type Retriever struct {
+	// SID is the security identifier of the principal in string form.
+	SID string
+
+	// Path is the ADsPath of the principal. It is empty if the security
+	// identifier could not be resolved.
+	Path string
+}
+
+// ManagedPassword holds the passwords of a group managed service account, as
+// decoded from an MSDS-MANAGEDPASSWORD_BLOB structure.
+type ManagedPassword struct {
+	// Current is the current password, encoded as UTF-16LE without a
+	// terminator. Managed passwords are random and need not be valid UTF-16.
+	Current []byte
+
+	// Previous is the previous password in the same encoding as Current. It
+	// is nil if the password has not been changed yet.
+	Previous []byte
+
+	// QueryInterval is the time after which the password must be retrieved
+	// again, as the next password will then be current.
+	QueryInterval time.Duration
+
+	// UnchangedInterval is the time during which the password will not
+	// change.
+	UnchangedInterval time.Duration
+
+	// NextQuery is the time at which the password must be retrieved again.
+	NextQuery time.Time
+
+	// UnchangedUntil is the time until which the password will not change.
+	UnchangedUntil time.Time
+}
+
+// CreateGMSA creates a group managed service account in the container and
+// permits the given retrievers to retrieve its password. The account is
+// written to the underlying directory store immediately.
+//
+// Creating the account is recorded as a reverse change that deletes it.
+// Permitting the retrievers requires a container that was opened by a Client.
+// The retrievers are resolved before the account is created, and the account
+// is deleted again if their permission cannot be written.
+//
+// The returned object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on the returned object when it is no
+// longer needed.
+//
+// CreateGMSA is only supported by the LDAP provider.
+func (c *Container) CreateGMSA(acct ManagedServiceAccount) (*Object, error) {
+	name := strings.TrimSuffix(acct.Name, "$")
+	switch {
+	case name == "":
+		return nil, errors.New("group managed service account name is empty")
+	case len(name) > maxComputerNameLength:
+		return nil, fmt.Errorf("group managed service account name %q is longer than %d characters", name, maxComputerNameLength)
+	case acct.DNSHostName == "":
+		return nil, fmt.Errorf("group managed service account %q has no DNS host name", name)
+	}
+	// The retrievers are resolved before the account is created, so that an
+	// unknown retriever does not leave behind an account that nobody can use.
+	var retrievers [][]byte
+	if len(acct.Retrievers) > 0 {
+		if c.client == nil {
+			return nil, ErrNoClient
+		}
+		var err error
+		if retrievers, err = c.client.retrieverSIDs(acct.Retrievers); err != nil {
+			return nil, err
+		}
+	}
+	interval := acct.PasswordInterval
+	if interval == 0 {
+		interval = DefaultManagedPasswordInterval
+	}
+	types := acct.EncryptionTypes
+	if types == 0 {
+		types = RC4HMAC | AES128 | AES256
+	}
+
+	obj, err := c.Create("msDS-GroupManagedServiceAccount", "CN="+name)
+	if err != nil {
+		return nil, err
+	}
+	puts := []func() error{
+		func() error { return obj.PutString("sAMAccountName", name+"$") },
+		func() error { return obj.PutString("dNSHostName", acct.DNSHostName) },
+		func() error { return obj.PutInt("msDS-ManagedPasswordInterval", interval) },
+		func() error { return obj.PutInt("msDS-SupportedEncryptionTypes", int(types)) },
+		func() error { return obj.PutInt("userAccountControl", api.ADS_UF_WORKSTATION_TRUST_ACCOUNT) },
+	}
+	if len(acct.ServicePrincipalNames) > 0 {
+		spns := make([]interface{}, len(acct.ServicePrincipalNames))
+		for i, s := range acct.ServicePrincipalNames {
+			spns[i] = s.String()
+		}
+		puts = append(puts, func() error { return obj.PutEx("servicePrincipalName", api.ADS_PROPERTY_UPDATE, spns...) })
+	}
+	if acct.Description != "" {
+		puts = append(puts, func() error { return obj.PutString("description", acct.Description) })
+	}
+	for _, put := range puts {
+		if err := put(); err != nil {
+			obj.Close()
+			return nil, err
+		}
+	}
+	if err := obj.SetInfo(); err != nil {
+		obj.Close()
+		if accountExists(err) {
+			return nil, fmt.Errorf("%w: %s$: %v", ErrAccountExists, name, err)
+		}
+		return nil, err
+	}
+
+	if len(retrievers) > 0 {
+		obj.m.Lock()
+		err := obj.writeRetrievers(retrievers)
+		obj.m.Unlock()
+		if err != nil {
+			// Remove the account rather than leave one whose password no
+			// retriever may read.
+			if derr := obj.Delete(); derr != nil {
+				err = fmt.Errorf("%w (deleting the account failed: %v)", err, derr)
+			}
+			obj.Close()
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+// PasswordRetrievers returns the principals that are permitted to retrieve
+// the password of the group managed service account, as granted by its
+// msDS-GroupMSAMembership security descriptor. The principals are resolved
+// to ADsPaths if the object was opened by a Client.
+//
+// PasswordRetrievers is only supported by the LDAP provider.
+func (o *object) PasswordRetrievers() ([]Retriever, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	iunknown, err := o.iface.QueryInterface(comutil.GUID(comiid.IDirectoryObject))
+	if err != nil {
+		return nil, err
+	}
+	dirobj := (*api.IDirectoryObject)(unsafe.Pointer(iunknown))
+	defer dirobj.Release()
+
+	attrs, err := dirobj.GetObjectAttributes([]string{"msDS-GroupMSAMembership"})
+	if err != nil {
+		return nil, err
+	}
+	var sd []byte
+	for _, attr := range attrs {
+		for _, value := range attr.Values {
+			switch v := value.(type) {
+			case api.SecurityDescriptor:
+				sd = v
+			case []byte:
+				sd = v
+			}
+		}
+	}
+	if sd == nil {
+		return nil, nil
+	}
+	sids, err := allowedSIDs(sd, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := o.iface.AdsPath()
+	if err != nil {
+		return nil, err
+	}
+	retrievers := make([]Retriever, 0, len(sids))
+	for _, sid := range sids {
+		s, err := FormatSID(sid)
+		if err != nil {
+			return nil, err
+		}
+		r := Retriever{SID: s}
+		if o.client != nil {
+			r.Path, _ = o.client.pathBySID(base, s)
+		}
+		retrievers = append(retrievers, r)
+	}
+	return retrievers, nil
+}
+
+// SetPasswordRetrievers replaces the principals that are permitted to
+// retrieve the password of the group managed service account with the
+// computers or groups with the given ADsPaths. The msDS-GroupMSAMembership
+// security descriptor is written to the underlying directory store
+// immediately.
+//
+// The modification is reported to the write interceptor of the client. It is
+// not recorded as a reverse change. SetPasswordRetrievers requires an object
+// that was opened by a Client.
+//
+// SetPasswordRetrievers is only supported by the LDAP provider.
+func (o *object) SetPasswordRetrievers(paths ...string) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	if o.client == nil {
+		return ErrNoClient
+	}
+	retrievers, err := o.client.retrieverSIDs(paths)
+	if err != nil {
+		return err
+	}
+	return o.writeRetrievers(retrievers)
+}
+
+// retrieverSIDs returns the objectSid of each of the objects with the given
+// ADsPaths.
+func (c *Client) retrieverSIDs(paths []string) ([][]byte, error) {
+	sids := make([][]byte, len(paths))
+	for i, path := range paths {
+		sid, err := c.objectSid(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if _, err := FormatSID(sid); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		sids[i] = sid
+	}
+	return sids, nil
+}
+
+// writeRetrievers writes the msDS-GroupMSAMembership security descriptor that
+// permits the principals with the given security identifiers to retrieve the
+// password of the account. The caller must hold a lock on the object.
+func (o *object) writeRetrievers(retrievers [][]byte) error {
+	aces := make([][]byte, len(retrievers))
+	sids := make([]interface{}, len(retrievers))
+	for i, sid := range retrievers {
+		aces[i] = allowedACE(retrieverAccess, sid)
+		sids[i], _ = FormatSID(sid)
+	}
+	sd, err := newSecurityDescriptor(0, builtinAdministrators, aclRevision, aces)
+	if err != nil {
+		return err
+	}
+
+	iunknown, err := o.iface.QueryInterface(comutil.GUID(comiid.IDirectoryObject))
+	if err != nil {
+		return err
+	}
+	dirobj := (*api.IDirectoryObject)(unsafe.Pointer(iunknown))
+	defer dirobj.Release()
+
+	e, err := o.writeEvent(WriteSetSecurity)
+	if err != nil {
+		return err
+	}
+	e.Changes = []Change{{Name: "msDS-GroupMSAMembership", New: sids}}
+	return o.client.intercept(e, o.user, func() error {
+		_, err := dirobj.SetObjectAttributes([]api.AttrModification{{
+			Name:        "msDS-GroupMSAMembership",
+			ControlCode: api.ADS_ATTR_UPDATE,
+			Values:      []interface{}{api.SecurityDescriptor(sd)},
+		}})
+		return err
+	})
+}
+
+// ManagedPassword retrieves and decodes the msDS-ManagedPassword attribute of
+// the group managed service account. Only the principals returned by
+// PasswordRetrievers may retrieve it, over a connection that is signed and
+// sealed.
+//
+// ManagedPassword is only supported by the LDAP provider.
+func (o *object) ManagedPassword() (*ManagedPassword, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	if err := o.Pull("msDS-ManagedPassword"); err != nil {
+		return nil, err
+	}
+	blob, err := o.AttrBytes("msDS-ManagedPassword")
+	if err != nil {
+		return nil, err
+	}
+	return DecodeManagedPassword(blob, time.Now())
+}
+
+// pathBySID returns the ADsPath of the object with the given security
+// identifier, using the scheme and server of the given ADsPath.
+func (c *Client) pathBySID(base, sid string) (string, error) {
+	path, err := pathFromDN(base, "<SID="+sid+">")
+	if err != nil {
+		return "", err
+	}
+	obj, err := c.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer obj.Close()
+
+	obj.m.Lock()
+	defer obj.m.Unlock()
+	if obj.closed() {
+		return "", ErrClosed
+	}
+	if err := obj.Pull("distinguishedName"); err != nil {
+		return "", err
+	}
+	dn, err := obj.AttrString("distinguishedName")
+	if err != nil {
+		return "", err
+	}
+	return pathFromDN(base, dn)
+}
+
+// DecodeManagedPassword decodes an MSDS-MANAGEDPASSWORD_BLOB structure, as
+// stored in the msDS-ManagedPassword attribute. The intervals of the blob are
+// relative to the time at which it was retrieved, from which NextQuery and
+// UnchangedUntil are computed.
+func DecodeManagedPassword(blob []byte, retrieved time.Time) (*ManagedPassword, error) {
+	if len(blob) < 16 {
+		return nil, errors.New("managed password blob is too short")
+	}
+	if version := binary.LittleEndian.Uint16(blob); version != 1 {
+		return nil, fmt.Errorf("managed password blob has unsupported version %d", version)
+	}
+	if length := binary.LittleEndian.Uint32(blob[4:]); int64(length) > int64(len(blob)) {
+		return nil, fmt.Errorf("managed password blob is truncated to %d of %d bytes", len(blob), length)
+	}
+	var (
+		currentOffset   = int(binary.LittleEndian.Uint16(blob[8:]))
+		previousOffset  = int(binary.LittleEndian.Uint16(blob[10:]))
+		queryOffset     = int(binary.LittleEndian.Uint16(blob[12:]))
+		unchangedOffset = int(binary.LittleEndian.Uint16(blob[14:]))
+	)
+
+	p := new(ManagedPassword)
+	var err error
+	if p.Current, err = blobPassword(blob, currentOffset); err != nil {
+		return nil, fmt.Errorf("current password: %w", err)
+	}
+	if previousOffset != 0 {
+		if p.Previous, err = blobPassword(blob, previousOffset); err != nil {
+			return nil, fmt.Errorf("previous password: %w", err)
+		}
+	}
+	if p.QueryInterval, err = blobInterval(blob, queryOffset); err != nil {
+		return nil, fmt.Errorf("query password interval: %w", err)
+	}
+	if p.UnchangedInterval, err = blobInterval(blob, unchangedOffset); err != nil {
+		return nil, fmt.Errorf("unchanged password interval: %w", err)
+	}
+	p.NextQuery = retrieved.Add(p.QueryInterval)
+	p.UnchangedUntil = retrieved.Add(p.UnchangedInterval)
+	return p, nil
+}
+
+// blobPassword returns the null-terminated UTF-16LE password at the given
+// offset of a managed password blob, without its terminator.
+func blobPassword(blob []byte, offset int) ([]byte, error) {
+	if offset < 16 || offset >= len(blob) {
+		return nil, fmt.Errorf("offset %d is out of bounds", offset)
+	}
+	for i := offset; i+1 < len(blob); i += 2 {
+		if blob[i] == 0 && blob[i+1] == 0 {
+			return append([]byte(nil), blob[offset:i]...), nil
+		}
+	}
+	return nil, errors.New("password is not terminated")
+}
+
+// blobInterval returns the interval at the given offset of a managed password
+// blob, which is stored in 100-nanosecond units.
+func blobInterval(blob []byte, offset int) (time.Duration, error) {
+	if offset < 16 || offset+8 > len(blob) {
+		return 0, fmt.Errorf("offset %d is out of bounds", offset)
+	}
+	v := binary.LittleEndian.Uint64(blob[offset:])
+	if v > uint64(1<<63-1)/100 {
+		return time.Duration(1<<63 - 1), nil
+	}
+	return time.Duration(v) * 100, nil
+}
@@ -0,0 +1,136 @@
+package adsi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// managedPasswordBlob builds an MSDS-MANAGEDPASSWORD_BLOB holding the given
+// passwords and intervals. The previous password is omitted if it is empty.
+func managedPasswordBlob(current, previous string, query, unchanged time.Duration) []byte {
+	password := func(s string) []byte {
+		var b bytes.Buffer
+		for _, c := range utf16.Encode([]rune(s)) {
+			binary.Write(&b, binary.LittleEndian, c)
+		}
+		b.Write([]byte{0, 0})
+		return b.Bytes()
+	}
+	var body bytes.Buffer
+	offset := func() uint16 { return uint16(16 + body.Len()) }
+
+	currentOffset := offset()
+	body.Write(password(current))
+	var previousOffset uint16
+	if previous != "" {
+		previousOffset = offset()
+		body.Write(password(previous))
+	}
+	queryOffset := offset()
+	binary.Write(&body, binary.LittleEndian, uint64(query/100))
+	unchangedOffset := offset()
+	binary.Write(&body, binary.LittleEndian, uint64(unchanged/100))
+
+	var blob bytes.Buffer
+	binary.Write(&blob, binary.LittleEndian, uint16(1))
+	binary.Write(&blob, binary.LittleEndian, uint16(0))
+	binary.Write(&blob, binary.LittleEndian, uint32(16+body.Len()))
+	for _, o := range []uint16{currentOffset, previousOffset, queryOffset, unchangedOffset} {
+		binary.Write(&blob, binary.LittleEndian, o)
+	}
+	blob.Write(body.Bytes())
+	return blob.Bytes()
+}
+
+func utf16LE(s string) []byte {
+	var b bytes.Buffer
+	for _, c := range utf16.Encode([]rune(s)) {
+		binary.Write(&b, binary.LittleEndian, c)
+	}
+	return b.Bytes()
+}
+
+func TestDecodeManagedPassword(t *testing.T) {
+	retrieved := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	query, unchanged := 12*time.Hour, 36*time.Hour
+
+	t.Run("current and previous", func(t *testing.T) {
+		blob := managedPasswordBlob("new-pässword", "old-password", query, unchanged)
+		p, err := DecodeManagedPassword(blob, retrieved)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(p.Current, utf16LE("new-pässword")) {
+			t.Errorf("Current = %x", p.Current)
+		}
+		if !bytes.Equal(p.Previous, utf16LE("old-password")) {
+			t.Errorf("Previous = %x", p.Previous)
+		}
+		if p.QueryInterval != query || p.UnchangedInterval != unchanged {
+			t.Errorf("intervals = %v, %v, want %v, %v", p.QueryInterval, p.UnchangedInterval, query, unchanged)
+		}
+		if !p.NextQuery.Equal(retrieved.Add(query)) || !p.UnchangedUntil.Equal(retrieved.Add(unchanged)) {
+			t.Errorf("times = %v, %v", p.NextQuery, p.UnchangedUntil)
+		}
+	})
+
+	t.Run("no previous", func(t *testing.T) {
+		blob := managedPasswordBlob("current", "", query, unchanged)
+		p, err := DecodeManagedPassword(blob, retrieved)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(p.Current, utf16LE("current")) {
+			t.Errorf("Current = %x", p.Current)
+		}
+		if p.Previous != nil {
+			t.Errorf("Previous = %x, want nil", p.Previous)
+		}
+	})
+
+	valid := managedPasswordBlob("current", "previous", query, unchanged)
+	setUint16 := func(offset int, v uint16) []byte {
+		blob := append([]byte(nil), valid...)
+		binary.LittleEndian.PutUint16(blob[offset:], v)
+		return blob
+	}
+	for _, tt := range []struct {
+		name string
+		blob []byte
+		want string
+	}{
+		{"short header", valid[:10], "too short"},
+		{"truncated", valid[:len(valid)-4], "truncated"},
+		{"bad version", setUint16(0, 2), "version 2"},
+		{"current offset in header", setUint16(8, 4), "current password"},
+		{"current offset past end", setUint16(8, uint16(len(valid))), "current password"},
+		{"previous offset past end", setUint16(10, uint16(len(valid)+2)), "previous password"},
+		{"query offset past end", setUint16(12, uint16(len(valid)-4)), "query password interval"},
+		{"unchanged offset in header", setUint16(14, 2), "unchanged password interval"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := DecodeManagedPassword(tt.blob, retrieved)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not mention %q", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("unterminated password", func(t *testing.T) {
+		blob := managedPasswordBlob("current", "", query, unchanged)
+		// Point the current password at the intervals, which hold no
+		// terminator before the end of the blob.
+		binary.LittleEndian.PutUint16(blob[8:], binary.LittleEndian.Uint16(blob[14:])+1)
+		if _, err := DecodeManagedPassword(blob, retrieved); err == nil || errors.Unwrap(err) == nil {
+			t.Errorf("error = %v", err)
+		}
+	})
+}
@@ -7,6 +7,7 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/scjalliance/comshim v0.0.0-20251021001035-b69f3cdad6f3
 	github.com/scjalliance/comutil v0.0.0-20251021001321-6c7d8e87d8f5
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require golang.org/x/sys v0.37.0 // indirect
@@ -23,13 +23,26 @@ func (g *Group) closed() bool {
 }
 
 // Add adds an ADSI object to an existing group.
+//
+// The addition is recorded as a reverse change that removes the object again.
 func (g *Group) Add(item string) (err error) {
 	g.m.Lock()
 	defer g.m.Unlock()
 	if g.closed() {
 		return ErrClosed
 	}
-	return g.iface.Add(item)
+	e, err := g.writeEvent(WriteAddMember)
+	if err != nil {
+		return
+	}
+	e.Target = item
+	err = g.client.intercept(e, g.user, func() error {
+		return g.iface.Add(item)
+	})
+	if err != nil {
+		return
+	}
+	return g.record(ReverseChange{Op: OpRemoveMember, Path: e.Path, Name: item})
 }
 
 // Close will release resources consumed by the group. It should be
@@ -70,16 +83,31 @@ func (g *Group) Members() (m *Members, err error) {
 		return
 	}
 	m = NewMembers(imembers)
+	m.client = g.client
+	m.user = g.user
 	return
 }
 
 // Remove removes the specified user object from this group. The operation
 // does not remove the group object itself even when there is no member remaining in the group.
+//
+// The removal is recorded as a reverse change that adds the object again.
 func (g *Group) Remove(item string) error {
 	g.m.Lock()
 	defer g.m.Unlock()
 	if g.closed() {
 		return ErrClosed
 	}
-	return g.iface.Remove(item)
+	e, err := g.writeEvent(WriteRemoveMember)
+	if err != nil {
+		return err
+	}
+	e.Target = item
+	err = g.client.intercept(e, g.user, func() error {
+		return g.iface.Remove(item)
+	})
+	if err != nil {
+		return err
+	}
+	return g.record(ReverseChange{Op: OpAddMember, Path: e.Path, Name: item})
 }
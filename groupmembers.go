@@ -0,0 +1,291 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+	"github.com/scjalliance/comutil"
+)
+
+// memberBatchSize is the maximum number of member values that are sent in a
+// single modify request.
+const memberBatchSize = 1000
+
+// MemberResult describes the outcome of adding a member to a group or
+// removing a member from it.
+type MemberResult struct {
+	// Member is the ADsPath of the member.
+	Member string
+
+	// Op is WriteAddMember or WriteRemoveMember.
+	Op WriteOp
+
+	// Changed is true if the membership was modified. It is false if the
+	// object was already a member when it was to be added, or was not a
+	// member when it was to be removed.
+	Changed bool
+
+	// Err is the error that prevented the membership from being modified, if
+	// any.
+	Err error
+}
+
+// AddMembers adds the objects with the given ADsPaths to the group. Objects
+// that are already members are left as they are and reported as unchanged.
+//
+// See SetMembers for how the modifications are made and reported.
+func (g *Group) AddMembers(members ...string) ([]MemberResult, error) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	if g.closed() {
+		return nil, ErrClosed
+	}
+	current, err := g.memberPaths()
+	if err != nil {
+		return nil, err
+	}
+	add, unchanged := memberDelta(members, current, true)
+	return g.modifyMembers(add, nil, unchanged)
+}
+
+// RemoveMembers removes the objects with the given ADsPaths from the group.
+// Objects that are not members are reported as unchanged.
+//
+// See SetMembers for how the modifications are made and reported.
+func (g *Group) RemoveMembers(members ...string) ([]MemberResult, error) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	if g.closed() {
+		return nil, ErrClosed
+	}
+	current, err := g.memberPaths()
+	if err != nil {
+		return nil, err
+	}
+	remove, unchanged := memberDelta(members, current, false)
+	return g.modifyMembers(nil, remove, unchanged)
+}
+
+// SetMembers modifies the membership of the group so that its members are
+// exactly the objects with the given ADsPaths. Members are compared by
+// distinguished name, without regard to case or to the server that a path
+// names.
+//
+// Only the difference between the current and the desired members is sent.
+// Groups of the LDAP provider are modified with as few modify requests as
+// possible; if the directory rejects a request, its members are retried one
+// at a time so that each failure can be attributed to a member. A request
+// that is refused by the write policy or interceptor of the client is not
+// retried and fails for all of its members. Other providers are modified one
+// member at a time.
+//
+// A result is returned for each member that was to be added or removed. If
+// any member could not be modified, the returned error joins the errors of
+// the failed members.
+func (g *Group) SetMembers(desired []string) ([]MemberResult, error) {
+	g.m.Lock()
+	defer g.m.Unlock()
+	if g.closed() {
+		return nil, ErrClosed
+	}
+	current, err := g.memberPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	add, _ := memberDelta(desired, current, true)
+	wanted := make(map[string]bool, len(desired))
+	for _, path := range desired {
+		wanted[adspath.Key(path)] = true
+	}
+	var remove []string
+	for key, path := range current {
+		if !wanted[key] {
+			remove = append(remove, path)
+		}
+	}
+	return g.modifyMembers(add, remove, nil)
+}
+
+// memberPaths returns the ADsPaths of the current members of the group,
+// keyed by adspath.Key. The caller must hold a lock on the group.
+func (g *Group) memberPaths() (map[string]string, error) {
+	imembers, err := g.iface.Members()
+	if err != nil {
+		return nil, err
+	}
+	members := NewMembers(imembers)
+	defer members.Close()
+	iter, err := members.Iter()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	paths := make(map[string]string)
+	for {
+		member, err := iter.Next()
+		if err == io.EOF {
+			return paths, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		path, err := member.Path()
+		member.Close()
+		if err != nil {
+			return nil, err
+		}
+		paths[adspath.Key(path)] = path
+	}
+}
+
+// memberDelta splits the given members into those that must be modified and
+// results for those that are already in the desired state. If add is true the
+// members are to be added, otherwise they are to be removed.
+func memberDelta(members []string, current map[string]string, add bool) (modify []string, unchanged []MemberResult) {
+	op := WriteRemoveMember
+	if add {
+		op = WriteAddMember
+	}
+	seen := make(map[string]bool, len(members))
+	for _, path := range members {
+		key := adspath.Key(path)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if _, ok := current[key]; ok == add {
+			unchanged = append(unchanged, MemberResult{Member: path, Op: op})
+			continue
+		}
+		modify = append(modify, path)
+	}
+	return
+}
+
+// modifyMembers adds and removes the given members and returns the results,
+// including the given unchanged results. The caller must hold a lock on the
+// group.
+func (g *Group) modifyMembers(add, remove []string, unchanged []MemberResult) ([]MemberResult, error) {
+	results := append([]MemberResult(nil), unchanged...)
+
+	var dirobj *api.IDirectoryObject
+	if iunknown, err := g.iface.QueryInterface(comutil.GUID(comiid.IDirectoryObject)); err == nil {
+		defer iunknown.Release()
+		dirobj = (*api.IDirectoryObject)(unsafe.Pointer(iunknown))
+	}
+
+	for _, batch := range [...]struct {
+		op      WriteOp
+		members []string
+	}{{WriteRemoveMember, remove}, {WriteAddMember, add}} {
+		for len(batch.members) > 0 {
+			n := min(len(batch.members), memberBatchSize)
+			chunk := batch.members[:n]
+			batch.members = batch.members[n:]
+			if dirobj != nil {
+				vetoed, err := g.modifyMemberBatch(dirobj, batch.op, chunk)
+				if err == nil || vetoed {
+					// A write that was refused by the write policy or
+					// interceptor is not retried, so that it is neither
+					// reported nor refused twice.
+					for _, member := range chunk {
+						results = append(results, MemberResult{Member: member, Op: batch.op, Changed: err == nil, Err: err})
+					}
+					continue
+				}
+			}
+			for _, member := range chunk {
+				results = append(results, g.modifyMember(batch.op, member))
+			}
+		}
+	}
+
+	var errs []error
+	for _, r := range results {
+		if r.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Member, r.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// modifyMemberBatch adds or removes the given members with a single modify
+// request. Vetoed is true if the request was refused by the write policy or
+// the write interceptor of the client before it was sent. The caller must
+// hold a lock on the group.
+func (g *Group) modifyMemberBatch(dirobj *api.IDirectoryObject, op WriteOp, members []string) (vetoed bool, err error) {
+	dns := make([]interface{}, len(members))
+	for i, member := range members {
+		dn, err := dnFromPath(member)
+		if err != nil {
+			return false, err
+		}
+		dns[i] = dn
+	}
+
+	e, err := g.writeEvent(op)
+	if err != nil {
+		return false, err
+	}
+	change := Change{Name: "member", New: dns}
+	mod := api.AttrModification{Name: "member", ControlCode: api.ADS_ATTR_APPEND, Values: dns}
+	if op == WriteRemoveMember {
+		change = Change{Name: "member", Old: dns}
+		mod.ControlCode = api.ADS_ATTR_DELETE
+	}
+	e.Changes = []Change{change}
+
+	sent := false
+	err = g.client.intercept(e, g.user, func() error {
+		sent = true
+		_, err := dirobj.SetObjectAttributes([]api.AttrModification{mod})
+		return err
+	})
+	if err != nil {
+		return !sent, err
+	}
+	// Replaying the reverse change deletes the added values or adds the
+	// removed ones.
+	return false, g.record(ReverseChange{Op: OpModify, Path: e.Path, Attrs: []Change{{Name: "member", Old: change.New, New: change.Old}}})
+}
+
+// modifyMember adds or removes a single member. The caller must hold a lock on
+// the group.
+func (g *Group) modifyMember(op WriteOp, member string) MemberResult {
+	result := MemberResult{Member: member, Op: op}
+	e, err := g.writeEvent(op)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	e.Target = member
+
+	reverse := ReverseChange{Op: OpRemoveMember, Path: e.Path, Name: member}
+	err = g.client.intercept(e, g.user, func() error {
+		if op == WriteAddMember {
+			return g.iface.Add(member)
+		}
+		return g.iface.Remove(member)
+	})
+	if op == WriteRemoveMember {
+		reverse.Op = OpAddMember
+	}
+
+	switch {
+	case err == nil:
+		result.Changed = true
+		result.Err = g.record(reverse)
+	case op == WriteAddMember && (api.IsHresult(err, api.E_DS_OBJECT_ALREADY_EXISTS) || api.IsHresult(err, api.E_DS_ATT_VAL_ALREADY_EXISTS) || api.IsHresult(err, api.E_ADS_OBJECT_EXISTS)):
+	case op == WriteRemoveMember && api.IsHresult(err, api.E_DS_NO_ATTRIBUTE_OR_VALUE):
+	default:
+		result.Err = err
+	}
+	return result
+}
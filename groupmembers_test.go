@@ -0,0 +1,57 @@
+package adsi
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-adsi/adsi/adspath"
+)
+
+func TestMemberDelta(t *testing.T) {
+	jane := "LDAP://CN=Jane Doe,OU=Staff,DC=example,DC=com"
+	john := "LDAP://CN=John Doe,OU=Staff,DC=example,DC=com"
+	current := map[string]string{adspath.Key(jane): jane}
+
+	for _, tt := range []struct {
+		name      string
+		members   []string
+		add       bool
+		modify    []string
+		unchanged []MemberResult
+	}{
+		{
+			name:    "add new member",
+			members: []string{john},
+			add:     true,
+			modify:  []string{john},
+		},
+		{
+			name:      "add existing member",
+			members:   []string{"LDAP://dc1.example.com/cn=jane doe,ou=staff,dc=example,dc=com", john},
+			add:       true,
+			modify:    []string{john},
+			unchanged: []MemberResult{{Member: "LDAP://dc1.example.com/cn=jane doe,ou=staff,dc=example,dc=com", Op: WriteAddMember}},
+		},
+		{
+			name:    "add duplicates",
+			members: []string{john, "LDAP://cn=john doe,ou=staff,dc=example,dc=com"},
+			add:     true,
+			modify:  []string{john},
+		},
+		{
+			name:    "remove member",
+			members: []string{jane},
+			modify:  []string{jane},
+		},
+		{
+			name:      "remove non-member",
+			members:   []string{john, john},
+			unchanged: []MemberResult{{Member: john, Op: WriteRemoveMember}},
+		},
+	} {
+		modify, unchanged := memberDelta(tt.members, current, tt.add)
+		if !reflect.DeepEqual(modify, tt.modify) || !reflect.DeepEqual(unchanged, tt.unchanged) {
+			t.Errorf("%s: memberDelta() = %v, %v, want %v, %v", tt.name, modify, unchanged, tt.modify, tt.unchanged)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package adsi
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UndoJournal persists reverse changes so that the modifications made by a
+// whole job can be undone later, even if the process that made them has
+// exited. Each recorded batch of reverse changes is written as a single line
+// of JSON.
+//
+// A journal is attached to a client with Client.SetUndoJournal, after which
+// every reverse change recorded by objects opened through the client is
+// written to it. Journals are safe for concurrent use.
+type UndoJournal struct {
+	m      sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// journalEntry is the JSON form of a batch of reverse changes in a journal.
+type journalEntry struct {
+	Time    time.Time       `json:"time"`
+	Changes []ReverseChange `json:"changes"`
+}
+
+// NewUndoJournal returns a journal that writes to w.
+func NewUndoJournal(w io.Writer) *UndoJournal {
+	return &UndoJournal{w: w}
+}
+
+// OpenUndoJournal opens the journal file at the given path for appending,
+// creating it if necessary. The file is synced after each batch is written.
+// The journal must be closed when it is no longer needed.
+func OpenUndoJournal(path string) (*UndoJournal, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &UndoJournal{w: f, closer: f}, nil
+}
+
+// Record writes a batch of reverse changes to the journal. The changes must
+// be in replay order. Values of secret attributes, such as passwords, are
+// never written; changes to them are dropped.
+func (j *UndoJournal) Record(changes ...ReverseChange) error {
+	changes = withoutSecrets(changes)
+	if len(changes) == 0 {
+		return nil
+	}
+	line, err := json.Marshal(journalEntry{Time: time.Now().UTC(), Changes: changes})
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	j.m.Lock()
+	defer j.m.Unlock()
+	if j.w == nil {
+		return ErrClosed
+	}
+	if _, err := j.w.Write(line); err != nil {
+		return err
+	}
+	if f, ok := j.closer.(*os.File); ok {
+		return f.Sync()
+	}
+	return nil
+}
+
+// Close closes the journal. If the journal was opened with OpenUndoJournal
+// its file is closed.
+func (j *UndoJournal) Close() error {
+	j.m.Lock()
+	defer j.m.Unlock()
+	j.w = nil
+	if j.closer == nil {
+		return nil
+	}
+	err := j.closer.Close()
+	j.closer = nil
+	return err
+}
+
+// ReadUndoJournal reads the reverse changes recorded in a journal and returns
+// them in the order in which they must be replayed to undo the recorded job.
+// The most recently recorded batch comes first.
+func ReadUndoJournal(r io.Reader) ([]ReverseChange, error) {
+	var batches [][]ReverseChange
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("undo journal line %d: %v", n, err)
+		}
+		batches = append(batches, entry.Changes)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var changes []ReverseChange
+	for i := len(batches) - 1; i >= 0; i-- {
+		changes = append(changes, batches[i]...)
+	}
+	return changes, nil
+}
+
+// jsonChange is the JSON form of a Change.
+type jsonChange struct {
+	Name string      `json:"name"`
+	Old  []jsonValue `json:"old,omitempty"`
+	New  []jsonValue `json:"new,omitempty"`
+}
+
+// jsonValue is the JSON form of an attribute value. Exactly one of its fields
+// is set, which preserves the type of the value.
+type jsonValue struct {
+	S *string    `json:"s,omitempty"`
+	I *int64     `json:"i,omitempty"`
+	B *bool      `json:"b,omitempty"`
+	X *[]byte    `json:"x,omitempty"`
+	T *time.Time `json:"t,omitempty"`
+}
+
+// MarshalJSON encodes the change as JSON in a form that preserves the type of
+// each value.
+func (c Change) MarshalJSON() ([]byte, error) {
+	jc := jsonChange{Name: c.Name}
+	var err error
+	if jc.Old, err = encodeJSONValues(c.Old); err != nil {
+		return nil, err
+	}
+	if jc.New, err = encodeJSONValues(c.New); err != nil {
+		return nil, err
+	}
+	return json.Marshal(jc)
+}
+
+// UnmarshalJSON decodes a change that was encoded with MarshalJSON.
+func (c *Change) UnmarshalJSON(data []byte) error {
+	var jc jsonChange
+	if err := json.Unmarshal(data, &jc); err != nil {
+		return err
+	}
+	c.Name = jc.Name
+	c.Old = decodeJSONValues(jc.Old)
+	c.New = decodeJSONValues(jc.New)
+	return nil
+}
+
+func encodeJSONValues(values []interface{}) ([]jsonValue, error) {
+	values = normalizeValues(values)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	result := make([]jsonValue, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case string:
+			result[i].S = &v
+		case int64:
+			result[i].I = &v
+		case bool:
+			result[i].B = &v
+		case []byte:
+			result[i].X = &v
+		case time.Time:
+			result[i].T = &v
+		default:
+			return nil, fmt.Errorf("%w: %T", ErrUnsupportedValue, value)
+		}
+	}
+	return result, nil
+}
+
+func decodeJSONValues(values []jsonValue) []interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		switch {
+		case v.S != nil:
+			result = append(result, *v.S)
+		case v.I != nil:
+			result = append(result, *v.I)
+		case v.B != nil:
+			result = append(result, *v.B)
+		case v.X != nil:
+			result = append(result, *v.X)
+		case v.T != nil:
+			result = append(result, *v.T)
+		}
+	}
+	return result
+}
+
+// withoutSecrets returns the reverse changes with the values of secret
+// attributes removed. Modifications that are left without attributes are
+// dropped.
+func withoutSecrets(changes []ReverseChange) []ReverseChange {
+	result := make([]ReverseChange, 0, len(changes))
+	for _, rc := range changes {
+		var attrs []Change
+		for _, c := range rc.Attrs {
+			if !secretAttrs[strings.ToLower(c.Name)] {
+				attrs = append(attrs, c)
+			}
+		}
+		if rc.Op == OpModify && len(attrs) == 0 {
+			continue
+		}
+		rc.Attrs = attrs
+		result = append(result, rc)
+	}
+	return result
+}
@@ -0,0 +1,95 @@
+package adsi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-adsi/adsi/keytab"
+)
+
+// SetPasswordKeytab sets the password of the account, like SetPassword, and
+// returns a keytab that holds the keys derived from the new password for the
+// given principals, such as "HTTP/web.example.com". Principals without a
+// realm are placed in the given realm. If no principals are given, the keytab
+// holds the keys of the sAMAccountName of the account.
+//
+// If the realm is empty, the upper case DNS name of the domain that contains
+// the account is used. The keys are derived with the salt that Active
+// Directory uses for the account and are versioned with the
+// msDS-KeyVersionNumber that the account has once the password has been set.
+// Keys of each of the given encryption types are written; if none are given,
+// keytab.DefaultEncryptionTypes is used.
+//
+// SetPasswordKeytab is only supported by the LDAP provider.
+func (u *User) SetPasswordKeytab(password, realm string, principals []string, types ...keytab.EncryptionType) (*keytab.Keytab, error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return nil, ErrClosed
+	}
+	if err := u.Pull("sAMAccountName"); err != nil {
+		return nil, err
+	}
+	sam, err := u.AttrString("sAMAccountName")
+	if err != nil {
+		return nil, err
+	}
+	if realm == "" {
+		path, err := u.iface.AdsPath()
+		if err != nil {
+			return nil, err
+		}
+		dn, err := dnFromPath(path)
+		if err != nil {
+			return nil, err
+		}
+		if realm = dnsDomain(domainDN(dn)); realm == "" {
+			return nil, fmt.Errorf("the realm of %s cannot be determined", dn)
+		}
+	}
+	realm = strings.ToUpper(realm)
+
+	if len(principals) == 0 {
+		principals = []string{sam}
+	}
+	names := make([]keytab.Principal, len(principals))
+	for i, principal := range principals {
+		if names[i], err = keytab.ParsePrincipal(principal, realm); err != nil {
+			return nil, err
+		}
+	}
+	// The keys are derived before the password is set, so that an
+	// unsupported encryption type does not leave the account with a password
+	// for which no keytab was produced.
+	if _, err := keytab.New(nil, password, "", 0, types...); err != nil {
+		return nil, err
+	}
+
+	if err := u.setPassword(password); err != nil {
+		return nil, err
+	}
+	if err := u.Pull("msDS-KeyVersionNumber"); err != nil {
+		return nil, err
+	}
+	kvno, err := u.AttrInt64("msDS-KeyVersionNumber")
+	if err != nil {
+		return nil, fmt.Errorf("msDS-KeyVersionNumber: %w", err)
+	}
+	return keytab.New(names, password, keytab.Salt(realm, sam), uint32(kvno), types...)
+}
+
+// dnsDomain returns the DNS name of the domain with the given distinguished
+// name, which is made up of its DC components.
+func dnsDomain(dn string) string {
+	var labels []string
+	for dn != "" {
+		i := lastUnescapedComma(dn)
+		rdn := strings.TrimSpace(dn[i+1:])
+		dn = dn[:max(i, 0)]
+		if len(rdn) < 3 || !strings.EqualFold(rdn[:3], "DC=") {
+			return ""
+		}
+		labels = append([]string{rdn[3:]}, labels...)
+	}
+	return strings.Join(labels, ".")
+}
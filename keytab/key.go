@@ -0,0 +1,193 @@
+package keytab
+
+import (
+	"crypto/aes"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// EncryptionType is a Kerberos encryption type, as assigned by RFC 3961 and
+// its successors.
+type EncryptionType int32
+
+// Encryption types supported by Active Directory for which keys can be
+// derived.
+const (
+	AES128 EncryptionType = 17 // aes128-cts-hmac-sha1-96
+	AES256 EncryptionType = 18 // aes256-cts-hmac-sha1-96
+	RC4    EncryptionType = 23 // rc4-hmac
+)
+
+// DefaultEncryptionTypes holds the encryption types of the keys that are
+// written to a keytab unless configured otherwise, strongest first.
+var DefaultEncryptionTypes = []EncryptionType{AES256, AES128, RC4}
+
+// String returns the name of the encryption type as used by MIT Kerberos.
+func (t EncryptionType) String() string {
+	switch t {
+	case AES128:
+		return "aes128-cts-hmac-sha1-96"
+	case AES256:
+		return "aes256-cts-hmac-sha1-96"
+	case RC4:
+		return "arcfour-hmac"
+	default:
+		return fmt.Sprintf("EncryptionType(%d)", int32(t))
+	}
+}
+
+// aesIterations is the PBKDF2 iteration count of the AES string-to-key
+// function that is used by Active Directory.
+const aesIterations = 4096
+
+// Key is a Kerberos key.
+type Key struct {
+	Type  EncryptionType
+	Value []byte
+}
+
+// DeriveKey derives the key of the given encryption type from a password and
+// salt. The salt is ignored for RC4, whose key is the NT hash of the
+// password.
+func DeriveKey(t EncryptionType, password, salt string) (Key, error) {
+	switch t {
+	case AES128:
+		return aesKey(t, password, salt, 16)
+	case AES256:
+		return aesKey(t, password, salt, 32)
+	case RC4:
+		return Key{Type: t, Value: ntHash(password)}, nil
+	default:
+		return Key{}, fmt.Errorf("%w: %v", ErrUnsupportedType, t)
+	}
+}
+
+// Salt returns the salt that Active Directory uses to derive the AES keys of
+// the account with the given sAMAccountName in the given realm.
+//
+// For users the salt is the upper case realm followed by the sAMAccountName.
+// For computers, whose sAMAccountName ends with a dollar sign, it is the upper
+// case realm followed by "host", the lower case name without the dollar sign,
+// a dot and the lower case realm.
+func Salt(realm, samAccountName string) string {
+	realm = strings.ToUpper(realm)
+	if name, ok := strings.CutSuffix(samAccountName, "$"); ok {
+		return realm + "host" + strings.ToLower(name) + "." + strings.ToLower(realm)
+	}
+	return realm + samAccountName
+}
+
+// aesKey derives an AES key with the iteration count used by Active
+// Directory.
+func aesKey(t EncryptionType, password, salt string, size int) (Key, error) {
+	key, err := stringToKey(password, salt, aesIterations, size)
+	if err != nil {
+		return Key{}, err
+	}
+	return Key{Type: t, Value: key}, nil
+}
+
+// stringToKey implements the string-to-key function of RFC 3962, which
+// derives an AES key of the given size from a password and salt.
+func stringToKey(password, salt string, iterations, size int) ([]byte, error) {
+	tkey, err := pbkdf2.Key(sha1.New, password, []byte(salt), iterations, size)
+	if err != nil {
+		return nil, err
+	}
+	return deriveAES(tkey, []byte("kerberos"))
+}
+
+// deriveAES implements the DK function of RFC 3961 for the AES encryption
+// types, which derives a key of the same size as the given key from a
+// constant.
+func deriveAES(key, constant []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	in := nfold(constant, aes.BlockSize)
+	out := make([]byte, 0, len(key)+aes.BlockSize)
+	for len(out) < len(key) {
+		next := make([]byte, aes.BlockSize)
+		block.Encrypt(next, in)
+		out = append(out, next...)
+		in = next
+	}
+	return out[:len(key)], nil
+}
+
+// nfold implements the n-fold operation of RFC 3961, which stretches or
+// shrinks the input to the given number of bytes.
+func nfold(in []byte, size int) []byte {
+	inBits := len(in) * 8
+	outBits := size * 8
+	lcm := inBits * outBits / gcd(inBits, outBits)
+
+	// The input is repeated, each copy rotated right by 13 bits more than
+	// the last, until it is lcm bits long. The result is split into
+	// size-byte chunks which are added with end-around carry.
+	buf := make([]byte, lcm/8)
+	for i := 0; i < lcm/inBits; i++ {
+		copy(buf[i*len(in):], rotateRight(in, 13*i))
+	}
+	out := make([]byte, size)
+	for i := 0; i < len(buf); i += size {
+		out = onesComplementAdd(out, buf[i:i+size])
+	}
+	return out
+}
+
+// rotateRight rotates the bits of b right by n bits.
+func rotateRight(b []byte, n int) []byte {
+	bits := len(b) * 8
+	n %= bits
+	out := make([]byte, len(b))
+	for i := 0; i < bits; i++ {
+		src := (i - n + bits) % bits
+		if b[src/8]&(0x80>>(src%8)) != 0 {
+			out[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return out
+}
+
+// onesComplementAdd adds two big-endian numbers of equal length with
+// end-around carry.
+func onesComplementAdd(a, b []byte) []byte {
+	out := make([]byte, len(a))
+	carry := 0
+	for i := len(a) - 1; i >= 0; i-- {
+		sum := int(a[i]) + int(b[i]) + carry
+		out[i] = byte(sum)
+		carry = sum >> 8
+	}
+	for carry != 0 {
+		for i := len(out) - 1; i >= 0 && carry != 0; i-- {
+			sum := int(out[i]) + carry
+			out[i] = byte(sum)
+			carry = sum >> 8
+		}
+	}
+	return out
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// ntHash returns the MD4 hash of the UTF-16LE encoding of the password.
+func ntHash(password string) []byte {
+	u := utf16.Encode([]rune(password))
+	b := make([]byte, 2*len(u))
+	for i, c := range u {
+		b[2*i] = byte(c)
+		b[2*i+1] = byte(c >> 8)
+	}
+	return md4Sum(b)
+}
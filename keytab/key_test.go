@@ -0,0 +1,169 @@
+package keytab
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// The n-fold test vectors of RFC 3961, appendix A.1.
+func TestNFold(t *testing.T) {
+	for _, tt := range []struct {
+		bits int
+		in   string
+		want string
+	}{
+		{64, "012345", "be072631276b1955"},
+		{56, "password", "78a07b6caf85fa"},
+		{64, "Rough Consensus, and Running Code", "bb6ed30870b7f0e0"},
+		{168, "password", "59e4a8ca7c0385c3c37b3f6d2000247cb6e6bd5b3e"},
+		{192, "MASSACHVSETTS INSTITVTE OF TECHNOLOGY", "db3b0d8f0b061e603282b308a50841229ad798fab9540c1b"},
+		{168, "Q", "518a54a215a8452a518a54a215a8452a518a54a215"},
+		{168, "ba", "fb25d531ae8974499f52fd92ea9857c4ba24cf297e"},
+		{64, "kerberos", "6b65726265726f73"},
+		{128, "kerberos", "6b65726265726f737b9b5b2b93132b93"},
+		{168, "kerberos", "8372c236344e5f1550cd0747e15d62ca7a5a3bcea4"},
+		{256, "kerberos", "6b65726265726f737b9b5b2b93132b935c9bdcdad95c9899c4cae4dee6d6cae4"},
+	} {
+		if got := nfold([]byte(tt.in), tt.bits/8); !bytes.Equal(got, unhex(t, tt.want)) {
+			t.Errorf("%d-fold(%q) = %x, want %s", tt.bits, tt.in, got, tt.want)
+		}
+	}
+}
+
+// The AES string-to-key test vectors of RFC 3962, appendix B.
+func TestStringToKey(t *testing.T) {
+	for _, tt := range []struct {
+		iterations     int
+		password, salt string
+		aes128, aes256 string
+	}{
+		{1, "password", "ATHENA.MIT.EDUraeburn",
+			"42263c6e89f4fc28b8df68ee09799f15",
+			"fe697b52bc0d3ce14432ba036a92e65bbb52280990a2fa27883998d72af30161"},
+		{2, "password", "ATHENA.MIT.EDUraeburn",
+			"c651bf29e2300ac27fa469d693bdda13",
+			"a2e16d16b36069c135d5e9d2e25f896102685618b95914b467c67622225824ff"},
+		{1200, "password", "ATHENA.MIT.EDUraeburn",
+			"4c01cd46d632d01e6dbe230a01ed642a",
+			"55a6ac740ad17b4846941051e1e8b0a7548d93b0ab30a8bc3ff16280382b8c2a"},
+		{5, "password", "\x12\x34\x56\x78\x78\x56\x34\x12",
+			"e9b23d52273747dd5c35cb55be619d8e",
+			"97a4e786be20d81a382d5ebc96d5909cabcdadc87ca48f574504159f16c36e31"},
+		{1200, strings.Repeat("X", 64), "pass phrase equals block size",
+			"59d1bb789a828b1aa54ef9c2883f69ed",
+			"89adee3608db8bc71f1bfbfe459486b05618b70cbae22092534e56c553ba4b34"},
+		{1200, strings.Repeat("X", 65), "pass phrase exceeds block size",
+			"cb8005dc5f90179a7f02104c0018751d",
+			"d78c5c9cb872a8c9dad4697f0bb5b2d21496c82beb2caeda2112fceea057401b"},
+		{50, "\U0001D11E", "EXAMPLE.COMpianist",
+			"f149c1f2e154a73452d43e7fe62a56e5",
+			"4b6d9839f84406df1f09cc166db4b83c571848b784a3d6bdc346589a3e393f9e"},
+	} {
+		for _, k := range []struct {
+			size int
+			want string
+		}{{16, tt.aes128}, {32, tt.aes256}} {
+			got, err := stringToKey(tt.password, tt.salt, tt.iterations, k.size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, unhex(t, k.want)) {
+				t.Errorf("string-to-key(%q, %q, %d) with %d-bit key = %x, want %s", tt.password, tt.salt, tt.iterations, 8*k.size, got, k.want)
+			}
+		}
+	}
+}
+
+// The test suite of RFC 1320, appendix A.5.
+func TestMD4(t *testing.T) {
+	for _, tt := range []struct {
+		in, want string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"a", "bde52cb31de33e46245e05fbdbd6fb24"},
+		{"abc", "a448017aaf21d8525fc10ae87aa6729d"},
+		{"message digest", "d9130a8164549fe818874806e1c7014b"},
+		{"abcdefghijklmnopqrstuvwxyz", "d79e1c308aa5bbcdeea8ed63df412da9"},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", "043f8582f241db351ce627e153e7f0e4"},
+		{strings.Repeat("1234567890", 8), "e33b4ddc9c38f2199c3e7b164fcc0536"},
+	} {
+		if got := md4Sum([]byte(tt.in)); !bytes.Equal(got, unhex(t, tt.want)) {
+			t.Errorf("MD4(%q) = %x, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDeriveKeyRC4(t *testing.T) {
+	for _, tt := range []struct {
+		password, want string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"password", "8846f7eaee8fb117ad06bdd830b7586c"},
+	} {
+		key, err := DeriveKey(RC4, tt.password, "ignored")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(key.Value, unhex(t, tt.want)) {
+			t.Errorf("NT hash of %q = %x, want %s", tt.password, key.Value, tt.want)
+		}
+	}
+}
+
+func TestSalt(t *testing.T) {
+	for _, tt := range []struct {
+		realm, name, want string
+	}{
+		{"example.com", "svc-web", "EXAMPLE.COMsvc-web"},
+		{"EXAMPLE.COM", "WEB01$", "EXAMPLE.COMhostweb01.example.com"},
+	} {
+		if got := Salt(tt.realm, tt.name); got != tt.want {
+			t.Errorf("Salt(%q, %q) = %q, want %q", tt.realm, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	p, err := ParsePrincipal("HTTP/web.example.com", "EXAMPLE.COM")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kt := &Keytab{Entries: []Entry{{
+		Principal: p,
+		Timestamp: time.Unix(1700000000, 0),
+		KVNO:      0x102,
+		Key:       Key{Type: RC4, Value: unhex(t, "000102030405060708090a0b0c0d0e0f")},
+	}}}
+	got, err := kt.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := unhex(t, ""+
+		"0502"+ // version
+		"00000047"+ // entry length
+		"0002"+ // component count
+		"000b"+hex.EncodeToString([]byte("EXAMPLE.COM"))+
+		"0004"+hex.EncodeToString([]byte("HTTP"))+
+		"000f"+hex.EncodeToString([]byte("web.example.com"))+
+		"00000003"+ // name type
+		"6553f100"+ // timestamp
+		"02"+ // 8-bit key version number
+		"0017"+ // encryption type
+		"0010"+"000102030405060708090a0b0c0d0e0f"+
+		"00000102") // 32-bit key version number
+	if !bytes.Equal(got, want) {
+		t.Errorf("MarshalBinary() =\n%x\nwant\n%x", got, want)
+	}
+}
@@ -0,0 +1,184 @@
+// Package keytab derives Kerberos keys from the passwords of Active Directory
+// accounts and writes them in the keytab format of MIT Kerberos, which is
+// read by Kerberos implementations on Linux and other platforms.
+//
+// Keys are derived with the salt that Active Directory uses for the account,
+// so that the keytab matches the keys issued by the domain controllers:
+//
+//	salt := keytab.Salt("EXAMPLE.COM", "svc-web")
+//	kt, err := keytab.New(principals, password, salt, kvno)
+//	_, err = kt.WriteTo(file)
+package keytab
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnsupportedType is returned when a key is requested for an
+	// encryption type that cannot be derived.
+	ErrUnsupportedType = errors.New("unsupported encryption type")
+
+	// ErrInvalidPrincipal is returned when a string cannot be parsed as a
+	// principal name.
+	ErrInvalidPrincipal = errors.New("invalid principal name")
+)
+
+// Principal name types.
+const (
+	NameTypePrincipal = 1 // KRB5_NT_PRINCIPAL
+	NameTypeService   = 3 // KRB5_NT_SRV_HST
+)
+
+// keytabVersion is the version of the keytab format that is written.
+const keytabVersion = 0x0502
+
+// Principal is a Kerberos principal name.
+type Principal struct {
+	// Components holds the components of the name, such as the service
+	// class and host name of a service principal.
+	Components []string
+
+	// Realm is the Kerberos realm, which is the upper case DNS name of the
+	// domain.
+	Realm string
+
+	// NameType is the name type of the principal. It is NameTypePrincipal for
+	// names with a single component and NameTypeService otherwise.
+	NameType int32
+}
+
+// ParsePrincipal parses a principal name of the form "name@REALM" or
+// "service/host@REALM". If the name has no realm, the given default realm is
+// used.
+func ParsePrincipal(s, realm string) (Principal, error) {
+	name := s
+	if i := strings.LastIndexByte(s, '@'); i >= 0 {
+		name, realm = s[:i], s[i+1:]
+	}
+	if name == "" || realm == "" {
+		return Principal{}, fmt.Errorf("%w: %q", ErrInvalidPrincipal, s)
+	}
+	p := Principal{Components: strings.Split(name, "/"), Realm: realm, NameType: NameTypePrincipal}
+	for _, c := range p.Components {
+		if c == "" {
+			return Principal{}, fmt.Errorf("%w: %q", ErrInvalidPrincipal, s)
+		}
+	}
+	if len(p.Components) > 1 {
+		p.NameType = NameTypeService
+	}
+	return p, nil
+}
+
+// String returns the principal name in the form "service/host@REALM".
+func (p Principal) String() string {
+	return strings.Join(p.Components, "/") + "@" + p.Realm
+}
+
+// Entry is a key of a principal in a keytab.
+type Entry struct {
+	Principal Principal
+
+	// Timestamp is the time at which the entry was written.
+	Timestamp time.Time
+
+	// KVNO is the key version number, which must match the
+	// msDS-KeyVersionNumber of the account.
+	KVNO uint32
+
+	Key Key
+}
+
+// Keytab is a set of keys.
+type Keytab struct {
+	Entries []Entry
+}
+
+// New returns a keytab that holds a key of each of the given encryption types
+// for each of the principals, derived from the password and salt. If no
+// encryption types are given, DefaultEncryptionTypes is used.
+func New(principals []Principal, password, salt string, kvno uint32, types ...EncryptionType) (*Keytab, error) {
+	if len(types) == 0 {
+		types = DefaultEncryptionTypes
+	}
+	keys := make([]Key, len(types))
+	for i, t := range types {
+		var err error
+		if keys[i], err = DeriveKey(t, password, salt); err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now()
+	kt := &Keytab{}
+	for _, p := range principals {
+		for _, key := range keys {
+			kt.Entries = append(kt.Entries, Entry{Principal: p, Timestamp: now, KVNO: kvno, Key: key})
+		}
+	}
+	return kt, nil
+}
+
+// MarshalBinary returns the keytab in the keytab format of MIT Kerberos.
+func (kt *Keytab) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint16(keytabVersion))
+	for i, e := range kt.Entries {
+		entry, err := e.marshal()
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i, err)
+		}
+		binary.Write(&buf, binary.BigEndian, int32(len(entry)))
+		buf.Write(entry)
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteTo writes the keytab to w in the keytab format of MIT Kerberos.
+func (kt *Keytab) WriteTo(w io.Writer) (int64, error) {
+	b, err := kt.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// marshal returns the encoding of the entry, without its length.
+func (e Entry) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	writeString := func(s []byte) error {
+		if len(s) > 0xFFFF {
+			return errors.New("value is too long")
+		}
+		binary.Write(&buf, binary.BigEndian, uint16(len(s)))
+		buf.Write(s)
+		return nil
+	}
+
+	binary.Write(&buf, binary.BigEndian, uint16(len(e.Principal.Components)))
+	if err := writeString([]byte(e.Principal.Realm)); err != nil {
+		return nil, err
+	}
+	for _, c := range e.Principal.Components {
+		if err := writeString([]byte(c)); err != nil {
+			return nil, err
+		}
+	}
+	binary.Write(&buf, binary.BigEndian, e.Principal.NameType)
+	binary.Write(&buf, binary.BigEndian, uint32(e.Timestamp.Unix()))
+	// The 8-bit version number is followed by the full 32-bit one.
+	buf.WriteByte(byte(e.KVNO))
+	binary.Write(&buf, binary.BigEndian, uint16(e.Key.Type))
+	if err := writeString(e.Key.Value); err != nil {
+		return nil, err
+	}
+	binary.Write(&buf, binary.BigEndian, e.KVNO)
+	return buf.Bytes(), nil
+}
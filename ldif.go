@@ -0,0 +1,201 @@
+package adsi
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/go-adsi/adsi/adspath"
+)
+
+// WriteLDIF writes the given reverse changes to w as LDIF change records, as
+// described by RFC 2849. The records can be applied with standard LDAP tools
+// such as ldapmodify or ldifde.
+//
+// Only reverse changes for objects with LDAP paths can be written. Values of
+// secret attributes, such as passwords, are never written.
+func WriteLDIF(w io.Writer, changes []ReverseChange) error {
+	changes = withoutSecrets(changes)
+	bw := bufio.NewWriter(w)
+	bw.WriteString("version: 1\n")
+	for i, rc := range changes {
+		bw.WriteByte('\n')
+		if err := writeLDIFRecord(bw, rc); err != nil {
+			return fmt.Errorf("change %d (%v): %w", i, rc, err)
+		}
+	}
+	return bw.Flush()
+}
+
+func writeLDIFRecord(w *bufio.Writer, rc ReverseChange) error {
+	switch rc.Op {
+	case OpModify:
+		dn, err := dnFromPath(rc.Path)
+		if err != nil {
+			return err
+		}
+		writeLDIFLine(w, "dn", dn)
+		w.WriteString("changetype: modify\n")
+		for _, c := range rc.Attrs {
+			switch {
+			case len(c.New) == 0 && len(c.Old) == 0:
+				continue
+			case len(c.New) == 0:
+				writeLDIFLine(w, "delete", c.Name)
+				if err := writeLDIFValues(w, c.Name, c.Old); err != nil {
+					return err
+				}
+			case len(c.Old) == 0:
+				writeLDIFLine(w, "add", c.Name)
+				if err := writeLDIFValues(w, c.Name, c.New); err != nil {
+					return err
+				}
+			default:
+				writeLDIFLine(w, "replace", c.Name)
+				if err := writeLDIFValues(w, c.Name, c.New); err != nil {
+					return err
+				}
+			}
+			w.WriteString("-\n")
+		}
+	case OpAdd:
+		parent, err := dnFromPath(rc.Parent)
+		if err != nil {
+			return err
+		}
+		if rc.Incomplete {
+			w.WriteString("# The attributes of this object could not be read before it was deleted.\n")
+		}
+		writeLDIFLine(w, "dn", rc.Name+","+parent)
+		w.WriteString("changetype: add\n")
+		writeLDIFLine(w, "objectClass", rc.Class)
+		for _, c := range rc.Attrs {
+			if err := writeLDIFValues(w, c.Name, c.New); err != nil {
+				return err
+			}
+		}
+	case OpDelete:
+		dn, err := dnFromPath(rc.Path)
+		if err != nil {
+			return err
+		}
+		writeLDIFLine(w, "dn", dn)
+		w.WriteString("changetype: delete\n")
+	case OpMove:
+		dn, err := dnFromPath(rc.Path)
+		if err != nil {
+			return err
+		}
+		parent, err := dnFromPath(rc.Parent)
+		if err != nil {
+			return err
+		}
+		writeLDIFLine(w, "dn", dn)
+		w.WriteString("changetype: modrdn\n")
+		writeLDIFLine(w, "newrdn", rc.Name)
+		w.WriteString("deleteoldrdn: 1\n")
+		writeLDIFLine(w, "newsuperior", parent)
+	case OpAddMember, OpRemoveMember:
+		dn, err := dnFromPath(rc.Path)
+		if err != nil {
+			return err
+		}
+		member, err := dnFromPath(rc.Name)
+		if err != nil {
+			return err
+		}
+		writeLDIFLine(w, "dn", dn)
+		w.WriteString("changetype: modify\n")
+		if rc.Op == OpAddMember {
+			w.WriteString("add: member\n")
+		} else {
+			w.WriteString("delete: member\n")
+		}
+		writeLDIFLine(w, "member", member)
+		w.WriteString("-\n")
+	default:
+		return fmt.Errorf("invalid reverse operation %d", int(rc.Op))
+	}
+	return nil
+}
+
+// dnFromPath returns the distinguished name of the object at the given LDAP
+// ADsPath.
+func dnFromPath(path string) (string, error) {
+	p, err := adspath.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	if p.Scheme != adspath.LDAP && p.Scheme != adspath.GC {
+		return "", fmt.Errorf("%s is not an LDAP path", path)
+	}
+	// Forward slashes in distinguished names are escaped in ADsPaths.
+	return p.DN(), nil
+}
+
+// pathFromDN returns the ADsPath of the object with the given distinguished
+// name, using the scheme and server of the given ADsPath.
+func pathFromDN(base, dn string) (string, error) {
+	p, err := adspath.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	return adspath.FromDN(p.Scheme, p.Host, dn).String(), nil
+}
+
+func writeLDIFValues(w *bufio.Writer, name string, values []interface{}) error {
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			writeLDIFLine(w, name, v)
+		case int64:
+			writeLDIFLine(w, name, strconv.FormatInt(v, 10))
+		case bool:
+			if v {
+				writeLDIFLine(w, name, "TRUE")
+			} else {
+				writeLDIFLine(w, name, "FALSE")
+			}
+		case []byte:
+			w.WriteString(name + ":: " + base64.StdEncoding.EncodeToString(v) + "\n")
+		case time.Time:
+			writeLDIFLine(w, name, v.UTC().Format("20060102150405.0Z"))
+		default:
+			return fmt.Errorf("%s: %w: %T", name, ErrUnsupportedValue, value)
+		}
+	}
+	return nil
+}
+
+// writeLDIFLine writes an attribute-value line, encoding the value in base64
+// if it isn't a safe string.
+func writeLDIFLine(w *bufio.Writer, name, value string) {
+	if ldifSafe(value) {
+		w.WriteString(name + ": " + value + "\n")
+	} else {
+		w.WriteString(name + ":: " + base64.StdEncoding.EncodeToString([]byte(value)) + "\n")
+	}
+}
+
+// ldifSafe reports whether the value can be written as an LDIF SAFE-STRING.
+func ldifSafe(value string) bool {
+	if value == "" {
+		return true
+	}
+	switch value[0] {
+	case ' ', ':', '<':
+		return false
+	}
+	if value[len(value)-1] == ' ' {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c == 0 || c == '\n' || c == '\r' || c > 127 {
+			return false
+		}
+	}
+	return true
+}
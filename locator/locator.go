@@ -0,0 +1,321 @@
+// Package locator discovers the domain controllers and global catalog servers
+// of an Active Directory domain by querying the SRV records that they
+// register in DNS.
+//
+// Servers are returned in the order in which they should be tried: servers
+// in the preferred site first, then servers of the domain as a whole, each
+// group ordered by priority and weight as described by RFC 2782. Try calls a
+// function with each server in turn until one of them succeeds:
+//
+//	l := &locator.Locator{Site: "Default-First-Site-Name"}
+//	err := l.Try(ctx, "example.com", locator.DC, func(s locator.Server) error {
+//		obj, err = client.Open(s.Path("DC=example,DC=com"))
+//		return err
+//	})
+package locator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrNoServers is returned when no servers of the requested kind could be
+// found for a domain.
+var ErrNoServers = errors.New("no servers found")
+
+// Resolver looks up DNS SRV records. It is satisfied by *net.Resolver and may
+// be replaced by a stand-in for testing or to direct queries to a particular
+// DNS server.
+//
+// The locator calls LookupSRV with an empty service and protocol and the
+// fully qualified name of the record.
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (cname string, addrs []*net.SRV, err error)
+}
+
+// Service identifies the kind of server to locate.
+type Service int
+
+// Services.
+const (
+	// DC locates the LDAP servers of the domain controllers of a domain.
+	DC Service = iota
+
+	// GC locates the global catalog servers of a forest.
+	GC
+
+	// PDC locates the domain controller that holds the primary domain
+	// controller emulator role of a domain. It has no site-specific records.
+	PDC
+
+	// KDC locates the Kerberos key distribution centers of a domain.
+	KDC
+)
+
+var serviceNames = map[Service]string{
+	DC:  "dc",
+	GC:  "gc",
+	PDC: "pdc",
+	KDC: "kdc",
+}
+
+// String returns the name of the service.
+func (s Service) String() string {
+	if name, ok := serviceNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("Service(%d)", int(s))
+}
+
+// Record returns the name of the SRV record that is registered for the
+// service in the given domain. If site is not empty, the name of the
+// site-specific record is returned instead. The PDC service has no
+// site-specific record, so site is ignored for it.
+func (s Service) Record(domain, site string) string {
+	domain = strings.TrimSuffix(domain, ".")
+	prefix, kind := "_ldap._tcp.", "dc"
+	switch s {
+	case GC:
+		kind = "gc"
+	case PDC:
+		return "_ldap._tcp.pdc._msdcs." + domain
+	case KDC:
+		prefix = "_kerberos._tcp."
+	}
+	if site != "" {
+		return prefix + site + "._sites." + kind + "._msdcs." + domain
+	}
+	return prefix + kind + "._msdcs." + domain
+}
+
+// Server is a server that was found by the locator.
+type Server struct {
+	// Host is the DNS name of the server, without a trailing dot.
+	Host string
+
+	// Port is the port of the service.
+	Port uint16
+
+	// Priority and Weight are taken from the SRV record.
+	Priority uint16
+	Weight   uint16
+
+	// InSite is true if the server was registered in the preferred site.
+	InSite bool
+
+	// Service is the service that the server was located for.
+	Service Service
+}
+
+// Address returns the host and port of the server in the form "host:port".
+func (s Server) Address() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(int(s.Port)))
+}
+
+// Path returns an ADsPath that binds to the object with the given
+// distinguished name on the server. The GC scheme is used for global catalog
+// servers and the LDAP scheme for all others. The port is omitted when it is
+// the default for the scheme.
+func (s Server) Path(dn string) string {
+	scheme, port := "LDAP", uint16(389)
+	if s.Service == GC {
+		scheme, port = "GC", 3268
+	}
+	host := s.Host
+	if s.Port != 0 && s.Port != port {
+		host = s.Address()
+	}
+	if dn == "" {
+		return scheme + "://" + host
+	}
+	return scheme + "://" + host + "/" + dn
+}
+
+// Locator finds servers by querying DNS. The zero value uses the default
+// resolver and has no site affinity.
+type Locator struct {
+	// Resolver is used to look up SRV records. If it is nil,
+	// net.DefaultResolver is used.
+	Resolver Resolver
+
+	// Site is the name of the Active Directory site in which servers are
+	// preferred, usually the site of the computer. If it is empty, servers are
+	// chosen from the domain as a whole.
+	Site string
+}
+
+// Locate returns the servers that provide the service for the given domain,
+// in the order in which they should be tried. For the GC service, domain
+// should be the name of the forest root domain.
+//
+// If a site is set, the servers registered in that site come first. The
+// domain-wide records are always consulted as well, so that servers in other
+// sites are available for failover. Within each group, servers are ordered by
+// priority, and servers of equal priority are shuffled in proportion to their
+// weight, so the order differs between calls.
+//
+// Locate returns ErrNoServers if no servers are registered. A failed lookup
+// of the site-specific records is ignored if the domain-wide records can be
+// read.
+func (l *Locator) Locate(ctx context.Context, domain string, svc Service) ([]Server, error) {
+	if domain == "" {
+		return nil, errors.New("locator: no domain specified")
+	}
+
+	var servers []Server
+	seen := make(map[string]bool)
+	add := func(addrs []*net.SRV, inSite bool) {
+		for _, srv := range order(addrs) {
+			host := strings.ToLower(strings.TrimSuffix(srv.Target, "."))
+			// A target of "." means that the service is decidedly not
+			// available in the domain.
+			if host == "" {
+				continue
+			}
+			key := host + ":" + strconv.Itoa(int(srv.Port))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			servers = append(servers, Server{
+				Host:     host,
+				Port:     srv.Port,
+				Priority: srv.Priority,
+				Weight:   srv.Weight,
+				InSite:   inSite,
+				Service:  svc,
+			})
+		}
+	}
+
+	var siteErr error
+	if l.Site != "" && svc != PDC {
+		addrs, err := l.lookup(ctx, svc.Record(domain, l.Site))
+		if err != nil {
+			siteErr = err
+		}
+		add(addrs, true)
+	}
+
+	addrs, err := l.lookup(ctx, svc.Record(domain, ""))
+	if err != nil {
+		if len(servers) > 0 {
+			return servers, nil
+		}
+		return nil, err
+	}
+	add(addrs, false)
+
+	if len(servers) == 0 {
+		if siteErr != nil {
+			return nil, siteErr
+		}
+		return nil, fmt.Errorf("locator: %s in %s: %w", svc, domain, ErrNoServers)
+	}
+	return servers, nil
+}
+
+// Try locates the servers that provide the service for the given domain and
+// calls fn with each of them in turn until fn returns nil. It returns nil if
+// fn succeeded for one of the servers, otherwise it returns an error that
+// joins the errors returned for each server.
+//
+// Try stops without trying the remaining servers if ctx is done.
+func (l *Locator) Try(ctx context.Context, domain string, svc Service, fn func(Server) error) error {
+	servers, err := l.Locate(ctx, domain, svc)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, s := range servers {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		err := fn(s)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", s.Host, err))
+	}
+	return errors.Join(errs...)
+}
+
+// lookup returns the targets of the SRV record with the given name. A name
+// that does not exist is not an error.
+func (l *Locator) lookup(ctx context.Context, name string) ([]*net.SRV, error) {
+	r := l.Resolver
+	if r == nil {
+		r = net.DefaultResolver
+	}
+	_, addrs, err := r.LookupSRV(ctx, "", "", name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("locator: %w", err)
+	}
+	return addrs, nil
+}
+
+// order returns the targets in the order in which they should be tried, as
+// described by RFC 2782: by ascending priority, and within each priority by
+// a random selection in which the chance of a target being chosen next is
+// proportional to its weight.
+func order(addrs []*net.SRV) []*net.SRV {
+	sorted := append([]*net.SRV(nil), addrs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority < sorted[j].Priority
+	})
+
+	ordered := make([]*net.SRV, 0, len(sorted))
+	for i := 0; i < len(sorted); {
+		j := i
+		for j < len(sorted) && sorted[j].Priority == sorted[i].Priority {
+			j++
+		}
+		ordered = append(ordered, shuffle(sorted[i:j])...)
+		i = j
+	}
+	return ordered
+}
+
+// shuffle orders targets of equal priority by weighted random selection.
+// Targets with a weight of zero have a small chance of being selected before
+// the others, as recommended by RFC 2782.
+func shuffle(addrs []*net.SRV) []*net.SRV {
+	remaining := append([]*net.SRV(nil), addrs...)
+	sort.SliceStable(remaining, func(i, j int) bool {
+		return remaining[i].Weight == 0 && remaining[j].Weight != 0
+	})
+	result := make([]*net.SRV, 0, len(addrs))
+	for len(remaining) > 0 {
+		total := 0
+		for _, srv := range remaining {
+			total += int(srv.Weight)
+		}
+		pick := 0
+		if total > 0 {
+			n := rand.IntN(total + 1)
+			for i, srv := range remaining {
+				n -= int(srv.Weight)
+				if n <= 0 {
+					pick = i
+					break
+				}
+			}
+		} else {
+			pick = rand.IntN(len(remaining))
+		}
+		result = append(result, remaining[pick])
+		remaining = append(remaining[:pick], remaining[pick+1:]...)
+	}
+	return result
+}
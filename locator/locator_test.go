@@ -0,0 +1,201 @@
+package locator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+)
+
+// fakeResolver answers SRV lookups from a fixed set of records. Names that
+// are not in the set are reported as not found, and names in errs fail.
+type fakeResolver struct {
+	records map[string][]*net.SRV
+	errs    map[string]error
+	queried []string
+}
+
+func (r *fakeResolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	r.queried = append(r.queried, name)
+	if err, ok := r.errs[name]; ok {
+		return "", nil, err
+	}
+	addrs, ok := r.records[name]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return name, addrs, nil
+}
+
+func hosts(servers []Server) []string {
+	var h []string
+	for _, s := range servers {
+		h = append(h, s.Host)
+	}
+	return h
+}
+
+func TestLocateSiteFirst(t *testing.T) {
+	r := &fakeResolver{records: map[string][]*net.SRV{
+		"_ldap._tcp.Branch._sites.dc._msdcs.example.com": {
+			{Target: "dc2.example.com.", Port: 389},
+		},
+		"_ldap._tcp.dc._msdcs.example.com": {
+			{Target: "dc1.example.com.", Port: 389},
+			{Target: "DC2.example.com.", Port: 389},
+			{Target: "dc3.example.com.", Port: 389, Priority: 10},
+		},
+	}}
+	l := &Locator{Resolver: r, Site: "Branch"}
+	servers, err := l.Locate(context.Background(), "example.com", DC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// dc2 is registered both in the site and in the domain, and is only
+	// returned once, as a server in the site.
+	if got, want := hosts(servers), []string{"dc2.example.com", "dc1.example.com", "dc3.example.com"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("hosts = %v, want %v", got, want)
+	}
+	if !servers[0].InSite || servers[1].InSite || servers[2].InSite {
+		t.Errorf("InSite = %v, %v, %v", servers[0].InSite, servers[1].InSite, servers[2].InSite)
+	}
+	for _, s := range servers {
+		if s.Service != DC {
+			t.Errorf("%s: Service = %v", s.Host, s.Service)
+		}
+	}
+}
+
+func TestLocateDedupByPort(t *testing.T) {
+	r := &fakeResolver{records: map[string][]*net.SRV{
+		"_ldap._tcp.gc._msdcs.example.com": {
+			{Target: "dc1.example.com.", Port: 3268},
+			{Target: "dc1.example.com", Port: 3268},
+			{Target: "dc1.example.com.", Port: 3269},
+		},
+	}}
+	servers, err := (&Locator{Resolver: r}).Locate(context.Background(), "example.com.", GC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(servers) != 2 {
+		t.Fatalf("servers = %+v, want 2", servers)
+	}
+}
+
+func TestLocateNoService(t *testing.T) {
+	r := &fakeResolver{records: map[string][]*net.SRV{
+		"_kerberos._tcp.dc._msdcs.example.com": {{Target: "."}},
+	}}
+	_, err := (&Locator{Resolver: r}).Locate(context.Background(), "example.com", KDC)
+	if !errors.Is(err, ErrNoServers) {
+		t.Fatalf("err = %v, want ErrNoServers", err)
+	}
+}
+
+func TestLocateNotFound(t *testing.T) {
+	r := &fakeResolver{records: map[string][]*net.SRV{
+		"_ldap._tcp.dc._msdcs.example.com": {{Target: "dc1.example.com.", Port: 389}},
+	}}
+	// The site has no records, which is not an error.
+	servers, err := (&Locator{Resolver: r, Site: "Empty"}).Locate(context.Background(), "example.com", DC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := hosts(servers); !reflect.DeepEqual(got, []string{"dc1.example.com"}) {
+		t.Errorf("hosts = %v", got)
+	}
+
+	// Neither record exists.
+	_, err = (&Locator{Resolver: &fakeResolver{}, Site: "Empty"}).Locate(context.Background(), "example.com", DC)
+	if !errors.Is(err, ErrNoServers) {
+		t.Errorf("err = %v, want ErrNoServers", err)
+	}
+}
+
+func TestLocateLookupError(t *testing.T) {
+	failure := errors.New("server failure")
+	site := "_ldap._tcp.Branch._sites.dc._msdcs.example.com"
+	domain := "_ldap._tcp.dc._msdcs.example.com"
+
+	// A failed site lookup is ignored when the domain records can be read.
+	r := &fakeResolver{
+		records: map[string][]*net.SRV{domain: {{Target: "dc1.example.com.", Port: 389}}},
+		errs:    map[string]error{site: failure},
+	}
+	if _, err := (&Locator{Resolver: r, Site: "Branch"}).Locate(context.Background(), "example.com", DC); err != nil {
+		t.Errorf("site failure: %v", err)
+	}
+
+	// A failed domain lookup is reported when there are no site servers.
+	r = &fakeResolver{errs: map[string]error{domain: failure}}
+	if _, err := (&Locator{Resolver: r}).Locate(context.Background(), "example.com", DC); !errors.Is(err, failure) {
+		t.Errorf("domain failure: err = %v", err)
+	}
+}
+
+func TestLocatePDCIgnoresSite(t *testing.T) {
+	r := &fakeResolver{records: map[string][]*net.SRV{
+		"_ldap._tcp.pdc._msdcs.example.com": {{Target: "dc1.example.com.", Port: 389}},
+	}}
+	servers, err := (&Locator{Resolver: r, Site: "Branch"}).Locate(context.Background(), "example.com", PDC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"_ldap._tcp.pdc._msdcs.example.com"}; !reflect.DeepEqual(r.queried, want) {
+		t.Errorf("queried = %v, want %v", r.queried, want)
+	}
+	if len(servers) != 1 || servers[0].InSite {
+		t.Errorf("servers = %+v", servers)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	for _, tt := range []struct {
+		svc        Service
+		site, want string
+	}{
+		{DC, "", "_ldap._tcp.dc._msdcs.example.com"},
+		{DC, "Branch", "_ldap._tcp.Branch._sites.dc._msdcs.example.com"},
+		{GC, "Branch", "_ldap._tcp.Branch._sites.gc._msdcs.example.com"},
+		{PDC, "Branch", "_ldap._tcp.pdc._msdcs.example.com"},
+		{KDC, "", "_kerberos._tcp.dc._msdcs.example.com"},
+	} {
+		if got := tt.svc.Record("example.com.", tt.site); got != tt.want {
+			t.Errorf("%v.Record(%q) = %q, want %q", tt.svc, tt.site, got, tt.want)
+		}
+	}
+}
+
+func TestOrder(t *testing.T) {
+	addrs := []*net.SRV{
+		{Target: "c", Priority: 20, Weight: 5},
+		{Target: "a1", Priority: 0, Weight: 50},
+		{Target: "b", Priority: 10},
+		{Target: "a2", Priority: 0, Weight: 50},
+		{Target: "a3", Priority: 0},
+	}
+	for i := 0; i < 100; i++ {
+		ordered := order(addrs)
+		if len(ordered) != len(addrs) {
+			t.Fatalf("order returned %d targets, want %d", len(ordered), len(addrs))
+		}
+		seen := make(map[string]bool)
+		for j, srv := range ordered {
+			seen[srv.Target] = true
+			if j > 0 && srv.Priority < ordered[j-1].Priority {
+				t.Fatalf("priority %d follows %d", srv.Priority, ordered[j-1].Priority)
+			}
+		}
+		if len(seen) != len(addrs) {
+			t.Fatalf("order dropped or duplicated targets: %v", seen)
+		}
+		if ordered[3].Target != "b" || ordered[4].Target != "c" {
+			t.Fatalf("lower priorities out of order: %s, %s", ordered[3].Target, ordered[4].Target)
+		}
+	}
+	if addrs[0].Target != "c" {
+		t.Error("order modified its argument")
+	}
+}
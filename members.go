@@ -1,6 +1,7 @@
 package adsi
 
 import (
+	"iter"
 	"sync"
 	"unsafe"
 
@@ -13,8 +14,10 @@ import (
 
 // Members provides access to group membership.
 type Members struct {
-	m     sync.RWMutex
-	iface *api.IADsMembers
+	m      sync.RWMutex
+	iface  *api.IADsMembers
+	client *Client
+	user   string
 }
 
 // NewMembers returns a membership that manages the given COM
@@ -60,9 +63,32 @@ func (m *Members) Iter() (iter *ObjectIter, err error) {
 	}
 	iface := (*ole.IEnumVARIANT)(unsafe.Pointer(idispatch))
 	iter = NewObjectIter(iface)
+	iter.client = m.client
+	iter.user = m.user
 	return
 }
 
+// All returns a sequence of the members of the group. The underlying iterator
+// is closed when the sequence ends.
+//
+// If the members cannot be enumerated, the error is yielded with a nil object
+// and the sequence ends.
+//
+// Each yielded object consumes resources until it is closed. It is the
+// caller's responsibilty to call Close on each object when it is no longer
+// needed.
+func (m *Members) All() iter.Seq2[*Object, error] {
+	return func(yield func(*Object, error) bool) {
+		members, err := m.Iter()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer members.Close()
+		members.All()(yield)
+	}
+}
+
 // Filter returns the current filter of the membership.
 func (m *Members) Filter() (filter []string, err error) {
 	m.m.Lock()
@@ -0,0 +1,345 @@
+package adsi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/go-adsi/adsi/adspath"
+)
+
+// Searcher searches a directory. It is implemented by Client.
+type Searcher interface {
+	Search(path string, q Query) ([]SearchResult, error)
+}
+
+// resolverAttrs holds the names of the attributes that are read from the
+// objects found by a NameResolver.
+var resolverAttrs = []string{
+	"distinguishedName",
+	"sAMAccountName",
+	"displayName",
+	"objectSid",
+	"objectGUID",
+	"userPrincipalName",
+}
+
+// NameResolver converts names between formats by searching the directory.
+// Unlike NameTranslator it works with any Searcher, and conversions between
+// distinguished names, domain names and canonical names are made without a
+// lookup, as with ConvertName, except that the components of a canonical name
+// are looked up so that the actual distinguished name of the object is
+// returned.
+//
+// The supported input types are NameType1779, NameTypeCanonical,
+// NameTypeCanonicalEx, NameTypeNT4, NameTypeDisplay, NameTypeGUID,
+// NameTypeUserPrincipalName, NameTypeServicePrincipalName, NameTypeSID and
+// NameTypeDNSDomain. The supported output types are the same except for
+// NameTypeServicePrincipalName.
+type NameResolver struct {
+	// Searcher is used to search the directory.
+	Searcher Searcher
+
+	// Base is the ADsPath below which objects are searched. The root of the
+	// global catalog covers every domain of the forest.
+	Base string
+
+	// Partitions is the ADsPath of the CN=Partitions container of the
+	// configuration naming context, which maps NetBIOS domain names to
+	// domains. It is required for NT4 names.
+	Partitions string
+}
+
+// NameResolver returns a NameResolver that searches the global catalog of the
+// forest with the client.
+func (c *Client) NameResolver() (*NameResolver, error) {
+	gc, err := c.GlobalCatalogPath()
+	if err != nil {
+		return nil, err
+	}
+	dse, err := c.RootDSE()
+	if err != nil {
+		return nil, err
+	}
+	// The global catalog holds the configuration naming context too.
+	partitions, err := pathFromDN(gc, "CN=Partitions,"+dse.ConfigurationNamingContext)
+	if err != nil {
+		return nil, err
+	}
+	return &NameResolver{Searcher: c, Base: gc, Partitions: partitions}, nil
+}
+
+// Resolve converts a name from one format to another.
+//
+// An error matching ErrNameNotFound is returned if no object has the name,
+// and one matching ErrNameNotUnique if more than one does.
+func (r *NameResolver) Resolve(name string, from, to NameType) (string, error) {
+	if syntacticName(from) && syntacticName(to) && from != NameTypeCanonical && from != NameTypeCanonicalEx {
+		return ConvertName(name, from, to)
+	}
+
+	var (
+		dn     string
+		result *SearchResult
+		err    error
+	)
+	switch from {
+	case NameType1779:
+		dn = name
+	case NameTypeDNSDomain:
+		dn, err = domainDNFromDNS(name)
+	case NameTypeCanonical, NameTypeCanonicalEx:
+		dn, err = r.resolveCanonical(name)
+	default:
+		result, err = r.find(name, from)
+	}
+	if err != nil {
+		return "", err
+	}
+	if result == nil && !syntacticName(to) {
+		if result, err = r.findOne(name, fmt.Sprintf("(distinguishedName=%s)", EscapeFilter(dn))); err != nil {
+			return "", err
+		}
+	}
+	if result != nil {
+		dn = result.String("distinguishedName")
+	}
+
+	switch to {
+	case NameType1779, NameTypeCanonical, NameTypeCanonicalEx, NameTypeDNSDomain:
+		return dnToName(dn, to)
+	case NameTypeNT4:
+		netbios, err := r.netbiosName(domainDN(dn))
+		if err != nil {
+			return "", err
+		}
+		return netbios + `\` + result.String("sAMAccountName"), nil
+	case NameTypeDisplay:
+		return requiredValue(name, result.String("displayName"), "display name")
+	case NameTypeUserPrincipalName:
+		return requiredValue(name, result.String("userPrincipalName"), "user principal name")
+	case NameTypeGUID:
+		b := result.Bytes("objectGUID")
+		if len(b) != 16 {
+			return "", fmt.Errorf("%w: %q has no GUID", ErrNameNotFound, name)
+		}
+		return "{" + adspath.WindowsGUID(uuid.UUID(b)).String() + "}", nil
+	case NameTypeSID:
+		return FormatSID(result.Bytes("objectSid"))
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedNameType, to)
+	}
+}
+
+// find searches for the object with a name of a type that requires a lookup.
+func (r *NameResolver) find(name string, t NameType) (*SearchResult, error) {
+	switch t {
+	case NameTypeNT4:
+		return r.findNT4(name)
+	case NameTypeDisplay:
+		return r.findOne(name, fmt.Sprintf("(displayName=%s)", EscapeFilter(name)))
+	case NameTypeUserPrincipalName:
+		return r.findOne(name, fmt.Sprintf("(userPrincipalName=%s)", EscapeFilter(name)))
+	case NameTypeServicePrincipalName:
+		return r.findOne(name, fmt.Sprintf("(servicePrincipalName=%s)", EscapeFilter(name)))
+	case NameTypeGUID:
+		guid, err := uuid.Parse(strings.Trim(name, "{}"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a GUID", ErrInvalidName, name)
+		}
+		b := adspath.WindowsGUID(guid)
+		return r.findOne(name, fmt.Sprintf("(objectGUID=%s)", escapeFilterBytes(b[:])))
+	case NameTypeSID:
+		sid, err := ParseSID(name)
+		if err != nil {
+			return nil, err
+		}
+		v := escapeFilterBytes(sid)
+		return r.findOne(name, fmt.Sprintf("(|(objectSid=%s)(sIDHistory=%s))", v, v))
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedNameType, t)
+	}
+}
+
+// findNT4 searches for the object with a name of the form DOMAIN\name. The
+// name of a domain itself is of the form DOMAIN\.
+func (r *NameResolver) findNT4(name string) (*SearchResult, error) {
+	netbios, sam, ok := strings.Cut(name, `\`)
+	if !ok || netbios == "" {
+		return nil, fmt.Errorf("%w: %q is not an NT4 name", ErrInvalidName, name)
+	}
+	if r.Partitions == "" {
+		return nil, fmt.Errorf("%w: NT4 names require the partitions container", ErrUnsupportedNameType)
+	}
+	ref, err := r.crossRef(name, fmt.Sprintf("(nETBIOSName=%s)", EscapeFilter(netbios)))
+	if err != nil {
+		return nil, err
+	}
+	domain := ref.String("nCName")
+	if sam == "" {
+		return r.findOne(name, fmt.Sprintf("(distinguishedName=%s)", EscapeFilter(domain)))
+	}
+
+	results, err := r.Searcher.Search(r.Base, Query{
+		Filter:     fmt.Sprintf("(sAMAccountName=%s)", EscapeFilter(sam)),
+		Attributes: resolverAttrs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var matches []SearchResult
+	for _, result := range results {
+		if adspath.EqualDN(domainDN(result.String("distinguishedName")), domain) {
+			matches = append(matches, result)
+		}
+	}
+	return uniqueResult(name, matches)
+}
+
+// resolveCanonical returns the distinguished name of the object with the
+// given canonical or extended canonical name by looking up each of its
+// components in turn.
+func (r *NameResolver) resolveCanonical(name string) (string, error) {
+	parts := splitCanonical(name)
+	dn, err := domainDNFromDNS(parts[0])
+	if err != nil {
+		return "", err
+	}
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		path, err := pathFromDN(r.Base, dn)
+		if err != nil {
+			return "", err
+		}
+		results, err := r.Searcher.Search(path, Query{
+			Filter:     fmt.Sprintf("(name=%s)", EscapeFilter(part)),
+			Attributes: []string{"distinguishedName"},
+			Scope:      ScopeOneLevel,
+		})
+		if err != nil {
+			return "", err
+		}
+		result, err := uniqueResult(name, results)
+		if err != nil {
+			return "", err
+		}
+		dn = result.String("distinguishedName")
+	}
+	return dn, nil
+}
+
+// netbiosName returns the NetBIOS name of the domain with the given
+// distinguished name.
+func (r *NameResolver) netbiosName(domain string) (string, error) {
+	if r.Partitions == "" {
+		return "", fmt.Errorf("%w: NT4 names require the partitions container", ErrUnsupportedNameType)
+	}
+	ref, err := r.crossRef(domain, fmt.Sprintf("(nCName=%s)", EscapeFilter(domain)))
+	if err != nil {
+		return "", err
+	}
+	return ref.String("nETBIOSName"), nil
+}
+
+// crossRef searches the partitions container for the cross reference of a
+// domain that matches the filter.
+func (r *NameResolver) crossRef(name, filter string) (*SearchResult, error) {
+	results, err := r.Searcher.Search(r.Partitions, Query{
+		Filter:     fmt.Sprintf("(&(objectClass=crossRef)(nETBIOSName=*)%s)", filter),
+		Attributes: []string{"nCName", "nETBIOSName"},
+		Scope:      ScopeOneLevel,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uniqueResult(name, results)
+}
+
+// findOne searches for the single object that matches the filter.
+func (r *NameResolver) findOne(name, filter string) (*SearchResult, error) {
+	results, err := r.Searcher.Search(r.Base, Query{Filter: filter, Attributes: resolverAttrs})
+	if err != nil {
+		return nil, err
+	}
+	return uniqueResult(name, results)
+}
+
+// uniqueResult returns the only result, or an error if there is not exactly
+// one.
+func uniqueResult(name string, results []SearchResult) (*SearchResult, error) {
+	switch len(results) {
+	case 0:
+		return nil, fmt.Errorf("%w: %q", ErrNameNotFound, name)
+	case 1:
+		return &results[0], nil
+	default:
+		return nil, fmt.Errorf("%w: %q matches %d objects", ErrNameNotUnique, name, len(results))
+	}
+}
+
+// requiredValue returns the value, or an error if it is empty.
+func requiredValue(name, value, kind string) (string, error) {
+	if value == "" {
+		return "", fmt.Errorf("%w: %q has no %s", ErrNameNotFound, name, kind)
+	}
+	return value, nil
+}
+
+// escapeFilterBytes escapes a binary value for use in an LDAP search filter.
+func escapeFilterBytes(b []byte) string {
+	var s strings.Builder
+	for _, c := range b {
+		fmt.Fprintf(&s, "\\%02x", c)
+	}
+	return s.String()
+}
+
+// LDAPPath returns the LDAP ADsPath of the principal with the given WinNT
+// ADsPath, such as "LDAP://example.com/CN=Jeff Smith,CN=Users,DC=example,DC=com"
+// for "WinNT://EXAMPLE/jsmith,user". The path binds to the DNS name of the
+// domain that holds the principal.
+func (r *NameResolver) LDAPPath(winntPath string) (string, error) {
+	p, err := adspath.Parse(winntPath)
+	if err != nil {
+		return "", err
+	}
+	name, ok := p.NT4Name()
+	if !ok {
+		return "", fmt.Errorf("%w: %s is not a WinNT path to a principal", ErrInvalidName, winntPath)
+	}
+	dn, err := r.Resolve(name, NameTypeNT4, NameType1779)
+	if err != nil {
+		return "", err
+	}
+	domain, err := ConvertName(dn, NameType1779, NameTypeDNSDomain)
+	if err != nil {
+		return "", err
+	}
+	return adspath.FromDN(adspath.LDAP, domain, dn).String(), nil
+}
+
+// WinNTPath returns the WinNT ADsPath of the principal with the given LDAP or
+// GC ADsPath, such as "WinNT://EXAMPLE/jsmith" for
+// "LDAP://CN=Jeff Smith,CN=Users,DC=example,DC=com". The path has no class
+// suffix.
+func (r *NameResolver) WinNTPath(ldapPath string) (string, error) {
+	p, err := adspath.Parse(ldapPath)
+	if err != nil {
+		return "", err
+	}
+	if p.Scheme != adspath.LDAP && p.Scheme != adspath.GC || p.Path == "" {
+		return "", fmt.Errorf("%w: %s is not an LDAP path to an object", ErrInvalidName, ldapPath)
+	}
+	name, err := r.Resolve(p.DN(), NameType1779, NameTypeNT4)
+	if err != nil {
+		return "", err
+	}
+	w, err := adspath.FromNT4(name, "")
+	if err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
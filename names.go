@@ -0,0 +1,347 @@
+package adsi
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// wellKnownContainers holds the lower case names of the containers that are
+// created below the root of every domain and are named with CN rather than
+// OU.
+var wellKnownContainers = map[string]bool{
+	"builtin":                            true,
+	"computers":                          true,
+	"foreignsecurityprincipals":          true,
+	"keys":                               true,
+	"lostandfound":                       true,
+	"managed service accounts":           true,
+	"microsoft exchange system objects":  true,
+	"ntds quotas":                        true,
+	"program data":                       true,
+	"system":                             true,
+	"tpm devices":                        true,
+	"users":                              true,
+	"infrastructure":                     true,
+	"deleted objects":                    true,
+	"configuration":                      true,
+	"schema":                             true,
+	"partitions":                         true,
+	"forestdnszones":                     true,
+	"domaindnszones":                     true,
+	"microsoft exchange security groups": true,
+}
+
+// syntacticName reports whether names of the given type can be converted
+// without consulting the directory.
+func syntacticName(t NameType) bool {
+	switch t {
+	case NameType1779, NameTypeCanonical, NameTypeCanonicalEx, NameTypeDNSDomain:
+		return true
+	default:
+		return false
+	}
+}
+
+// ConvertName converts a name between the RFC 1779 distinguished name,
+// canonical, extended canonical and DNS domain forms without consulting the
+// directory. The from and to types are NameType1779,
+// NameTypeCanonical, NameTypeCanonicalEx or
+// NameTypeDNSDomain.
+//
+// For example, "CN=Smith\, Jeff,OU=Sales,DC=example,DC=com" is converted to
+// "example.com/Sales/Smith, Jeff" in canonical form and to
+// "example.com/Sales\nSmith, Jeff" in extended canonical form. Slashes in
+// canonical names are escaped with a backslash.
+//
+// Canonical names do not record whether a component is an organizational
+// unit or a container. When converting them to distinguished names, the last
+// component and the well-known containers of a domain, such as Users and
+// System, along with their descendants, are named with CN and the remaining
+// components with OU. Use a NameResolver to find the actual name of an
+// object.
+func ConvertName(name string, from, to NameType) (string, error) {
+	if !syntacticName(from) || !syntacticName(to) {
+		return "", fmt.Errorf("%w: conversion from %s names to %s names requires a directory lookup", ErrUnsupportedNameType, from, to)
+	}
+	dn, err := nameToDN(name, from)
+	if err != nil {
+		return "", err
+	}
+	return dnToName(dn, to)
+}
+
+// nameToDN converts a name of a syntactic type into a distinguished name.
+func nameToDN(name string, t NameType) (string, error) {
+	switch t {
+	case NameType1779:
+		if _, err := parseDN(name); err != nil {
+			return "", err
+		}
+		return name, nil
+	case NameTypeDNSDomain:
+		return domainDNFromDNS(name)
+	default:
+		return canonicalToDN(name)
+	}
+}
+
+// dnToName converts a distinguished name into a name of a syntactic type.
+func dnToName(dn string, t NameType) (string, error) {
+	switch t {
+	case NameType1779:
+		return dn, nil
+	case NameTypeDNSDomain:
+		domain := dnsDomain(domainDN(dn))
+		if domain == "" {
+			return "", fmt.Errorf("%w: %q is not within a domain", ErrInvalidName, dn)
+		}
+		return domain, nil
+	default:
+		return dnToCanonical(dn, t == NameTypeCanonicalEx)
+	}
+}
+
+// dnToCanonical converts a distinguished name into canonical form. In the
+// extended form the last separator is a newline rather than a slash.
+func dnToCanonical(dn string, extended bool) (string, error) {
+	rdns, err := parseDN(dn)
+	if err != nil {
+		return "", err
+	}
+	// The trailing DC components make up the domain.
+	i := len(rdns)
+	for i > 0 && strings.EqualFold(rdns[i-1].Type, "DC") {
+		i--
+	}
+	if i == len(rdns) {
+		return "", fmt.Errorf("%w: %q is not within a domain", ErrInvalidName, dn)
+	}
+	labels := make([]string, 0, len(rdns)-i)
+	for _, r := range rdns[i:] {
+		labels = append(labels, r.Value)
+	}
+
+	var b strings.Builder
+	b.WriteString(escapeCanonical(strings.Join(labels, ".")))
+	for j := i - 1; j >= 0; j-- {
+		if extended && j == 0 {
+			b.WriteByte('\n')
+		} else {
+			b.WriteByte('/')
+		}
+		b.WriteString(escapeCanonical(rdns[j].Value))
+	}
+	if i == 0 {
+		// The canonical name of a domain ends with a separator.
+		if extended {
+			b.WriteByte('\n')
+		} else {
+			b.WriteByte('/')
+		}
+	}
+	return b.String(), nil
+}
+
+// canonicalToDN converts a name in canonical or extended canonical form into
+// a distinguished name.
+func canonicalToDN(name string) (string, error) {
+	parts := splitCanonical(name)
+	if len(parts) == 0 || parts[0] == "" {
+		return "", fmt.Errorf("%w: %q is not a canonical name", ErrInvalidName, name)
+	}
+	domain, err := domainDNFromDNS(parts[0])
+	if err != nil {
+		return "", err
+	}
+	parts = parts[1:]
+	if len(parts) > 0 && parts[len(parts)-1] == "" {
+		// The canonical name of a domain ends with a separator.
+		parts = parts[:len(parts)-1]
+	}
+
+	rdns := make([]string, len(parts))
+	container := false
+	for i, part := range parts {
+		if part == "" {
+			return "", fmt.Errorf("%w: %q has an empty component", ErrInvalidName, name)
+		}
+		if i == 0 && wellKnownContainers[strings.ToLower(part)] {
+			container = true
+		}
+		typ := "OU"
+		if container || i == len(parts)-1 {
+			typ = "CN"
+		}
+		rdns[len(parts)-1-i] = typ + "=" + escapeRDNValue(part)
+	}
+	return strings.Join(append(rdns, domain), ","), nil
+}
+
+// splitCanonical splits a canonical or extended canonical name into its
+// unescaped components.
+func splitCanonical(name string) []string {
+	var (
+		parts []string
+		b     strings.Builder
+	)
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; c {
+		case '\\':
+			if i+1 < len(name) {
+				i++
+				b.WriteByte(name[i])
+			}
+		case '/', '\n':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return append(parts, b.String())
+}
+
+// escapeCanonical escapes the slashes and backslashes of a component of a
+// canonical name.
+func escapeCanonical(s string) string {
+	if !strings.ContainsAny(s, `/\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '/' || s[i] == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// domainDNFromDNS returns the distinguished name of the domain with the given
+// DNS name.
+func domainDNFromDNS(domain string) (string, error) {
+	domain = strings.TrimSuffix(domain, ".")
+	if domain == "" {
+		return "", fmt.Errorf("%w: empty domain name", ErrInvalidName)
+	}
+	labels := strings.Split(domain, ".")
+	for i, label := range labels {
+		if label == "" {
+			return "", fmt.Errorf("%w: %q is not a domain name", ErrInvalidName, domain)
+		}
+		labels[i] = "DC=" + escapeRDNValue(label)
+	}
+	return strings.Join(labels, ","), nil
+}
+
+// relativeName is a component of a distinguished name.
+type relativeName struct {
+	Type  string
+	Value string
+}
+
+// parseDN splits a distinguished name into its components and unescapes
+// their values, as described in RFC 4514. Multi-valued components, such as
+// "CN=a+SN=b", have no canonical form and are rejected.
+func parseDN(dn string) ([]relativeName, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %q %s", ErrInvalidName, dn, reason)
+	}
+	if strings.TrimSpace(dn) == "" {
+		return nil, invalid("is empty")
+	}
+	var (
+		rdns  []relativeName
+		value []byte
+		typ   string
+		inTyp = true
+		start = 0
+	)
+	for i := 0; i <= len(dn); i++ {
+		if inTyp {
+			if i == len(dn) {
+				return nil, invalid("has a component without a value")
+			}
+			if dn[i] == '=' {
+				typ = strings.TrimSpace(dn[start:i])
+				if typ == "" {
+					return nil, invalid("has a component without a type")
+				}
+				inTyp = false
+				value = value[:0]
+			}
+			continue
+		}
+		if i == len(dn) || dn[i] == ',' || dn[i] == ';' {
+			rdns = append(rdns, relativeName{Type: typ, Value: trimValue(string(value))})
+			inTyp, start = true, i+1
+			continue
+		}
+		switch c := dn[i]; c {
+		case '\\':
+			if i+1 >= len(dn) {
+				return nil, invalid("ends with an escape character")
+			}
+			if i+2 < len(dn) && isHex(dn[i+1]) && isHex(dn[i+2]) {
+				b, _ := hex.DecodeString(dn[i+1 : i+3])
+				value = append(value, b[0])
+				i += 2
+			} else {
+				i++
+				value = append(value, dn[i])
+			}
+			// Escaped spaces are retained when the value is trimmed.
+			if value[len(value)-1] == ' ' {
+				value[len(value)-1] = 0xFF
+			}
+		case '+':
+			return nil, invalid("has a multi-valued component")
+		default:
+			value = append(value, c)
+		}
+	}
+	return rdns, nil
+}
+
+// trimValue trims the unescaped spaces around a value, which were marked with
+// 0xFF if they were escaped, and restores the escaped ones.
+func trimValue(s string) string {
+	s = strings.TrimSpace(s)
+	if strings.IndexByte(s, 0xFF) < 0 {
+		return s
+	}
+	b := []byte(s)
+	for i := range b {
+		if b[i] == 0xFF {
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// escapeRDNValue escapes a value for use in a distinguished name, as
+// described in RFC 4514.
+func escapeRDNValue(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ',' || c == '+' || c == '"' || c == '\\' || c == '<' || c == '>' || c == ';' || c == '=':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '#' && i == 0, c == ' ' && (i == 0 || i == len(s)-1):
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7F:
+			fmt.Fprintf(&b, "\\%02X", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package adsi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConvertName(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		from, to NameType
+		want     string
+	}{
+		{`CN=Smith\, Jeff,OU=Sales,DC=example,DC=com`, NameType1779, NameTypeCanonical, `example.com/Sales/Smith, Jeff`},
+		{`CN=Jeff Smith,OU=Sales,DC=example,DC=com`, NameType1779, NameTypeCanonicalEx, "example.com/Sales\nJeff Smith"},
+		{"example.com/Sales\nJeff Smith", NameTypeCanonicalEx, NameType1779, `CN=Jeff Smith,OU=Sales,DC=example,DC=com`},
+		{`CN=Jeff Smith,OU=Sales,DC=example,DC=com`, NameType1779, NameTypeDNSDomain, `example.com`},
+
+		// Slashes are escaped in canonical names.
+		{`CN=a/b,OU=Sales,DC=example,DC=com`, NameType1779, NameTypeCanonical, `example.com/Sales/a\/b`},
+		{`example.com/Sales/a\/b`, NameTypeCanonical, NameType1779, `CN=a/b,OU=Sales,DC=example,DC=com`},
+
+		// Special characters are escaped in distinguished names.
+		{`example.com/Sales/Smith, Jeff`, NameTypeCanonical, NameType1779, `CN=Smith\, Jeff,OU=Sales,DC=example,DC=com`},
+		{`CN=a\+b,DC=example,DC=com`, NameType1779, NameTypeCanonical, `example.com/a+b`},
+		{`example.com/a+b`, NameTypeCanonical, NameType1779, `CN=a\+b,DC=example,DC=com`},
+		{`CN=\#1,DC=example,DC=com`, NameType1779, NameTypeCanonical, `example.com/#1`},
+		{`example.com/#1`, NameTypeCanonical, NameType1779, `CN=\#1,DC=example,DC=com`},
+		{`example.com/a#1`, NameTypeCanonical, NameType1779, `CN=a#1,DC=example,DC=com`},
+		{`CN=\ x,DC=example,DC=com`, NameType1779, NameTypeCanonical, `example.com/ x`},
+		{`example.com/ x`, NameTypeCanonical, NameType1779, `CN=\ x,DC=example,DC=com`},
+		{`CN=\4A\65ff,DC=example,DC=com`, NameType1779, NameTypeCanonical, `example.com/Jeff`},
+
+		// Well-known containers and their descendants are named with CN.
+		{`example.com/Users/jsmith`, NameTypeCanonical, NameType1779, `CN=jsmith,CN=Users,DC=example,DC=com`},
+		{`example.com/System/Policies/x`, NameTypeCanonical, NameType1779, `CN=x,CN=Policies,CN=System,DC=example,DC=com`},
+
+		// Domains.
+		{`DC=example,DC=com`, NameType1779, NameTypeCanonical, `example.com/`},
+		{`DC=example,DC=com`, NameType1779, NameTypeCanonicalEx, "example.com\n"},
+		{`DC=example,DC=com`, NameType1779, NameTypeDNSDomain, `example.com`},
+		{`example.com/`, NameTypeCanonical, NameType1779, `DC=example,DC=com`},
+		{`example.com`, NameTypeCanonical, NameType1779, `DC=example,DC=com`},
+		{`example.com.`, NameTypeDNSDomain, NameType1779, `DC=example,DC=com`},
+		{`sub.example.com`, NameTypeDNSDomain, NameTypeCanonical, `sub.example.com/`},
+	} {
+		got, err := ConvertName(tt.name, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("ConvertName(%q, %s, %s): %v", tt.name, tt.from, tt.to, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ConvertName(%q, %s, %s) = %q, want %q", tt.name, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestConvertNameError(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		from, to NameType
+		err      error
+	}{
+		{`CN=a+SN=b,DC=example,DC=com`, NameType1779, NameTypeCanonical, ErrInvalidName},
+		{`CN=a+SN=b,DC=example,DC=com`, NameType1779, NameType1779, ErrInvalidName},
+		{`CN=Jeff Smith,OU=Sales`, NameType1779, NameTypeCanonical, ErrInvalidName},
+		{`CN=Jeff Smith,OU=Sales`, NameType1779, NameTypeDNSDomain, ErrInvalidName},
+		{`CN=Jeff\`, NameType1779, NameTypeCanonical, ErrInvalidName},
+		{`Jeff Smith`, NameType1779, NameTypeCanonical, ErrInvalidName},
+		{``, NameType1779, NameTypeCanonical, ErrInvalidName},
+		{`example..com`, NameTypeDNSDomain, NameType1779, ErrInvalidName},
+		{`example.com//Jeff`, NameTypeCanonical, NameType1779, ErrInvalidName},
+		{`/Sales`, NameTypeCanonical, NameType1779, ErrInvalidName},
+		{`EXAMPLE\jsmith`, NameTypeNT4, NameType1779, ErrUnsupportedNameType},
+		{`DC=example,DC=com`, NameType1779, NameTypeSID, ErrUnsupportedNameType},
+	} {
+		if _, err := ConvertName(tt.name, tt.from, tt.to); !errors.Is(err, tt.err) {
+			t.Errorf("ConvertName(%q, %s, %s) error = %v, want %v", tt.name, tt.from, tt.to, err, tt.err)
+		}
+	}
+}
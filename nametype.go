@@ -0,0 +1,137 @@
+package adsi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// NameType identifies a format of the names of directory objects. The values
+// other than NameTypeDNSDomain are those of the api.ADS_NAME_TYPE_ENUM
+// enumeration.
+type NameType uint32
+
+// Name types.
+const (
+	// NameType1779 is an RFC 1779 distinguished name, such as
+	// "CN=Jeff Smith,OU=Sales,DC=example,DC=com".
+	NameType1779 = NameType(api.ADS_NAME_TYPE_1779)
+
+	// NameTypeCanonical is a canonical name, such as
+	// "example.com/Sales/Jeff Smith".
+	NameTypeCanonical = NameType(api.ADS_NAME_TYPE_CANONICAL)
+
+	// NameTypeNT4 is a Windows NT 4.0 account name, such as
+	// `EXAMPLE\jsmith`.
+	NameTypeNT4 = NameType(api.ADS_NAME_TYPE_NT4)
+
+	// NameTypeDisplay is the display name of an object.
+	NameTypeDisplay = NameType(api.ADS_NAME_TYPE_DISPLAY)
+
+	// NameTypeDomainSimple is a simple name within a domain, such as
+	// "jsmith@example.com".
+	NameTypeDomainSimple = NameType(api.ADS_NAME_TYPE_DOMAIN_SIMPLE)
+
+	// NameTypeEnterpriseSimple is a simple name within the forest, such as
+	// "jsmith@example.com".
+	NameTypeEnterpriseSimple = NameType(api.ADS_NAME_TYPE_ENTERPRISE_SIMPLE)
+
+	// NameTypeGUID is the GUID of an object in the form
+	// "{4fa050f0-f561-11cf-bdd9-00aa003a77b6}".
+	NameTypeGUID = NameType(api.ADS_NAME_TYPE_GUID)
+
+	// NameTypeUnknown asks the directory to guess the format of a name.
+	NameTypeUnknown = NameType(api.ADS_NAME_TYPE_UNKNOWN)
+
+	// NameTypeUserPrincipalName is a user principal name, such as
+	// "jsmith@example.com".
+	NameTypeUserPrincipalName = NameType(api.ADS_NAME_TYPE_USER_PRINCIPAL_NAME)
+
+	// NameTypeCanonicalEx is an extended canonical name, in which the last
+	// separator is a newline, such as "example.com/Sales\nJeff Smith".
+	NameTypeCanonicalEx = NameType(api.ADS_NAME_TYPE_CANONICAL_EX)
+
+	// NameTypeServicePrincipalName is a service principal name, such as
+	// "HTTP/web.example.com".
+	NameTypeServicePrincipalName = NameType(api.ADS_NAME_TYPE_SERVICE_PRINCIPAL_NAME)
+
+	// NameTypeSID is a security identifier in string form, such as
+	// "S-1-5-21-...". When translating into an object, the SID history of
+	// objects is searched too.
+	NameTypeSID = NameType(api.ADS_NAME_TYPE_SID_OR_SID_HISTORY_NAME)
+
+	// NameTypeDNSDomain is the DNS name of the domain that contains an
+	// object, such as "example.com". It is not supported by the directory
+	// and is only accepted by ConvertName and NameResolver.
+	NameTypeDNSDomain NameType = 13
+)
+
+var nameTypeNames = map[NameType]string{
+	NameType1779:                 "1779",
+	NameTypeCanonical:            "canonical",
+	NameTypeNT4:                  "nt4",
+	NameTypeDisplay:              "display",
+	NameTypeDomainSimple:         "domain-simple",
+	NameTypeEnterpriseSimple:     "enterprise-simple",
+	NameTypeGUID:                 "guid",
+	NameTypeUnknown:              "unknown",
+	NameTypeUserPrincipalName:    "upn",
+	NameTypeCanonicalEx:          "canonical-ex",
+	NameTypeServicePrincipalName: "spn",
+	NameTypeSID:                  "sid",
+	NameTypeDNSDomain:            "dns-domain",
+}
+
+// nameTypeAliases holds additional names accepted by ParseNameType.
+var nameTypeAliases = map[string]NameType{
+	"dn":                     NameType1779,
+	"rfc1779":                NameType1779,
+	"canonicalex":            NameTypeCanonicalEx,
+	"user-principal-name":    NameTypeUserPrincipalName,
+	"service-principal-name": NameTypeServicePrincipalName,
+	"sid-or-sid-history":     NameTypeSID,
+	"domain":                 NameTypeDNSDomain,
+}
+
+// String returns the name of the name type.
+func (t NameType) String() string {
+	if name, ok := nameTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("NameType(%d)", uint32(t))
+}
+
+// ParseNameType returns the name type with the given name, as returned by
+// String. The names "dn" and "rfc1779" are accepted for NameType1779 and
+// "domain" for NameTypeDNSDomain. Case is ignored.
+func ParseNameType(s string) (NameType, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for t, name := range nameTypeNames {
+		if name == s {
+			return t, nil
+		}
+	}
+	if t, ok := nameTypeAliases[s]; ok {
+		return t, nil
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnsupportedNameType, s)
+}
+
+// MarshalText returns the name of the name type.
+func (t NameType) MarshalText() ([]byte, error) {
+	if _, ok := nameTypeNames[t]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedNameType, uint32(t))
+	}
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText parses the name of a name type.
+func (t *NameType) UnmarshalText(text []byte) error {
+	v, err := ParseNameType(string(text))
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
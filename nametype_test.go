@@ -0,0 +1,87 @@
+package adsi
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestNameTypeRoundTrip(t *testing.T) {
+	for typ, name := range nameTypeNames {
+		if got := typ.String(); got != name {
+			t.Errorf("NameType(%d).String() = %q, want %q", uint32(typ), got, name)
+		}
+		parsed, err := ParseNameType(name)
+		if err != nil || parsed != typ {
+			t.Errorf("ParseNameType(%q) = %v, %v, want %v", name, parsed, err, typ)
+		}
+		text, err := typ.MarshalText()
+		if err != nil {
+			t.Errorf("NameType(%d).MarshalText(): %v", uint32(typ), err)
+			continue
+		}
+		var unmarshaled NameType
+		if err := unmarshaled.UnmarshalText(text); err != nil || unmarshaled != typ {
+			t.Errorf("UnmarshalText(%q) = %v, %v, want %v", text, unmarshaled, err, typ)
+		}
+	}
+}
+
+func TestParseNameType(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want NameType
+	}{
+		{"UPN", NameTypeUserPrincipalName},
+		{" nt4 ", NameTypeNT4},
+		{"dn", NameType1779},
+		{"RFC1779", NameType1779},
+		{"canonicalex", NameTypeCanonicalEx},
+		{"user-principal-name", NameTypeUserPrincipalName},
+		{"service-principal-name", NameTypeServicePrincipalName},
+		{"sid-or-sid-history", NameTypeSID},
+		{"domain", NameTypeDNSDomain},
+	} {
+		got, err := ParseNameType(tt.s)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseNameType(%q) = %v, %v, want %v", tt.s, got, err, tt.want)
+		}
+	}
+	for _, s := range []string{"", "ntlm", "1779x", "13"} {
+		if _, err := ParseNameType(s); !errors.Is(err, ErrUnsupportedNameType) {
+			t.Errorf("ParseNameType(%q) error = %v, want ErrUnsupportedNameType", s, err)
+		}
+	}
+}
+
+func TestNameTypeInvalid(t *testing.T) {
+	invalid := NameType(99)
+	if got := invalid.String(); got != "NameType(99)" {
+		t.Errorf("String() = %q, want %q", got, "NameType(99)")
+	}
+	if _, err := invalid.MarshalText(); !errors.Is(err, ErrUnsupportedNameType) {
+		t.Errorf("MarshalText() error = %v, want ErrUnsupportedNameType", err)
+	}
+}
+
+func TestNameTypeJSON(t *testing.T) {
+	type options struct {
+		From NameType `json:"from"`
+		To   NameType `json:"to"`
+	}
+	in := options{From: NameTypeNT4, To: NameTypeCanonicalEx}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), `{"from":"nt4","to":"canonical-ex"}`; got != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+	var out options
+	if err := json.Unmarshal([]byte(`{"from":"NT4","to":"canonicalex"}`), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out != in {
+		t.Errorf("Unmarshal() = %+v, want %+v", out, in)
+	}
+}
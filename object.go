@@ -29,8 +29,17 @@ func NewObject(iface *api.IADs) *Object {
 }
 
 type object struct {
-	m     sync.RWMutex
-	iface *api.IADs
+	m          sync.RWMutex
+	iface      *api.IADs
+	client     *Client
+	user       string
+	changes    []Change
+	conditions []Version
+	undo       []ReverseChange
+
+	// created is true for objects returned by Container.Create that have not
+	// yet been written to the directory with SetInfo.
+	created bool
 }
 
 func (o *object) closed() bool {
@@ -168,6 +177,117 @@ func (o *object) Pull(attrs ...string) (err error) {
 	return
 }
 
+// Refresh discards the property cache of the object and reloads it from the
+// underlying data store. Any values that have been set but not yet committed
+// with SetInfo are lost.
+func (o *object) Refresh() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	return o.iface.GetInfo()
+}
+
+// Attribute holds the values of an attribute that is populated on an object.
+type Attribute struct {
+	// Name is the name of the attribute.
+	Name string
+
+	// Type identifies the syntax of the attribute's values. It is one of the
+	// api.ADSTYPE values.
+	Type uint32
+
+	// Values holds the values of the attribute. Each value is an interface{}
+	// that holds a Go native type that is the best match for the underlying
+	// variant, as returned by Attr.
+	Values []interface{}
+}
+
+// Attributes returns every attribute that is populated in the property cache
+// of the object, along with its values and syntax type. If the property cache
+// is empty it is loaded from the underlying data store first.
+//
+// Constructed and operational attributes are only included if they have been
+// loaded explicitly with Pull.
+//
+// If any attribute contains IUnknown or IDispatch members, it is the
+// caller's responsibility to release them.
+func (o *object) Attributes() (attrs []Attribute, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	return o.attributes()
+}
+
+// attributes returns the attributes that are populated in the property cache.
+// The caller must hold a lock on the object.
+func (o *object) attributes() (attrs []Attribute, err error) {
+	idispatch, err := o.iface.QueryInterface(comutil.GUID(comiid.IADsPropertyList))
+	if err != nil {
+		return
+	}
+	defer idispatch.Release()
+	list := (*api.IADsPropertyList)(unsafe.Pointer(idispatch))
+
+	count, err := list.PropertyCount()
+	if err != nil {
+		return
+	}
+	if count == 0 {
+		if err = o.iface.GetInfo(); err != nil {
+			return
+		}
+	}
+
+	if err = list.Reset(); err != nil {
+		return
+	}
+	for {
+		variant, nextErr := list.Next()
+		if nextErr == api.ErrNoMoreItems {
+			return attrs, nil
+		}
+		if nextErr != nil {
+			releaseAttributes(attrs)
+			return nil, nextErr
+		}
+		attr, entryErr := o.propertyEntry(variant)
+		variant.Clear()
+		if entryErr != nil {
+			releaseAttributes(attrs)
+			return nil, entryErr
+		}
+		attrs = append(attrs, attr)
+	}
+}
+
+// propertyEntry reads the attribute described by the property entry held in
+// the given variant.
+func (o *object) propertyEntry(variant *ole.VARIANT) (attr Attribute, err error) {
+	idispatch := variant.ToIDispatch()
+	if idispatch == nil {
+		return attr, ErrNonDispatchVariant
+	}
+	iresult, err := idispatch.QueryInterface(comutil.GUID(comiid.IADsPropertyEntry))
+	if err != nil {
+		return
+	}
+	defer iresult.Release()
+	entry := (*api.IADsPropertyEntry)(unsafe.Pointer(iresult))
+
+	if attr.Name, err = entry.Name(); err != nil {
+		return
+	}
+	if attr.Type, err = entry.ADsType(); err != nil {
+		return
+	}
+	attr.Values, err = o.Attr(attr.Name)
+	return
+}
+
 // Attr attempts to retrieve the attribute with the given name and
 // return its values as a slice of interfaces. Each value is an interface{}
 // that holds a Go native type that is the best match for the underlying
@@ -501,35 +621,116 @@ func (o *object) AttrGUID(name string) (attr uuid.UUID, err error) {
 
 // PutInt sets the values of an int attribute in the ADSI attribute
 // cache. The value must be commited with SetInfo to be made persistent.
+//
+// The modification is tracked and can be inspected with Changes.
 func (o *object) PutInt(name string, val int) error {
 	o.m.Lock()
 	defer o.m.Unlock()
 	if o.closed() {
 		return ErrClosed
 	}
-	return o.iface.PutInt(name, val)
+	return o.stage(name, replaceValues(val), func() error {
+		return o.iface.PutInt(name, val)
+	})
 }
 
 // PutString sets the values of a string attribute in the ADSI attribute
 // cache. The value must be commited with SetInfo to be made persistent.
+//
+// The modification is tracked and can be inspected with Changes.
 func (o *object) PutString(name string, val string) error {
 	o.m.Lock()
 	defer o.m.Unlock()
 	if o.closed() {
 		return ErrClosed
 	}
-	return o.iface.PutString(name, val)
+	return o.stage(name, replaceValues(val), func() error {
+		return o.iface.PutString(name, val)
+	})
+}
+
+// PutEx modifies the values of an attribute in the ADSI attribute cache. The
+// control code is one of the api.ADS_PROPERTY values and determines whether
+// the values replace, are appended to or are deleted from the existing values
+// of the attribute. The modification must be commited with SetInfo to be made
+// persistent.
+//
+// Each value must be a string, bool, int, int32, uint32, int64, []byte or
+// time.Time.
+//
+// The modification is tracked and can be inspected with Changes.
+func (o *object) PutEx(name string, control uint32, values ...interface{}) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	return o.putEx(name, control, values)
+}
+
+// putEx stages a modification of the named attribute. The caller must hold a
+// lock on the object.
+func (o *object) putEx(name string, control uint32, values []interface{}) error {
+	next := func(current []interface{}) []interface{} {
+		return applyControl(control, current, values)
+	}
+	return o.stage(name, next, func() error {
+		variant, err := newVariantArray(values)
+		if err != nil {
+			return err
+		}
+		defer variant.Clear()
+		return o.iface.PutEx(control, name, variant)
+	})
 }
 
 // SetInfo saves the cached property values of the ADSI object to the underlying
-// directory store.
+// directory store. Attributes that were put with their existing values are
+// not sent. Once the values have been saved the object has no staged
+// modifications.
+//
+// The previous values of the modified attributes are recorded as a reverse
+// change that can be retrieved with Undo. If the object was returned by
+// Container.Create, SetInfo creates it and the reverse change deletes it.
+//
+// If conditions were recorded by PutExIf, the object is checked against them
+// first and a *ConflictError is returned if any of them no longer hold. The
+// staged modifications are retained in that case.
 func (o *object) SetInfo() error {
 	o.m.Lock()
 	defer o.m.Unlock()
 	if o.closed() {
 		return ErrClosed
 	}
-	return o.iface.SetInfo()
+	return o.setInfo()
+}
+
+// setInfo checks the recorded conditions and commits the property cache. The
+// caller must hold a lock on the object.
+func (o *object) setInfo() error {
+	if o.created {
+		return o.create()
+	}
+	for _, expected := range o.conditions {
+		if err := o.checkVersion(expected); err != nil {
+			return err
+		}
+	}
+	e, err := o.writeEvent(WriteSetInfo)
+	if err != nil {
+		return err
+	}
+	e.Changes = o.changes
+	if err := o.client.intercept(e, o.user, o.iface.SetInfo); err != nil {
+		return err
+	}
+	reverse := modifyReverse(e.Path, o.changes)
+	o.changes = nil
+	o.conditions = nil
+	if len(reverse.Attrs) == 0 {
+		return nil
+	}
+	return o.record(reverse)
 }
 
 // ToContainer attempts to acquire a container interface for the object.
@@ -545,6 +746,8 @@ func (o *object) ToContainer() (c *Container, err error) {
 	}
 	iface := (*api.IADsContainer)(unsafe.Pointer(idispatch))
 	c = NewContainer(iface)
+	c.client = o.client
+	c.user = o.user
 	return
 }
 
@@ -561,6 +764,8 @@ func (o *object) ToComputer() (c *Computer, err error) {
 	}
 	iface := (*api.IADsComputer)(unsafe.Pointer(idispatch))
 	c = NewComputer(iface)
+	c.client = o.client
+	c.user = o.user
 	return
 }
 
@@ -577,6 +782,8 @@ func (o *object) ToGroup() (g *Group, err error) {
 	}
 	iface := (*api.IADsGroup)(unsafe.Pointer(idispatch))
 	g = NewGroup(iface)
+	g.client = o.client
+	g.user = o.user
 	return
 }
 
@@ -593,5 +800,7 @@ func (o *object) ToUser() (u *User, err error) {
 	}
 	iface := (*api.IADsUser)(unsafe.Pointer(idispatch))
 	u = NewUser(iface)
+	u.client = o.client
+	u.user = o.user
 	return
 }
@@ -12,6 +12,9 @@ package adsi
 // If the requested ADSI object does not implement the IADs interface an error
 // is returned.
 //
+// The returned object is not associated with a client, so operations that
+// require one return ErrNoClient.
+//
 // The returned object consumes resources until it is closed. It is the
 // caller's responsibilty to call Close on the returned object when it is no
 // longer needed.
@@ -21,8 +24,13 @@ func Open(path string) (obj *Object, err error) {
 		return nil, err
 	}
 	defer c.Close()
-
-	return c.Open(path)
+	obj, err = c.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	// The client is closed before returning, so the object must not use it.
+	obj.client = nil
+	return obj, nil
 }
 
 // OpenSC opens an ADSI object with the given path. Most users will use Open
@@ -41,6 +49,9 @@ func Open(path string) (obj *Object, err error) {
 // If the requested ADSI object does not implement the IADs interface an error
 // is returned.
 //
+// The returned object is not associated with a client, so operations that
+// require one return ErrNoClient.
+//
 // The returned object consumes resources until it is closed. It is the
 // caller's responsibilty to call Close on the returned object when it is no
 // longer needed.
@@ -50,7 +61,13 @@ func OpenSC(path, user, password string, flags uint32) (obj *Object, err error)
 		return nil, err
 	}
 	defer c.Close()
-	return c.OpenSC(path, user, password, flags)
+	obj, err = c.OpenSC(path, user, password, flags)
+	if err != nil {
+		return nil, err
+	}
+	// The client is closed before returning, so the object must not use it.
+	obj.client = nil
+	return obj, nil
 }
 
 // OpenContainer opens an ADSI container with the given path. It creates an
@@ -65,6 +82,9 @@ func OpenSC(path, user, password string, flags uint32) (obj *Object, err error)
 // If the returned directory object does not implement the IADsContainer
 // interface an error is returned.
 //
+// The returned container is not associated with a client, so operations that
+// require one, such as MoveHere, return ErrNoClient.
+//
 // The returned container consumes resources until it is closed. It is the
 // caller's responsibilty to call Close on the returned container when it is no
 // longer needed.
@@ -74,7 +94,13 @@ func OpenContainer(path string) (container *Container, err error) {
 		return nil, err
 	}
 	defer c.Close()
-	return c.OpenContainer(path)
+	container, err = c.OpenContainer(path)
+	if err != nil {
+		return nil, err
+	}
+	// The client is closed before returning, so the container must not use it.
+	container.client = nil
+	return container, nil
 }
 
 // OpenContainerSC opens an ADSI container with the given path. Most users will
@@ -93,6 +119,9 @@ func OpenContainer(path string) (container *Container, err error) {
 // If the returned directory object does not implement the IADsContainer
 // interface an error is returned.
 //
+// The returned container is not associated with a client, so operations that
+// require one, such as MoveHere, return ErrNoClient.
+//
 // The returned container consumes resources until it is closed. It is the
 // caller's responsibilty to call Close on the returned container when it is no
 // longer needed.
@@ -102,7 +131,13 @@ func OpenContainerSC(path, user, password string, flags uint32) (container *Cont
 		return nil, err
 	}
 	defer c.Close()
-	return c.OpenContainerSC(path, user, password, flags)
+	container, err = c.OpenContainerSC(path, user, password, flags)
+	if err != nil {
+		return nil, err
+	}
+	// The client is closed before returning, so the container must not use it.
+	container.client = nil
+	return container, nil
 }
 
 // OpenComputer opens an ADSI computer with the given path. The existing
@@ -117,6 +152,9 @@ func OpenContainerSC(path, user, password string, flags uint32) (container *Cont
 // If the returned directory object does not implement the IADsComputer
 // interface an error is returned.
 //
+// The returned computer is not associated with a client, so operations that
+// require one return ErrNoClient.
+//
 // The returned computer consumes resources until it is closed. It is the
 // caller's responsibilty to call Close on the returned computer when it is no
 // longer needed.
@@ -126,7 +164,13 @@ func OpenComputer(path string) (computer *Computer, err error) {
 		return nil, err
 	}
 	defer c.Close()
-	return c.OpenComputer(path)
+	computer, err = c.OpenComputer(path)
+	if err != nil {
+		return nil, err
+	}
+	// The client is closed before returning, so the computer must not use it.
+	computer.client = nil
+	return computer, nil
 }
 
 // OpenComputerSC opens an ADSI computer with the given path. Most users will
@@ -145,6 +189,9 @@ func OpenComputer(path string) (computer *Computer, err error) {
 // If the returned directory object does not implement the IADsComputer
 // interface an error is returned.
 //
+// The returned computer is not associated with a client, so operations that
+// require one return ErrNoClient.
+//
 // The returned computer consumes resources until it is closed. It is the
 // caller's responsibilty to call Close on the returned computer when it is no
 // longer needed.
@@ -154,5 +201,11 @@ func OpenComputerSC(path, user, password string, flags uint32) (computer *Comput
 		return nil, err
 	}
 	defer c.Close()
-	return c.OpenComputerSC(path, user, password, flags)
+	computer, err = c.OpenComputerSC(path, user, password, flags)
+	if err != nil {
+		return nil, err
+	}
+	// The client is closed before returning, so the computer must not use it.
+	computer.client = nil
+	return computer, nil
 }
@@ -0,0 +1,171 @@
+package adsi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-adsi/adsi/adspath"
+)
+
+// WritePolicy restricts the directory writes that can be made through a
+// Client. The policy is enforced by this package before any request is sent
+// to the server, in addition to the permissions granted by the directory.
+//
+// Each list that is empty imposes no restriction. Names and distinguished
+// names are compared without regard to case.
+type WritePolicy struct {
+	// ReadOnly denies all writes.
+	ReadOnly bool
+
+	// Subtrees holds the distinguished names of the subtrees in which objects
+	// may be written, such as "OU=Staff,DC=example,DC=com". An object may be
+	// written if it is the root of one of the subtrees or is below it. Moves
+	// are only permitted if both the source and the destination are within
+	// the subtrees. Objects that are not addressed by LDAP paths cannot be
+	// written when Subtrees is set.
+	Subtrees []string
+
+	// Classes holds the schema classes of the objects that may be written,
+	// such as "user" or "group". It is compared with the most specific class
+	// of the object, as returned by Class.
+	Classes []string
+
+	// Attributes holds the attributes that may be modified. Group membership
+	// operations modify the "member" attribute and password operations modify
+	// the "unicodePwd" attribute.
+	Attributes []string
+}
+
+// PolicyError is returned when a write is denied by the write policy of a
+// client. It matches ErrPolicyViolation with errors.Is.
+type PolicyError struct {
+	// Op is the kind of write that was denied.
+	Op WriteOp
+
+	// Path is the ADsPath of the object that was to be written.
+	Path string
+
+	// Reason describes the restriction that denied the write.
+	Reason string
+}
+
+// Error returns a description of the violation.
+func (e *PolicyError) Error() string {
+	return fmt.Sprintf("%v: %s of %s: %s", ErrPolicyViolation, e.Op, e.Path, e.Reason)
+}
+
+// Is reports whether target is ErrPolicyViolation.
+func (e *PolicyError) Is(target error) bool {
+	return target == ErrPolicyViolation
+}
+
+// WritePolicy returns the write policy of the client, or nil if none has been
+// set.
+func (c *Client) WritePolicy() *WritePolicy {
+	c.m.RLock()
+	defer c.m.RUnlock()
+	if c.policy == nil {
+		return nil
+	}
+	p := c.policy.clone()
+	return &p
+}
+
+// SetWritePolicy sets the policy that restricts the directory writes made
+// through objects opened by the client. A nil policy removes all
+// restrictions. The policy is copied, so later modifications of p have no
+// effect.
+//
+// Writes that are denied by the policy return a *PolicyError and are not
+// reported to the write interceptor of the client. Objects that were created
+// with NewObject rather than opened through the client are not subject to
+// the policy.
+func (c *Client) SetWritePolicy(p *WritePolicy) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	if p == nil {
+		c.policy = nil
+		return
+	}
+	clone := p.clone()
+	c.policy = &clone
+}
+
+func (p *WritePolicy) clone() WritePolicy {
+	return WritePolicy{
+		ReadOnly:   p.ReadOnly,
+		Subtrees:   append([]string(nil), p.Subtrees...),
+		Classes:    append([]string(nil), p.Classes...),
+		Attributes: append([]string(nil), p.Attributes...),
+	}
+}
+
+// check returns a *PolicyError if the policy denies the given write.
+func (p *WritePolicy) check(e WriteEvent) error {
+	deny := func(format string, args ...interface{}) error {
+		return &PolicyError{Op: e.Op, Path: e.Path, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	if p.ReadOnly {
+		return deny("writes are not permitted")
+	}
+
+	if len(p.Subtrees) > 0 {
+		if !p.inSubtrees(e.Path) {
+			return deny("object is outside of the permitted subtrees")
+		}
+		if e.Op == WriteMove && !p.inSubtrees(e.Target) {
+			return deny("destination %s is outside of the permitted subtrees", e.Target)
+		}
+	}
+
+	if len(p.Classes) > 0 && !containsFold(p.Classes, e.Class) {
+		return deny("class %q is not permitted", e.Class)
+	}
+
+	if len(p.Attributes) > 0 {
+		var attrs []string
+		switch e.Op {
+		case WriteAddMember, WriteRemoveMember:
+			attrs = []string{"member"}
+		case WriteSetPassword, WriteChangePassword:
+			attrs = []string{"unicodePwd"}
+		default:
+			for _, c := range e.Changes {
+				attrs = append(attrs, c.Name)
+			}
+		}
+		for _, attr := range attrs {
+			if !containsFold(p.Attributes, attr) {
+				return deny("attribute %q is not permitted", attr)
+			}
+		}
+	}
+	return nil
+}
+
+// inSubtrees reports whether the object at the given ADsPath is within one of
+// the permitted subtrees.
+func (p *WritePolicy) inSubtrees(path string) bool {
+	dn, err := dnFromPath(path)
+	if err != nil {
+		return false
+	}
+	for _, subtree := range p.Subtrees {
+		// An empty subtree would be the root of the directory.
+		if strings.TrimSpace(subtree) != "" && adspath.WithinDN(dn, subtree) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether values contains s without regard to case.
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
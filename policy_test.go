@@ -0,0 +1,69 @@
+package adsi
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWritePolicySubtrees(t *testing.T) {
+	p := &WritePolicy{Subtrees: []string{"OU=Staff,DC=example,DC=com"}}
+	for _, tt := range []struct {
+		name  string
+		event WriteEvent
+		allow bool
+	}{
+		{"root", WriteEvent{Op: WriteSetInfo, Path: "LDAP://OU=Staff,DC=example,DC=com"}, true},
+		{"child", WriteEvent{Op: WriteSetInfo, Path: "LDAP://srv/CN=Jeff Smith,OU=Staff,DC=example,DC=com"}, true},
+		{"case", WriteEvent{Op: WriteSetInfo, Path: "LDAP://cn=jeff smith,ou=STAFF, dc=Example,dc=COM"}, true},
+		{"escaped slash", WriteEvent{Op: WriteSetInfo, Path: `LDAP://CN=a\/b,OU=Staff,DC=example,DC=com`}, true},
+		{"question mark", WriteEvent{Op: WriteSetInfo, Path: "LDAP://CN=Who?,OU=Staff,DC=example,DC=com"}, true},
+		{"escaped comma", WriteEvent{Op: WriteSetInfo, Path: `LDAP://CN=evil\,OU=Staff,DC=example,DC=com`}, false},
+		{"hex escaped comma", WriteEvent{Op: WriteSetInfo, Path: `LDAP://CN=evil\2COU=Staff,DC=example,DC=com`}, false},
+		{"sibling", WriteEvent{Op: WriteSetInfo, Path: "LDAP://CN=x,OU=OtherStaff,DC=example,DC=com"}, false},
+		{"suffix of value", WriteEvent{Op: WriteSetInfo, Path: "LDAP://CN=x,OU=Temp Staff,DC=example,DC=com"}, false},
+		{"parent", WriteEvent{Op: WriteSetInfo, Path: "LDAP://DC=example,DC=com"}, false},
+		{"winnt", WriteEvent{Op: WriteSetInfo, Path: "WinNT://EXAMPLE/jsmith"}, false},
+		{"move within", WriteEvent{Op: WriteMove, Path: "LDAP://CN=x,OU=Staff,DC=example,DC=com", Target: "LDAP://OU=Sales,OU=Staff,DC=example,DC=com"}, true},
+		{"move out", WriteEvent{Op: WriteMove, Path: "LDAP://CN=x,OU=Staff,DC=example,DC=com", Target: "LDAP://OU=Sales,DC=example,DC=com"}, false},
+		{"move out through escaped comma", WriteEvent{Op: WriteMove, Path: "LDAP://CN=x,OU=Staff,DC=example,DC=com", Target: `LDAP://OU=a\,OU=Staff,DC=example,DC=com`}, false},
+		{"move in", WriteEvent{Op: WriteMove, Path: "LDAP://CN=x,OU=Sales,DC=example,DC=com", Target: "LDAP://OU=Staff,DC=example,DC=com"}, false},
+	} {
+		err := p.check(tt.event)
+		if tt.allow && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if !tt.allow && !errors.Is(err, ErrPolicyViolation) {
+			t.Errorf("%s: error = %v, want a policy violation", tt.name, err)
+		}
+	}
+}
+
+func TestWritePolicyRestrictions(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		policy WritePolicy
+		event  WriteEvent
+		allow  bool
+	}{
+		{"read only", WritePolicy{ReadOnly: true}, WriteEvent{Op: WriteSetInfo}, false},
+		{"no restrictions", WritePolicy{}, WriteEvent{Op: WriteDelete, Path: "WinNT://EXAMPLE/jsmith"}, true},
+		{"empty subtree", WritePolicy{Subtrees: []string{""}}, WriteEvent{Op: WriteSetInfo, Path: "LDAP://DC=example,DC=com"}, false},
+		{"class", WritePolicy{Classes: []string{"user"}}, WriteEvent{Op: WriteSetInfo, Class: "User"}, true},
+		{"other class", WritePolicy{Classes: []string{"user"}}, WriteEvent{Op: WriteSetInfo, Class: "group"}, false},
+		{"attribute", WritePolicy{Attributes: []string{"description"}}, WriteEvent{Op: WriteSetInfo, Changes: []Change{{Name: "Description"}}}, true},
+		{"other attribute", WritePolicy{Attributes: []string{"description"}}, WriteEvent{Op: WriteSetInfo, Changes: []Change{{Name: "description"}, {Name: "mail"}}}, false},
+		{"member", WritePolicy{Attributes: []string{"member"}}, WriteEvent{Op: WriteAddMember}, true},
+		{"password", WritePolicy{Attributes: []string{"member"}}, WriteEvent{Op: WriteSetPassword}, false},
+	} {
+		err := tt.policy.check(tt.event)
+		if tt.allow && err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if !tt.allow {
+			var pe *PolicyError
+			if !errors.As(err, &pe) || !errors.Is(err, ErrPolicyViolation) {
+				t.Errorf("%s: error = %v, want a *PolicyError", tt.name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package adsi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/spn"
+)
+
+// Length limits of computer names.
+const (
+	maxComputerNameLength = 15
+	maxDNSLabelLength     = 63
+	maxDNSNameLength      = 255
+)
+
+// Schema identifiers of the rights that are needed to join a computer to a
+// pre-staged account.
+var (
+	rightResetPassword       = uuid.MustParse("00299570-246d-11d0-a768-00aa006e0529")
+	rightWriteDNSHostName    = uuid.MustParse("72e39547-7b18-11d1-adef-00c04fd8d5cd")
+	rightWriteSPN            = uuid.MustParse("f3a64788-5306-11d1-a9c5-0000f8031d42")
+	rightAccountRestrictions = uuid.MustParse("4c164200-20c0-11d0-a768-00aa006e0529")
+)
+
+// ComputerAccount describes a computer account that is pre-staged by
+// Container.ProvisionComputer.
+type ComputerAccount struct {
+	// Name is the NetBIOS name of the computer. It must be a valid DNS label
+	// of at most 15 characters that is not entirely numeric.
+	Name string
+
+	// DNSDomain is the DNS name of the domain that the computer joins, such as
+	// "example.com". It is used to form dNSHostName and the service principal
+	// names.
+	DNSDomain string
+
+	// Password is the initial password of the account. If it is empty, the
+	// lower case name of the computer truncated to 14 characters is used,
+	// which is the password expected when joining a pre-staged or reset
+	// account without one.
+	Password string
+
+	// Description is written to the description attribute of new accounts,
+	// if it is not empty.
+	Description string
+
+	// JoinPrincipal is the ADsPath of a user or group that is granted the
+	// rights needed to join the computer to the account. If it is empty no
+	// rights are granted.
+	JoinPrincipal string
+
+	// Reset permits an existing account with the same name in the container
+	// to be reused. Its password is reset instead of returning an error.
+	Reset bool
+}
+
+// ProvisionResult describes the outcome of Container.ProvisionComputer.
+type ProvisionResult struct {
+	// Path is the ADsPath of the computer account.
+	Path string
+
+	// SAMAccountName is the sAMAccountName of the account, which is the upper
+	// case name of the computer followed by a dollar sign.
+	SAMAccountName string
+
+	// DNSHostName is the fully qualified DNS name of the computer.
+	DNSHostName string
+
+	// Created is true if the account was created and false if an existing
+	// account was reset.
+	Created bool
+
+	// Password is the password that was set on the account.
+	Password string
+}
+
+// ProvisionComputer pre-stages a computer account in the container, so that
+// the computer can join the domain without creating its own account.
+//
+// A new account is created with the WORKSTATION_TRUST_ACCOUNT
+// userAccountControl flag, a sAMAccountName of the upper case name followed
+// by a dollar sign, dNSHostName and the HOST and RestrictedKrbHost service
+// principal names for both the NetBIOS and the DNS name of the computer. The
+// initial password is then set on it and the account is enabled. If either
+// step fails, the new account is deleted again.
+//
+// If an account with the same name already exists in the container, its
+// password is reset if acct.Reset is true and an error matching
+// ErrAccountExists is returned otherwise. An error matching ErrAccountExists
+// is also returned if the sAMAccountName is in use elsewhere in the domain.
+// Names that are too long or contain invalid characters are rejected with an
+// error matching ErrInvalidComputerName.
+//
+// If acct.JoinPrincipal is set, the principal is granted the rights to reset
+// the password, to write the account restrictions and to make validated
+// writes to dNSHostName and servicePrincipalName on the account. Granting
+// them requires a container that was opened by a Client.
+//
+// ProvisionComputer is only supported by the LDAP provider.
+func (c *Container) ProvisionComputer(acct ComputerAccount) (*ProvisionResult, error) {
+	if err := validateComputerName(acct.Name, acct.DNSDomain); err != nil {
+		return nil, err
+	}
+	if acct.JoinPrincipal != "" && c.client == nil {
+		return nil, ErrNoClient
+	}
+
+	name := strings.ToUpper(acct.Name)
+	result := &ProvisionResult{
+		SAMAccountName: name + "$",
+		DNSHostName:    strings.ToLower(acct.Name) + "." + strings.ToLower(strings.TrimSuffix(acct.DNSDomain, ".")),
+		Password:       acct.Password,
+	}
+	if result.Password == "" {
+		result.Password = defaultComputerPassword(acct.Name)
+	}
+
+	rdn := "CN=" + name
+	obj, err := c.Object("computer", rdn)
+	switch {
+	case err == nil:
+		defer obj.Close()
+		if !acct.Reset {
+			return nil, fmt.Errorf("%w: %s", ErrAccountExists, rdn)
+		}
+		if err := setComputerPassword(obj, result.Password); err != nil {
+			return nil, err
+		}
+	case api.IsHresult(err, api.E_DS_NO_SUCH_OBJECT), api.IsHresult(err, api.E_ADS_UNKNOWN_OBJECT):
+		if obj, err = c.createComputer(rdn, acct, result); err != nil {
+			return nil, err
+		}
+		defer obj.Close()
+		result.Created = true
+	default:
+		return nil, err
+	}
+
+	if result.Path, err = obj.Path(); err != nil {
+		return nil, err
+	}
+	if acct.JoinPrincipal != "" {
+		trustee, err := c.client.objectSid(acct.JoinPrincipal)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", acct.JoinPrincipal, err)
+		}
+		if err := obj.GrantAccess(JoinAccess(trustee)...); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// createComputer creates a computer account with the given relative name and
+// sets its password. If the password can't be set or the account can't be
+// enabled, the account is deleted again.
+func (c *Container) createComputer(rdn string, acct ComputerAccount, result *ProvisionResult) (*Object, error) {
+	obj, err := c.Create("computer", rdn)
+	if err != nil {
+		return nil, err
+	}
+	spns := []interface{}{
+		spn.New("HOST", strings.ToUpper(acct.Name)).String(),
+		spn.New("HOST", result.DNSHostName).String(),
+		spn.New("RestrictedKrbHost", strings.ToUpper(acct.Name)).String(),
+		spn.New("RestrictedKrbHost", result.DNSHostName).String(),
+	}
+	// The account is created disabled and without requiring a password,
+	// which is set once the account exists. It is only enabled once the
+	// password has been set, so that a failure in between does not leave an
+	// enabled account without a password in the directory.
+	puts := []func() error{
+		func() error { return obj.PutString("sAMAccountName", result.SAMAccountName) },
+		func() error {
+			return obj.PutInt("userAccountControl", api.ADS_UF_WORKSTATION_TRUST_ACCOUNT|api.ADS_UF_PASSWD_NOTREQD|api.ADS_UF_ACCOUNTDISABLE)
+		},
+		func() error { return obj.PutString("dNSHostName", result.DNSHostName) },
+		func() error { return obj.PutEx("servicePrincipalName", api.ADS_PROPERTY_UPDATE, spns...) },
+	}
+	if acct.Description != "" {
+		puts = append(puts, func() error { return obj.PutString("description", acct.Description) })
+	}
+	for _, put := range puts {
+		if err := put(); err != nil {
+			obj.Close()
+			return nil, err
+		}
+	}
+	if err := obj.SetInfo(); err != nil {
+		obj.Close()
+		if accountExists(err) {
+			return nil, fmt.Errorf("%w: %s: %v", ErrAccountExists, result.SAMAccountName, err)
+		}
+		return nil, err
+	}
+
+	// From here on a failure would leave a disabled account behind that
+	// blocks a retry, so the account is deleted again.
+	abandon := func(err error) (*Object, error) {
+		if derr := obj.Delete(); derr != nil {
+			path, _ := obj.Path()
+			err = fmt.Errorf("%w (the account %s could not be deleted: %v)", err, path, derr)
+		}
+		obj.Close()
+		return nil, err
+	}
+	if err := setComputerPassword(obj, result.Password); err != nil {
+		return abandon(err)
+	}
+	if err := obj.PutInt("userAccountControl", api.ADS_UF_WORKSTATION_TRUST_ACCOUNT); err != nil {
+		return abandon(err)
+	}
+	if err := obj.SetInfo(); err != nil {
+		return abandon(err)
+	}
+	return obj, nil
+}
+
+// setComputerPassword sets the password of the computer account.
+func setComputerPassword(obj *Object, password string) error {
+	u, err := obj.ToUser()
+	if err != nil {
+		return err
+	}
+	defer u.Close()
+	return u.SetPassword(password)
+}
+
+// objectSid returns the objectSid of the object with the given path.
+func (c *Client) objectSid(path string) ([]byte, error) {
+	obj, err := c.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	obj.m.Lock()
+	defer obj.m.Unlock()
+	if obj.closed() {
+		return nil, ErrClosed
+	}
+	if err := obj.Pull("objectSid"); err != nil {
+		return nil, err
+	}
+	return obj.AttrBytes("objectSid")
+}
+
+// JoinAccess returns the access that a principal with the given objectSid
+// needs on a pre-staged computer account in order to join a computer to it.
+func JoinAccess(trustee []byte) []ObjectAccess {
+	return []ObjectAccess{
+		{Trustee: trustee, Mask: api.ADS_RIGHT_DS_CONTROL_ACCESS, ObjectType: rightResetPassword},
+		{Trustee: trustee, Mask: api.ADS_RIGHT_DS_WRITE_PROP, ObjectType: rightAccountRestrictions},
+		{Trustee: trustee, Mask: api.ADS_RIGHT_DS_SELF, ObjectType: rightWriteDNSHostName},
+		{Trustee: trustee, Mask: api.ADS_RIGHT_DS_SELF, ObjectType: rightWriteSPN},
+	}
+}
+
+// validateComputerName returns an error matching ErrInvalidComputerName if
+// the name cannot be used as both the NetBIOS name and the first label of the
+// DNS name of a computer in the given domain.
+func validateComputerName(name, domain string) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("%w: %q %s", ErrInvalidComputerName, name, reason)
+	}
+	switch {
+	case name == "":
+		return invalid("is empty")
+	case len(name) > maxComputerNameLength:
+		return invalid(fmt.Sprintf("is longer than %d characters", maxComputerNameLength))
+	case name[0] == '-' || name[len(name)-1] == '-':
+		return invalid("starts or ends with a hyphen")
+	}
+	numeric := true
+	for _, r := range name {
+		switch {
+		case r >= '0' && r <= '9':
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '-':
+			numeric = false
+		default:
+			return invalid(fmt.Sprintf("contains the invalid character %q", r))
+		}
+	}
+	if numeric {
+		return invalid("is entirely numeric")
+	}
+
+	domain = strings.TrimSuffix(domain, ".")
+	if domain == "" {
+		return invalid("has no DNS domain")
+	}
+	if n := len(name) + 1 + len(domain); n > maxDNSNameLength {
+		return invalid(fmt.Sprintf("forms a DNS name of %d characters", n))
+	}
+	for _, label := range strings.Split(domain, ".") {
+		if label == "" || len(label) > maxDNSLabelLength {
+			return fmt.Errorf("%w: invalid DNS domain %q", ErrInvalidComputerName, domain)
+		}
+	}
+	return nil
+}
+
+// defaultComputerPassword returns the password that a computer expects on a
+// pre-staged account that was created or reset without one.
+func defaultComputerPassword(name string) string {
+	name = strings.ToLower(name)
+	if len(name) > 14 {
+		name = name[:14]
+	}
+	return name
+}
+
+// accountExists reports whether err indicates that an account could not be
+// created because its name is already in use.
+func accountExists(err error) bool {
+	return api.IsHresult(err, api.E_USER_EXISTS) ||
+		api.IsHresult(err, api.E_DS_OBJECT_ALREADY_EXISTS) ||
+		api.IsHresult(err, api.E_ADS_OBJECT_EXISTS)
+}
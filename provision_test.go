@@ -0,0 +1,52 @@
+package adsi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateComputerName(t *testing.T) {
+	for _, tt := range []struct {
+		name, domain string
+		ok           bool
+	}{
+		{"WS01", "example.com", true},
+		{"ws-01", "example.com.", true},
+		{"A23456789012345", "example.com", true},
+		{"", "example.com", false},
+		{"A234567890123456", "example.com", false},
+		{"-WS01", "example.com", false},
+		{"WS01-", "example.com", false},
+		{"12345", "example.com", false},
+		{"WS_01", "example.com", false},
+		{"WS.01", "example.com", false},
+		{"WS01", "", false},
+		{"WS01", ".", false},
+		{"WS01", "example..com", false},
+		{"WS01", strings.Repeat("a", 64) + ".com", false},
+		{"WS01", strings.Repeat("abcdefg.", 31) + "com", false},
+	} {
+		err := validateComputerName(tt.name, tt.domain)
+		switch {
+		case tt.ok && err != nil:
+			t.Errorf("validateComputerName(%q, %q): %v", tt.name, tt.domain, err)
+		case !tt.ok && !errors.Is(err, ErrInvalidComputerName):
+			t.Errorf("validateComputerName(%q, %q) = %v, want ErrInvalidComputerName", tt.name, tt.domain, err)
+		}
+	}
+}
+
+func TestDefaultComputerPassword(t *testing.T) {
+	for _, tt := range []struct {
+		name, want string
+	}{
+		{"WS01", "ws01"},
+		{"ABCDEFGHIJKLMN", "abcdefghijklmn"},
+		{"ABCDEFGHIJKLMNO", "abcdefghijklmn"},
+	} {
+		if got := defaultComputerPassword(tt.name); got != tt.want {
+			t.Errorf("defaultComputerPassword(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
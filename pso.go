@@ -0,0 +1,396 @@
+package adsi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// Group type flags of the groupType attribute.
+const (
+	groupTypeGlobal   = 0x2
+	groupTypeSecurity = -0x80000000
+)
+
+// EffectivePolicy is the password and lockout policy that applies to an
+// account.
+type EffectivePolicy struct {
+	Password PasswordPolicy
+	Lockout  LockoutPolicy
+
+	// Source is the ADsPath of the password settings object that the policy
+	// was read from, or of the domain if no fine-grained policy applies.
+	Source string
+
+	// FineGrained is true if the policy was read from a password settings
+	// object rather than from the domain.
+	FineGrained bool
+
+	// Precedence is the precedence of the password settings object. It is
+	// zero for the domain policy.
+	Precedence int
+}
+
+// psoAttrs holds the names of the attributes of a password settings object.
+var psoAttrs = []string{
+	"msDS-PasswordSettingsPrecedence",
+	"msDS-MinimumPasswordLength",
+	"msDS-PasswordHistoryLength",
+	"msDS-MinimumPasswordAge",
+	"msDS-MaximumPasswordAge",
+	"msDS-PasswordComplexityEnabled",
+	"msDS-PasswordReversibleEncryptionEnabled",
+	"msDS-LockoutThreshold",
+	"msDS-LockoutDuration",
+	"msDS-LockoutObservationWindow",
+	"objectGUID",
+}
+
+// EffectivePolicy returns the password and lockout policy that applies to
+// the user or group with the given LDAP path.
+//
+// For users, the msDS-ResultantPSO attribute computed by the domain
+// controller is used: it names the password settings object that applies, or
+// is empty if none applies and the policy of the domain is in force. For
+// groups, and other objects for which the attribute isn't computed, the
+// policy is resolved from the password settings objects that apply to the
+// object directly or, if there are none, to the global security groups that
+// it is a member of, including its primary group, directly or through
+// nesting. Of the candidates, the one with the lowest precedence value wins,
+// and ties are broken in favor of the lowest objectGUID. If no password
+// settings object applies, the policy of the domain that contains the object
+// is returned.
+func (c *Client) EffectivePolicy(path string) (*EffectivePolicy, error) {
+	obj, err := c.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	obj.m.Lock()
+	defer obj.m.Unlock()
+	if obj.closed() {
+		return nil, ErrClosed
+	}
+
+	if err := obj.Pull("msDS-ResultantPSO", "msDS-PSOApplied", "memberOf", "primaryGroupID", "objectSid", "objectClass"); err != nil {
+		return nil, err
+	}
+	resultant, err := optional(obj.AttrString("msDS-ResultantPSO"))
+	if err != nil {
+		return nil, err
+	}
+	if resultant != "" {
+		psoPath, err := pathFromDN(path, resultant)
+		if err != nil {
+			return nil, err
+		}
+		return c.readPSO(psoPath)
+	}
+	classes, err := optional(obj.AttrStringSlice("objectClass"))
+	if err != nil {
+		return nil, err
+	}
+	for _, class := range classes {
+		// The attribute is computed for users, so an empty value means
+		// that no password settings object applies.
+		if strings.EqualFold(class, "user") {
+			return c.domainPolicy(path)
+		}
+	}
+
+	pso, err := c.resolvePSO(path, obj)
+	if err != nil {
+		return nil, err
+	}
+	if pso != nil {
+		return pso, nil
+	}
+	return c.domainPolicy(path)
+}
+
+// resolvePSO returns the password settings object with the highest priority
+// that applies to the object, or nil if none applies. The caller must hold a
+// lock on the object.
+func (c *Client) resolvePSO(path string, obj *Object) (*EffectivePolicy, error) {
+	applied, err := optional(obj.AttrStringSlice("msDS-PSOApplied"))
+	if err != nil {
+		return nil, err
+	}
+	if len(applied) == 0 {
+		groups, err := optional(obj.AttrStringSlice("memberOf"))
+		if err != nil {
+			return nil, err
+		}
+		// The primary group is not listed in memberOf.
+		primary, err := primaryGroup(obj)
+		if err != nil {
+			return nil, err
+		}
+		if primary != "" {
+			groups = append(groups, primary)
+		}
+		if applied, err = c.groupPSOs(path, groups); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		best     *EffectivePolicy
+		bestGUID []byte
+	)
+	seen := make(map[string]bool)
+	for _, dn := range applied {
+		if seen[strings.ToLower(dn)] {
+			continue
+		}
+		seen[strings.ToLower(dn)] = true
+		psoPath, err := pathFromDN(path, dn)
+		if err != nil {
+			return nil, err
+		}
+		p, guid, err := c.readPSOGUID(psoPath)
+		if err != nil {
+			return nil, err
+		}
+		if best == nil || precedes(p.Precedence, guid, best.Precedence, bestGUID) {
+			best, bestGUID = p, guid
+		}
+	}
+	return best, nil
+}
+
+// primaryGroup returns the serverless binding of the primary group of the
+// account, in the form "<SID=S-1-5-...>", or an empty string if the object has
+// none. The security identifier of the group is that of the domain of the
+// account followed by the primaryGroupID. The caller must hold a lock on the
+// object.
+func primaryGroup(obj *Object) (string, error) {
+	rid, err := optional(obj.AttrInt64("primaryGroupID"))
+	if err != nil || rid == 0 {
+		return "", err
+	}
+	sid, err := optional(obj.AttrBytes("objectSid"))
+	if err != nil || len(sid) == 0 {
+		return "", err
+	}
+	if _, err := FormatSID(sid); err != nil {
+		return "", err
+	}
+	if sid[1] == 0 {
+		return "", fmt.Errorf("%w: account SID has no relative identifier", ErrInvalidSID)
+	}
+	group := append([]byte(nil), sid...)
+	binary.LittleEndian.PutUint32(group[len(group)-4:], uint32(rid))
+	s, err := FormatSID(group)
+	if err != nil {
+		return "", err
+	}
+	return "<SID=" + s + ">", nil
+}
+
+// precedes reports whether a password settings object with precedence a and
+// objectGUID guidA takes priority over one with precedence b and objectGUID
+// guidB.
+func precedes(a int, guidA []byte, b int, guidB []byte) bool {
+	if a != b {
+		return a < b
+	}
+	return bytes.Compare(guidA, guidB) < 0
+}
+
+// groupPSOs returns the distinguished names of the password settings objects
+// that apply to the global security groups with the given distinguished
+// names and to the groups that they are nested in.
+func (c *Client) groupPSOs(path string, groups []string) ([]string, error) {
+	var applied []string
+	seen := make(map[string]bool)
+	for len(groups) > 0 {
+		dn := groups[0]
+		groups = groups[1:]
+		if seen[strings.ToLower(dn)] {
+			continue
+		}
+		seen[strings.ToLower(dn)] = true
+
+		groupPath, err := pathFromDN(path, dn)
+		if err != nil {
+			return nil, err
+		}
+		group, err := c.Open(groupPath)
+		if err != nil {
+			return nil, err
+		}
+		psos, parents, err := groupPSOAttrs(group)
+		group.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", dn, err)
+		}
+		applied = append(applied, psos...)
+		groups = append(groups, parents...)
+	}
+	return applied, nil
+}
+
+// groupPSOAttrs returns the password settings objects that apply to the group
+// and the groups that it is a member of. Groups other than global security
+// groups are not subject to password settings objects and return nothing.
+func groupPSOAttrs(group *Object) (psos, parents []string, err error) {
+	group.m.Lock()
+	defer group.m.Unlock()
+	if group.closed() {
+		return nil, nil, ErrClosed
+	}
+	if err = group.Pull("groupType", "msDS-PSOApplied", "memberOf"); err != nil {
+		return
+	}
+	groupType, err := optional(group.AttrInt64("groupType"))
+	if err != nil {
+		return
+	}
+	groupType = int64(int32(groupType))
+	if groupType&groupTypeGlobal == 0 || groupType&groupTypeSecurity == 0 {
+		return nil, nil, nil
+	}
+	if psos, err = optional(group.AttrStringSlice("msDS-PSOApplied")); err != nil {
+		return
+	}
+	parents, err = optional(group.AttrStringSlice("memberOf"))
+	return
+}
+
+// readPSO reads the policy of the password settings object with the given
+// path.
+func (c *Client) readPSO(path string) (*EffectivePolicy, error) {
+	p, _, err := c.readPSOGUID(path)
+	return p, err
+}
+
+// readPSOGUID reads the policy and the objectGUID of the password settings
+// object with the given path.
+func (c *Client) readPSOGUID(path string) (*EffectivePolicy, []byte, error) {
+	obj, err := c.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer obj.Close()
+
+	obj.m.Lock()
+	defer obj.m.Unlock()
+	if obj.closed() {
+		return nil, nil, ErrClosed
+	}
+	if err := obj.Pull(psoAttrs...); err != nil {
+		return nil, nil, err
+	}
+
+	ints := make(map[string]int64)
+	for _, name := range []string{
+		"msDS-PasswordSettingsPrecedence",
+		"msDS-MinimumPasswordLength",
+		"msDS-PasswordHistoryLength",
+		"msDS-MinimumPasswordAge",
+		"msDS-MaximumPasswordAge",
+		"msDS-LockoutThreshold",
+		"msDS-LockoutDuration",
+		"msDS-LockoutObservationWindow",
+	} {
+		v, err := optional(obj.AttrInt64(name))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %v", name, err)
+		}
+		ints[name] = v
+	}
+	complexity, err := optional(obj.AttrBool("msDS-PasswordComplexityEnabled"))
+	if err != nil {
+		return nil, nil, err
+	}
+	reversible, err := optional(obj.AttrBool("msDS-PasswordReversibleEncryptionEnabled"))
+	if err != nil {
+		return nil, nil, err
+	}
+	guid, err := obj.AttrBytes("objectGUID")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := &EffectivePolicy{
+		Password: PasswordPolicy{
+			MinLength:            int(ints["msDS-MinimumPasswordLength"]),
+			HistoryLength:        int(ints["msDS-PasswordHistoryLength"]),
+			MinAge:               directoryInterval(ints["msDS-MinimumPasswordAge"]),
+			MaxAge:               directoryInterval(ints["msDS-MaximumPasswordAge"]),
+			ComplexityRequired:   complexity,
+			ReversibleEncryption: reversible,
+		},
+		Lockout: LockoutPolicy{
+			Threshold:         int(ints["msDS-LockoutThreshold"]),
+			Duration:          directoryInterval(ints["msDS-LockoutDuration"]),
+			ObservationWindow: directoryInterval(ints["msDS-LockoutObservationWindow"]),
+		},
+		Source:      path,
+		FineGrained: true,
+		Precedence:  int(ints["msDS-PasswordSettingsPrecedence"]),
+	}
+	return p, guid, nil
+}
+
+// domainPolicy reads the policy of the domain that contains the object with
+// the given LDAP path.
+func (c *Client) domainPolicy(path string) (*EffectivePolicy, error) {
+	dn, err := dnFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	domainPath, err := pathFromDN(path, domainDN(dn))
+	if err != nil {
+		return nil, err
+	}
+	d, err := c.OpenDomain(domainPath)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+
+	p := &EffectivePolicy{Source: domainPath}
+	if p.Password, err = d.PasswordPolicy(); err != nil {
+		return nil, err
+	}
+	if p.Lockout, err = d.LockoutPolicy(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// domainDN returns the distinguished name of the domain that contains the
+// object with the given distinguished name, which is made up of its trailing
+// DC components.
+func domainDN(dn string) string {
+	start := len(dn)
+	for i := len(dn); i > 0; {
+		j := lastUnescapedComma(dn[:i])
+		rdn := strings.TrimSpace(dn[j+1 : i])
+		if len(rdn) < 3 || !strings.EqualFold(rdn[:3], "DC=") {
+			break
+		}
+		start = j + 1
+		i = j
+	}
+	return strings.TrimSpace(dn[start:])
+}
+
+// lastUnescapedComma returns the index of the last comma in dn that separates
+// two components, or -1 if there is none.
+func lastUnescapedComma(dn string) int {
+	last := -1
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case ',':
+			last = i
+		}
+	}
+	return last
+}
@@ -0,0 +1,156 @@
+package reconcile
+
+import (
+	"fmt"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/api"
+)
+
+// Apply performs the actions of the plan in order through the given client.
+// It stops at the first action that fails and returns an error that
+// identifies it.
+//
+// Actions are idempotent, so a plan that was interrupted can safely be
+// applied again: objects that already exist are updated rather than created,
+// objects that have already been moved or deleted are skipped, and members
+// that have already been added or removed are ignored. Computing a new plan
+// before applying it again avoids unnecessary writes.
+//
+// Writes are subject to the write policy, write interceptor and undo journal
+// of the client.
+func Apply(c *adsi.Client, p *Plan) error {
+	for i, a := range p.Actions {
+		if err := apply(c, a); err != nil {
+			return fmt.Errorf("action %d (%v): %w", i+1, a, err)
+		}
+	}
+	return nil
+}
+
+func apply(c *adsi.Client, a Action) error {
+	switch a.Kind {
+	case Create:
+		return create(c, a)
+	case Update:
+		return update(c, a.Path, a.Changes)
+	case Move:
+		return move(c, a)
+	case AddMembers, RemoveMembers:
+		return modifyMembers(c, a)
+	case Delete:
+		obj, err := c.Open(a.Path)
+		if err != nil {
+			if notFound(err) {
+				return nil
+			}
+			return err
+		}
+		defer obj.Close()
+		return obj.Delete()
+	default:
+		return fmt.Errorf("invalid action kind %d", int(a.Kind))
+	}
+}
+
+func create(c *adsi.Client, a Action) error {
+	rdn, parent, err := splitPath(a.Path)
+	if err != nil {
+		return err
+	}
+	container, err := c.OpenContainer(parent)
+	if err != nil {
+		return err
+	}
+	defer container.Close()
+
+	obj, err := container.Create(a.Class, rdn)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	if err := put(obj, a.Changes); err != nil {
+		return err
+	}
+	err = obj.SetInfo()
+	if exists(err) {
+		// The object was created by an earlier attempt.
+		return update(c, a.Path, a.Changes)
+	}
+	return err
+}
+
+func update(c *adsi.Client, path string, changes []adsi.Change) error {
+	obj, err := c.Open(path)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	if err := put(obj, changes); err != nil {
+		return err
+	}
+	return obj.SetInfo()
+}
+
+// put stages the new values of each change in the property cache of the
+// object.
+func put(obj *adsi.Object, changes []adsi.Change) error {
+	for _, change := range changes {
+		var err error
+		if len(change.New) == 0 {
+			err = obj.PutEx(change.Name, api.ADS_PROPERTY_CLEAR)
+		} else {
+			err = obj.PutEx(change.Name, api.ADS_PROPERTY_UPDATE, change.New...)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", change.Name, err)
+		}
+	}
+	return nil
+}
+
+func move(c *adsi.Client, a Action) error {
+	rdn, parent, err := splitPath(a.Target)
+	if err != nil {
+		return err
+	}
+	container, err := c.OpenContainer(parent)
+	if err != nil {
+		return err
+	}
+	defer container.Close()
+
+	moved, err := container.MoveHere(a.Path, rdn)
+	if err != nil {
+		if notFound(err) {
+			// The object may have been moved by an earlier attempt.
+			if obj, openErr := c.Open(a.Target); openErr == nil {
+				obj.Close()
+				return nil
+			}
+		}
+		return err
+	}
+	moved.Close()
+	return nil
+}
+
+func modifyMembers(c *adsi.Client, a Action) error {
+	obj, err := c.Open(a.Path)
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	g, err := obj.ToGroup()
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+
+	if a.Kind == AddMembers {
+		_, err = g.AddMembers(a.Members...)
+	} else {
+		_, err = g.RemoveMembers(a.Members...)
+	}
+	return err
+}
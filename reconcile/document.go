@@ -0,0 +1,173 @@
+// Package reconcile brings a directory into a desired state.
+//
+// The desired state is described by a Document, which is usually loaded from
+// YAML:
+//
+//	objects:
+//	  - path: LDAP://CN=Jane Doe,OU=Staff,DC=example,DC=com
+//	    class: user
+//	    attributes:
+//	      sAMAccountName: jdoe
+//	      description: Accounts payable
+//	  - path: LDAP://CN=Payables,OU=Groups,DC=example,DC=com
+//	    class: group
+//	    members:
+//	      - LDAP://CN=Jane Doe,OU=Staff,DC=example,DC=com
+//	  - path: LDAP://CN=John Doe,OU=Staff,DC=example,DC=com
+//	    absent: true
+//
+// Compute compares a document with the directory and returns a Plan of the
+// actions that are needed to reach the desired state. The plan can be
+// printed for review and then applied with Apply.
+package reconcile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-adsi/adsi/adspath"
+	"gopkg.in/yaml.v3"
+)
+
+// Document describes the desired state of a set of directory objects.
+type Document struct {
+	Objects []Entry `yaml:"objects"`
+}
+
+// Entry describes the desired state of a single directory object.
+type Entry struct {
+	// Path is the LDAP ADsPath that the object should have.
+	Path string `yaml:"path"`
+
+	// GUID optionally identifies an existing object by its objectGUID. If the
+	// object is found at some other path, it is moved to Path.
+	GUID string `yaml:"guid,omitempty"`
+
+	// Class is the schema class of the object. It is required for objects
+	// that have to be created.
+	Class string `yaml:"class,omitempty"`
+
+	// Absent indicates that the object should not exist. Objects that are
+	// absent are deleted along with any objects that they contain.
+	Absent bool `yaml:"absent,omitempty"`
+
+	// Attributes holds the desired values of the attributes of the object.
+	// Attributes that are not listed are left unmodified. An empty list
+	// clears the attribute.
+	Attributes map[string]Values `yaml:"attributes,omitempty"`
+
+	// Members holds the ADsPaths of the desired members of a group. If it is
+	// nil the membership of the group is left unmodified; if it is empty
+	// every member is removed.
+	Members *[]string `yaml:"members,omitempty"`
+}
+
+// Values holds the values of an attribute. In YAML it may be written as a
+// single scalar or as a sequence of scalars. Integers are decoded as int64.
+type Values []interface{}
+
+// UnmarshalYAML decodes a scalar or a sequence of scalars.
+func (v *Values) UnmarshalYAML(node *yaml.Node) error {
+	var raw interface{}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	switch x := raw.(type) {
+	case nil:
+		*v = nil
+	case []interface{}:
+		values := make(Values, 0, len(x))
+		for _, value := range x {
+			value, err := scalar(value)
+			if err != nil {
+				return fmt.Errorf("line %d: %v", node.Line, err)
+			}
+			values = append(values, value)
+		}
+		*v = values
+	default:
+		value, err := scalar(x)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", node.Line, err)
+		}
+		*v = Values{value}
+	}
+	return nil
+}
+
+// scalar converts a decoded YAML value into an attribute value.
+func scalar(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string, bool, int64, []byte:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v), nil
+		}
+		return nil, fmt.Errorf("unsupported non-integer value %v", v)
+	default:
+		return nil, fmt.Errorf("unsupported value %v of type %T", value, value)
+	}
+}
+
+// Load reads a YAML document from r and validates it.
+func Load(r io.Reader) (*Document, error) {
+	var doc Document
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// LoadFile reads and validates the YAML document at the given path.
+func LoadFile(path string) (*Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Validate reports the first problem with the document, if any.
+func (doc *Document) Validate() error {
+	seen := make(map[string]bool, len(doc.Objects))
+	for i, e := range doc.Objects {
+		dn, err := entryDN(e.Path)
+		if err != nil {
+			return fmt.Errorf("object %d: %v", i, err)
+		}
+		key := strings.ToLower(dn)
+		if seen[key] {
+			return fmt.Errorf("object %d: %s is listed more than once", i, e.Path)
+		}
+		seen[key] = true
+		if e.Absent && (len(e.Attributes) > 0 || e.Members != nil) {
+			return fmt.Errorf("object %d: %s is absent but has attributes or members", i, e.Path)
+		}
+	}
+	return nil
+}
+
+// entryDN returns the distinguished name of an LDAP ADsPath.
+func entryDN(path string) (string, error) {
+	p, err := adspath.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	if p.Scheme != adspath.LDAP || p.Path == "" {
+		return "", fmt.Errorf("%s is not an LDAP path to an object", path)
+	}
+	return p.DN(), nil
+}
@@ -0,0 +1,86 @@
+package reconcile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestValuesUnmarshalYAML(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want Values
+	}{
+		{"v: jdoe", Values{"jdoe"}},
+		{"v: 42", Values{int64(42)}},
+		{"v: 42.0", Values{int64(42)}},
+		{"v: true", Values{true}},
+		{"v: [a, 1, false]", Values{"a", int64(1), false}},
+		{"v: []", Values{}},
+		{"v:", nil},
+		{"v: !!binary AQI=", Values{"\x01\x02"}},
+	} {
+		var doc struct{ V Values }
+		if err := yaml.Unmarshal([]byte(tt.in), &doc); err != nil {
+			t.Errorf("Unmarshal(%q): %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(doc.V, tt.want) {
+			t.Errorf("Unmarshal(%q) = %#v, want %#v", tt.in, doc.V, tt.want)
+		}
+	}
+	for _, in := range []string{
+		"v: 1.5",
+		"v: [a, 1.5]",
+		"v: {a: b}",
+		"v: [[a]]",
+	} {
+		var doc struct{ V Values }
+		if err := yaml.Unmarshal([]byte(in), &doc); err == nil {
+			t.Errorf("Unmarshal(%q) = %#v, want error", in, doc.V)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	doc, err := Load(strings.NewReader(`
+objects:
+  - path: LDAP://CN=Jane Doe,OU=Staff,DC=example,DC=com
+    class: user
+    attributes:
+      sAMAccountName: jdoe
+      otherTelephone: [555-0100, 555-0101]
+  - path: LDAP://CN=Payables,OU=Groups,DC=example,DC=com
+    members: []
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Objects) != 2 {
+		t.Fatalf("Load() returned %d objects, want 2", len(doc.Objects))
+	}
+	want := map[string]Values{
+		"sAMAccountName": {"jdoe"},
+		"otherTelephone": {"555-0100", "555-0101"},
+	}
+	if got := doc.Objects[0].Attributes; !reflect.DeepEqual(got, want) {
+		t.Errorf("Attributes = %v, want %v", got, want)
+	}
+	if m := doc.Objects[1].Members; m == nil || len(*m) != 0 {
+		t.Errorf("Members = %v, want empty", m)
+	}
+
+	for _, in := range []string{
+		"objects: [{path: 'WinNT://WORKGROUP/host'}]",
+		"objects: [{path: 'LDAP://srv'}]",
+		"objects: [{path: 'LDAP://CN=a,DC=x'}, {path: 'LDAP://cn=A,dc=X'}]",
+		"objects: [{path: 'LDAP://CN=a,DC=x', absent: true, members: []}]",
+		"objects: [{path: 'LDAP://CN=a,DC=x', unknown: 1}]",
+	} {
+		if _, err := Load(strings.NewReader(in)); err == nil {
+			t.Errorf("Load(%q) succeeded", in)
+		}
+	}
+}
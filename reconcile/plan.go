@@ -0,0 +1,541 @@
+package reconcile
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/go-adsi/adsi"
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+)
+
+// Kind identifies the kind of an action.
+type Kind int
+
+// Action kinds, in the order in which they are applied.
+const (
+	Create Kind = iota + 1
+	Move
+	Update
+	RemoveMembers
+	AddMembers
+	Delete
+)
+
+var kindNames = map[Kind]string{
+	Create:        "create",
+	Move:          "move",
+	Update:        "update",
+	RemoveMembers: "remove members",
+	AddMembers:    "add members",
+	Delete:        "delete",
+}
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Action is a single step of a plan.
+type Action struct {
+	// Kind is the kind of action.
+	Kind Kind
+
+	// Path is the ADsPath of the object that the action applies to. For Move
+	// it is the current path of the object.
+	Path string
+
+	// Class is the schema class of the object.
+	Class string
+
+	// Target is the ADsPath that the object is moved to by Move.
+	Target string
+
+	// Changes holds the attribute values to populate for Create and the
+	// attribute modifications to make for Update.
+	Changes []adsi.Change
+
+	// Members holds the ADsPaths of the members to add or remove for
+	// AddMembers and RemoveMembers.
+	Members []string
+}
+
+// String returns a human-readable description of the action.
+func (a Action) String() string {
+	switch a.Kind {
+	case Create:
+		return fmt.Sprintf("create %s %s", a.Class, a.Path)
+	case Move:
+		return fmt.Sprintf("move %s to %s", a.Path, a.Target)
+	case RemoveMembers:
+		return fmt.Sprintf("remove %d member(s) from %s", len(a.Members), a.Path)
+	case AddMembers:
+		return fmt.Sprintf("add %d member(s) to %s", len(a.Members), a.Path)
+	default:
+		return fmt.Sprintf("%s %s", a.Kind, a.Path)
+	}
+}
+
+// Plan is an ordered list of actions that bring the directory into the state
+// described by a document.
+//
+// Actions are ordered so that their dependencies are satisfied: containers
+// are created before their children, objects are created and moved before
+// their attributes and memberships are modified, and objects are deleted
+// last, children before their parents, except that an object whose path is
+// reused by a created or moved object is deleted or moved away first.
+type Plan struct {
+	Actions []Action
+}
+
+// Empty reports whether the directory is already in the desired state.
+func (p *Plan) Empty() bool {
+	return len(p.Actions) == 0
+}
+
+// String returns a human-readable summary of the plan, with one line per
+// action followed by an indented line per attribute or member.
+func (p *Plan) String() string {
+	var buf bytes.Buffer
+	for _, a := range p.Actions {
+		switch a.Kind {
+		case Create, AddMembers:
+			buf.WriteString("+ ")
+		case RemoveMembers, Delete:
+			buf.WriteString("- ")
+		case Move:
+			buf.WriteString("> ")
+		default:
+			buf.WriteString("~ ")
+		}
+		buf.WriteString(a.String())
+		buf.WriteByte('\n')
+		for _, c := range a.Changes {
+			fmt.Fprintf(&buf, "    %s\n", c)
+		}
+		for _, m := range a.Members {
+			fmt.Fprintf(&buf, "    %s\n", m)
+		}
+	}
+	return buf.String()
+}
+
+// Compute compares the document with the directory and returns the plan of
+// actions that bring the directory into the desired state. Objects are read
+// through the given client; nothing is written.
+//
+// Attribute values are compared exactly, including the case of strings.
+func Compute(c *adsi.Client, doc *Document) (*Plan, error) {
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+	var actions []Action
+	for _, e := range doc.Objects {
+		entryActions, err := compute(c, e)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Path, err)
+		}
+		actions = append(actions, entryActions...)
+	}
+	return &Plan{Actions: order(actions)}, nil
+}
+
+func compute(c *adsi.Client, e Entry) ([]Action, error) {
+	obj, err := find(c, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if obj == nil {
+		if e.Absent {
+			return nil, nil
+		}
+		if e.Class == "" {
+			return nil, fmt.Errorf("object does not exist and has no class")
+		}
+		a := Action{Kind: Create, Path: e.Path, Class: e.Class}
+		for _, name := range attributeNames(e) {
+			if values := e.Attributes[name]; len(values) > 0 {
+				a.Changes = append(a.Changes, adsi.Change{Name: name, New: values})
+			}
+		}
+		actions := []Action{a}
+		if e.Members != nil && len(*e.Members) > 0 {
+			actions = append(actions, Action{Kind: AddMembers, Path: e.Path, Class: e.Class, Members: *e.Members})
+		}
+		return actions, nil
+	}
+	defer obj.Close()
+
+	path, err := obj.Path()
+	if err != nil {
+		return nil, err
+	}
+	class, err := obj.Class()
+	if err != nil {
+		return nil, err
+	}
+	if e.Absent {
+		return []Action{{Kind: Delete, Path: path, Class: class}}, nil
+	}
+
+	var actions []Action
+	if !samePath(path, e.Path) {
+		actions = append(actions, Action{Kind: Move, Path: path, Class: class, Target: e.Path})
+	}
+
+	update := Action{Kind: Update, Path: e.Path, Class: class}
+	for _, name := range attributeNames(e) {
+		current, err := obj.Values(name)
+		if err != nil {
+			return nil, err
+		}
+		desired := []interface{}(e.Attributes[name])
+		if !equalValues(current, desired) {
+			update.Changes = append(update.Changes, adsi.Change{Name: name, Old: current, New: desired})
+		}
+	}
+	if len(update.Changes) > 0 {
+		actions = append(actions, update)
+	}
+
+	if e.Members != nil {
+		add, remove, err := memberDelta(obj, *e.Members)
+		if err != nil {
+			return nil, err
+		}
+		if len(remove) > 0 {
+			actions = append(actions, Action{Kind: RemoveMembers, Path: e.Path, Class: class, Members: remove})
+		}
+		if len(add) > 0 {
+			actions = append(actions, Action{Kind: AddMembers, Path: e.Path, Class: class, Members: add})
+		}
+	}
+	return actions, nil
+}
+
+// find opens the object described by the entry, or returns nil if it doesn't
+// exist.
+func find(c *adsi.Client, e Entry) (*adsi.Object, error) {
+	path := e.Path
+	if e.GUID != "" {
+		p, err := adspath.Parse(e.Path)
+		if err != nil {
+			return nil, err
+		}
+		p.Path = "<GUID=" + e.GUID + ">"
+		path = p.String()
+	}
+	obj, err := c.Open(path)
+	if err != nil {
+		if notFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+// memberDelta returns the members that must be added to and removed from the
+// group to leave it with the desired members.
+func memberDelta(obj *adsi.Object, desired []string) (add, remove []string, err error) {
+	g, err := obj.ToGroup()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer g.Close()
+	members, err := g.Members()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer members.Close()
+
+	current := make(map[string]string)
+	for member, err := range members.All() {
+		if err != nil {
+			return nil, nil, err
+		}
+		path, err := member.Path()
+		member.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		current[adspath.Key(path)] = path
+	}
+
+	wanted := make(map[string]bool, len(desired))
+	for _, path := range desired {
+		key := adspath.Key(path)
+		wanted[key] = true
+		if _, ok := current[key]; !ok {
+			add = append(add, path)
+		}
+	}
+	for key, path := range current {
+		if !wanted[key] {
+			remove = append(remove, path)
+		}
+	}
+	sort.Strings(remove)
+	return add, remove, nil
+}
+
+// order sorts actions so that their dependencies are satisfied. Actions are
+// applied by kind and, within a kind, by depth, unless one action depends on
+// another that would otherwise come later: an object is created or moved
+// only after its parent exists and after any object that held its path has
+// been deleted or moved away, and an object is modified only after it has
+// been created or moved into place. Actions that depend on each other in a
+// cycle are left in kind order.
+func order(actions []Action) []Action {
+	sort.SliceStable(actions, func(i, j int) bool {
+		a, b := actions[i], actions[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		switch a.Kind {
+		case Create:
+			return depth(a.Path) < depth(b.Path)
+		case Move:
+			return depth(a.Target) < depth(b.Target)
+		case Delete:
+			return depth(a.Path) > depth(b.Path)
+		}
+		return false
+	})
+	sorted := make([]Action, 0, len(actions))
+	done := make([]bool, len(actions))
+	for len(sorted) < len(actions) {
+		next := -1
+		for i := range actions {
+			if !done[i] && ready(actions, done, i) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			// The remaining actions form a cycle.
+			for i := range actions {
+				if !done[i] {
+					next = i
+					break
+				}
+			}
+		}
+		done[next] = true
+		sorted = append(sorted, actions[next])
+	}
+	return sorted
+}
+
+// ready reports whether every action that actions[i] depends on is done.
+func ready(actions []Action, done []bool, i int) bool {
+	for j := range actions {
+		if j != i && !done[j] && dependsOn(actions[i], actions[j]) {
+			return false
+		}
+	}
+	return true
+}
+
+// dependsOn reports whether a must be applied after b.
+func dependsOn(a, b Action) bool {
+	switch a.Kind {
+	case Create, Move:
+		path := a.Path
+		if a.Kind == Move {
+			path = a.Target
+		}
+		if _, parent, err := splitPath(path); err == nil && creates(b, parent) {
+			return true
+		}
+		return frees(b, path)
+	case Update, RemoveMembers:
+		return creates(b, a.Path)
+	case AddMembers:
+		if creates(b, a.Path) {
+			return true
+		}
+		for _, member := range a.Members {
+			if creates(b, member) {
+				return true
+			}
+		}
+	case Delete:
+		switch b.Kind {
+		case Move, Delete:
+			// Objects are moved out of a subtree, and the objects in it
+			// deleted, before the subtree itself is deleted.
+			return !samePath(a.Path, b.Path) && within(b.Path, a.Path)
+		case RemoveMembers:
+			for _, member := range b.Members {
+				if samePath(member, a.Path) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// creates reports whether the action leaves an object at the given path.
+func creates(a Action, path string) bool {
+	switch a.Kind {
+	case Create:
+		return samePath(a.Path, path)
+	case Move:
+		return samePath(a.Target, path)
+	}
+	return false
+}
+
+// frees reports whether the action removes the object at the given path.
+func frees(a Action, path string) bool {
+	switch a.Kind {
+	case Move, Delete:
+		return samePath(a.Path, path)
+	}
+	return false
+}
+
+// within reports whether the object at path is the object at base or one of
+// its descendants.
+func within(path, base string) bool {
+	dn, err := entryDN(path)
+	if err != nil {
+		return false
+	}
+	baseDN, err := entryDN(base)
+	if err != nil {
+		return false
+	}
+	return adspath.WithinDN(dn, baseDN)
+}
+
+func attributeNames(e Entry) []string {
+	names := make([]string, 0, len(e.Attributes))
+	for name := range e.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// equalValues reports whether a and b hold the same set of values, without
+// regard to order. Distinguished names, such as the values of manager and
+// managedBy, are compared as the directory compares them, so that a
+// difference in case alone is not reported as a change.
+func equalValues(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, x := range a {
+		found := false
+		for j, y := range b {
+			if !used[j] && equalValue(x, y) {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// equalValue reports whether x and y are the same value.
+func equalValue(x, y interface{}) bool {
+	if a, ok := x.(string); ok {
+		if b, ok := y.(string); ok && isDN(a) && isDN(b) {
+			return adspath.EqualDN(a, b)
+		}
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// isDN reports whether s has the form of a distinguished name: its first
+// component starts with an attribute type, which is either a name or an
+// object identifier, followed by an equals sign.
+func isDN(s string) bool {
+	typ, _, ok := strings.Cut(s, "=")
+	typ = strings.TrimSpace(typ)
+	if !ok || typ == "" {
+		return false
+	}
+	oid := typ[0] >= '0' && typ[0] <= '9'
+	for i := 0; i < len(typ); i++ {
+		switch c := typ[i]; {
+		case c >= '0' && c <= '9':
+		case c == '.' && oid:
+		case (c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c == '-') && !oid:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func samePath(a, b string) bool {
+	return adspath.Key(a) == adspath.Key(b)
+}
+
+// depth returns the number of components of the distinguished name of the
+// given ADsPath.
+func depth(path string) int {
+	dn, err := entryDN(path)
+	if err != nil {
+		return 0
+	}
+	n := 1
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case ',':
+			n++
+		}
+	}
+	return n
+}
+
+// splitPath returns the relative name of the object at the given ADsPath and
+// the ADsPath of its parent.
+func splitPath(path string) (rdn, parent string, err error) {
+	p, err := adspath.Parse(path)
+	if err != nil {
+		return "", "", err
+	}
+	dn := p.Path
+	for i := 0; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case ',':
+			p.Path = dn[i+1:]
+			return dn[:i], p.String(), nil
+		}
+	}
+	return "", "", fmt.Errorf("%s has no parent", path)
+}
+
+// notFound reports whether err indicates that an object does not exist.
+func notFound(err error) bool {
+	return api.IsHresult(err, api.E_DS_NO_SUCH_OBJECT)
+}
+
+// exists reports whether err indicates that an object or value already
+// exists.
+func exists(err error) bool {
+	return api.IsHresult(err, api.E_ADS_OBJECT_EXISTS) ||
+		api.IsHresult(err, api.E_DS_OBJECT_ALREADY_EXISTS) ||
+		api.IsHresult(err, api.E_DS_ATT_VAL_ALREADY_EXISTS)
+}
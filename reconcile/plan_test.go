@@ -0,0 +1,179 @@
+package reconcile
+
+import (
+	"reflect"
+	"testing"
+)
+
+const base = "LDAP://DC=example,DC=com"
+
+func path(dn string) string {
+	return "LDAP://" + dn + ",DC=example,DC=com"
+}
+
+func TestOrder(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		actions []Action
+		want    []string
+	}{
+		{
+			name: "kinds",
+			actions: []Action{
+				{Kind: Delete, Path: path("CN=old,OU=Staff")},
+				{Kind: AddMembers, Path: path("CN=g,OU=Groups"), Members: []string{path("CN=a,OU=Staff")}},
+				{Kind: Update, Path: path("CN=a,OU=Staff")},
+				{Kind: Create, Path: path("CN=a,OU=Staff")},
+				{Kind: RemoveMembers, Path: path("CN=g,OU=Groups"), Members: []string{path("CN=b,OU=Staff")}},
+				{Kind: Move, Path: path("CN=c,OU=Old"), Target: path("CN=c,OU=Staff")},
+			},
+			want: []string{
+				"create " + path("CN=a,OU=Staff"),
+				"move " + path("CN=c,OU=Old"),
+				"update " + path("CN=a,OU=Staff"),
+				"remove members " + path("CN=g,OU=Groups"),
+				"add members " + path("CN=g,OU=Groups"),
+				"delete " + path("CN=old,OU=Staff"),
+			},
+		},
+		{
+			name: "parents before children",
+			actions: []Action{
+				{Kind: Create, Path: path("CN=a,OU=Sub,OU=Top")},
+				{Kind: Create, Path: path("OU=Sub,OU=Top")},
+				{Kind: Create, Path: path("OU=Top")},
+			},
+			want: []string{
+				"create " + path("OU=Top"),
+				"create " + path("OU=Sub,OU=Top"),
+				"create " + path("CN=a,OU=Sub,OU=Top"),
+			},
+		},
+		{
+			name: "children deleted first",
+			actions: []Action{
+				{Kind: Delete, Path: path("OU=Top")},
+				{Kind: Delete, Path: path("CN=a,OU=Sub,OU=Top")},
+				{Kind: Delete, Path: path("OU=Sub,OU=Top")},
+			},
+			want: []string{
+				"delete " + path("CN=a,OU=Sub,OU=Top"),
+				"delete " + path("OU=Sub,OU=Top"),
+				"delete " + path("OU=Top"),
+			},
+		},
+		{
+			name: "create in moved container",
+			actions: []Action{
+				{Kind: Create, Path: path("CN=a,OU=New")},
+				{Kind: Move, Path: path("OU=Old"), Target: path("OU=New")},
+			},
+			want: []string{
+				"move " + path("OU=Old"),
+				"create " + path("CN=a,OU=New"),
+			},
+		},
+		{
+			name: "create in place of deleted object",
+			actions: []Action{
+				{Kind: Create, Path: path("CN=a,OU=Staff")},
+				{Kind: Update, Path: path("CN=a,OU=Staff")},
+				{Kind: Delete, Path: path("cn=A,ou=staff")},
+				{Kind: Delete, Path: path("CN=b,OU=Staff")},
+			},
+			want: []string{
+				"delete " + path("cn=A,ou=staff"),
+				"create " + path("CN=a,OU=Staff"),
+				"update " + path("CN=a,OU=Staff"),
+				"delete " + path("CN=b,OU=Staff"),
+			},
+		},
+		{
+			name: "create in place of moved object",
+			actions: []Action{
+				{Kind: Create, Path: path("CN=a,OU=Staff")},
+				{Kind: Move, Path: path("CN=a,OU=Staff"), Target: path("CN=a,OU=Former")},
+			},
+			want: []string{
+				"move " + path("CN=a,OU=Staff"),
+				"create " + path("CN=a,OU=Staff"),
+			},
+		},
+		{
+			name: "move out before delete",
+			actions: []Action{
+				{Kind: Delete, Path: path("OU=Old")},
+				{Kind: Move, Path: path("CN=a,OU=Old"), Target: path("CN=a,OU=Staff")},
+				{Kind: Delete, Path: path(`CN=x\,OU=Old,OU=Staff`)},
+			},
+			want: []string{
+				"move " + path("CN=a,OU=Old"),
+				"delete " + path(`CN=x\,OU=Old,OU=Staff`),
+				"delete " + path("OU=Old"),
+			},
+		},
+		{
+			name: "swap",
+			actions: []Action{
+				{Kind: Move, Path: path("CN=a"), Target: path("CN=b")},
+				{Kind: Move, Path: path("CN=b"), Target: path("CN=a")},
+			},
+			want: []string{
+				"move " + path("CN=a"),
+				"move " + path("CN=b"),
+			},
+		},
+	} {
+		var got []string
+		for _, a := range order(tt.actions) {
+			got = append(got, a.Kind.String()+" "+a.Path)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: order() =\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDepth(t *testing.T) {
+	for _, tt := range []struct {
+		path string
+		want int
+	}{
+		{base, 2},
+		{path("OU=Staff"), 3},
+		{path(`CN=Doe\, Jane,OU=Staff`), 4},
+		{path(`CN=a\\,OU=Staff`), 4},
+		{"LDAP://srv/CN=a,DC=x", 2},
+		{"LDAP://srv", 0},
+		{"WinNT://WORKGROUP/host", 0},
+		{"", 0},
+	} {
+		if got := depth(tt.path); got != tt.want {
+			t.Errorf("depth(%q) = %d, want %d", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestSplitPath(t *testing.T) {
+	for _, tt := range []struct {
+		path, rdn, parent string
+	}{
+		{path("OU=Staff"), "OU=Staff", base},
+		{path(`CN=Doe\, Jane,OU=Staff`), `CN=Doe\, Jane`, path("OU=Staff")},
+		{"LDAP://srv/CN=a,DC=x", "CN=a", "LDAP://srv/DC=x"},
+	} {
+		rdn, parent, err := splitPath(tt.path)
+		if err != nil {
+			t.Errorf("splitPath(%q): %v", tt.path, err)
+			continue
+		}
+		if rdn != tt.rdn || parent != tt.parent {
+			t.Errorf("splitPath(%q) = %q, %q, want %q, %q", tt.path, rdn, parent, tt.rdn, tt.parent)
+		}
+	}
+	for _, s := range []string{"LDAP://DC=com", "LDAP://srv", "LDAP://x/y?"} {
+		if _, _, err := splitPath(s); err == nil {
+			t.Errorf("splitPath(%q) succeeded", s)
+		}
+	}
+}
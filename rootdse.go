@@ -0,0 +1,314 @@
+package adsi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// Object identifiers of LDAP controls that are commonly advertised in the
+// supportedControl attribute of RootDSE.
+const (
+	ControlPaging      = "1.2.840.113556.1.4.319"
+	ControlSort        = "1.2.840.113556.1.4.473"
+	ControlShowDeleted = "1.2.840.113556.1.4.417"
+	ControlDirSync     = "1.2.840.113556.1.4.841"
+	ControlVLV         = "2.16.840.1.113730.3.4.9"
+)
+
+// Object identifiers of server capabilities that are commonly advertised in
+// the supportedCapabilities attribute of RootDSE.
+const (
+	CapabilityActiveDirectory = "1.2.840.113556.1.4.800"
+	CapabilityADAM            = "1.2.840.113556.1.4.1851"
+	CapabilityPartialSecrets  = "1.2.840.113556.1.4.1920"
+)
+
+// FunctionalLevel is the functional level of an Active Directory domain,
+// forest or domain controller.
+type FunctionalLevel int
+
+// Functional levels.
+const (
+	Windows2000          FunctionalLevel = 0
+	Windows2003Interim   FunctionalLevel = 1
+	Windows2003          FunctionalLevel = 2
+	Windows2008          FunctionalLevel = 3
+	Windows2008R2        FunctionalLevel = 4
+	Windows2012          FunctionalLevel = 5
+	Windows2012R2        FunctionalLevel = 6
+	Windows2016          FunctionalLevel = 7
+	Windows2025          FunctionalLevel = 10
+	UnknownFunctionality FunctionalLevel = -1
+)
+
+var functionalLevelNames = map[FunctionalLevel]string{
+	Windows2000:          "Windows 2000",
+	Windows2003Interim:   "Windows Server 2003 interim",
+	Windows2003:          "Windows Server 2003",
+	Windows2008:          "Windows Server 2008",
+	Windows2008R2:        "Windows Server 2008 R2",
+	Windows2012:          "Windows Server 2012",
+	Windows2012R2:        "Windows Server 2012 R2",
+	Windows2016:          "Windows Server 2016",
+	Windows2025:          "Windows Server 2025",
+	UnknownFunctionality: "unknown",
+}
+
+// String returns the name of the functional level.
+func (l FunctionalLevel) String() string {
+	if name, ok := functionalLevelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("FunctionalLevel(%d)", int(l))
+}
+
+// RootDSE holds the attributes of the root of the directory information tree
+// of an LDAP server, which describe the server and the naming contexts that it
+// holds.
+type RootDSE struct {
+	DefaultNamingContext       string
+	ConfigurationNamingContext string
+	SchemaNamingContext        string
+	RootDomainNamingContext    string
+	NamingContexts             []string
+
+	// DNSHostName is the DNS name of the server.
+	DNSHostName string
+
+	// ServerName is the distinguished name of the server object of the
+	// domain controller in the configuration naming context.
+	ServerName string
+
+	// SupportedControls holds the object identifiers of the LDAP controls
+	// supported by the server.
+	SupportedControls []string
+
+	// SupportedCapabilities holds the object identifiers of the capabilities
+	// of the server.
+	SupportedCapabilities []string
+
+	// SupportedLDAPVersions holds the versions of the LDAP protocol supported
+	// by the server.
+	SupportedLDAPVersions []int
+
+	// Functional levels are UnknownFunctionality if the server does not
+	// advertise them, as is the case for servers other than Active Directory.
+	DomainFunctionality           FunctionalLevel
+	ForestFunctionality           FunctionalLevel
+	DomainControllerFunctionality FunctionalLevel
+
+	// CurrentTime is the time on the server when RootDSE was read.
+	CurrentTime time.Time
+}
+
+// rootDSEAttrs holds the names of the attributes that are read from RootDSE.
+var rootDSEAttrs = []string{
+	"defaultNamingContext",
+	"configurationNamingContext",
+	"schemaNamingContext",
+	"rootDomainNamingContext",
+	"namingContexts",
+	"dnsHostName",
+	"serverName",
+	"supportedControl",
+	"supportedCapabilities",
+	"supportedLDAPVersion",
+	"domainFunctionality",
+	"forestFunctionality",
+	"domainControllerFunctionality",
+	"currentTime",
+}
+
+// RootDSE reads RootDSE from a server of the domain that the computer
+// belongs to.
+func (c *Client) RootDSE() (*RootDSE, error) {
+	return c.ServerRootDSE("")
+}
+
+// ServerRootDSE reads RootDSE from the given server, which may be a host name
+// or a domain name. If server is empty a server of the domain that the
+// computer belongs to is used.
+func (c *Client) ServerRootDSE(server string) (*RootDSE, error) {
+	path := "LDAP://RootDSE"
+	if server != "" {
+		path = "LDAP://" + server + "/RootDSE"
+	}
+	obj, err := c.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	obj.m.Lock()
+	defer obj.m.Unlock()
+	if obj.closed() {
+		return nil, ErrClosed
+	}
+	if err := obj.Pull(rootDSEAttrs...); err != nil {
+		return nil, err
+	}
+
+	var r RootDSE
+	strs := []struct {
+		name  string
+		value *string
+	}{
+		{"defaultNamingContext", &r.DefaultNamingContext},
+		{"configurationNamingContext", &r.ConfigurationNamingContext},
+		{"schemaNamingContext", &r.SchemaNamingContext},
+		{"rootDomainNamingContext", &r.RootDomainNamingContext},
+		{"dnsHostName", &r.DNSHostName},
+		{"serverName", &r.ServerName},
+	}
+	for _, s := range strs {
+		if *s.value, err = optional(obj.AttrString(s.name)); err != nil {
+			return nil, err
+		}
+	}
+
+	slices := []struct {
+		name   string
+		values *[]string
+	}{
+		{"namingContexts", &r.NamingContexts},
+		{"supportedControl", &r.SupportedControls},
+		{"supportedCapabilities", &r.SupportedCapabilities},
+	}
+	for _, s := range slices {
+		if *s.values, err = optional(obj.AttrStringSlice(s.name)); err != nil {
+			return nil, err
+		}
+	}
+
+	versions, err := optional(obj.Attr("supportedLDAPVersion"))
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range normalizeValues(versions) {
+		if n, ok := rootDSEInt(v); ok {
+			r.SupportedLDAPVersions = append(r.SupportedLDAPVersions, n)
+		}
+	}
+
+	levels := []struct {
+		name  string
+		level *FunctionalLevel
+	}{
+		{"domainFunctionality", &r.DomainFunctionality},
+		{"forestFunctionality", &r.ForestFunctionality},
+		{"domainControllerFunctionality", &r.DomainControllerFunctionality},
+	}
+	for _, l := range levels {
+		values, err := optional(obj.Attr(l.name))
+		if err != nil {
+			return nil, err
+		}
+		*l.level = UnknownFunctionality
+		for _, v := range normalizeValues(values) {
+			if n, ok := rootDSEInt(v); ok {
+				*l.level = FunctionalLevel(n)
+				break
+			}
+		}
+	}
+
+	times, err := optional(obj.Attr("currentTime"))
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range normalizeValues(times) {
+		switch t := v.(type) {
+		case time.Time:
+			r.CurrentTime = t
+		case string:
+			if r.CurrentTime, err = parseGeneralizedTime(t); err != nil {
+				return nil, fmt.Errorf("currentTime: %v", err)
+			}
+		}
+	}
+
+	return &r, nil
+}
+
+// SupportsControl reports whether the server supports the LDAP control with
+// the given object identifier.
+func (r *RootDSE) SupportsControl(oid string) bool {
+	return containsString(r.SupportedControls, oid)
+}
+
+// SupportsCapability reports whether the server has the capability with the
+// given object identifier.
+func (r *RootDSE) SupportsCapability(oid string) bool {
+	return containsString(r.SupportedCapabilities, oid)
+}
+
+// SupportsPaging reports whether the server supports paged search results.
+func (r *RootDSE) SupportsPaging() bool {
+	return r.SupportsControl(ControlPaging)
+}
+
+// SupportsDirSync reports whether the server supports the DirSync control,
+// which is used to retrieve the changes made since a previous search.
+func (r *RootDSE) SupportsDirSync() bool {
+	return r.SupportsControl(ControlDirSync)
+}
+
+// SupportsSort reports whether the server supports server-side sorting of
+// search results.
+func (r *RootDSE) SupportsSort() bool {
+	return r.SupportsControl(ControlSort)
+}
+
+// SupportsVLV reports whether the server supports virtual list views.
+func (r *RootDSE) SupportsVLV() bool {
+	return r.SupportsControl(ControlVLV)
+}
+
+// IsActiveDirectory reports whether the server is an Active Directory domain
+// controller, as opposed to an AD LDS instance or another LDAP server.
+func (r *RootDSE) IsActiveDirectory() bool {
+	return r.SupportsCapability(CapabilityActiveDirectory)
+}
+
+// optional returns the zero value in place of an error that indicates that an
+// attribute is not populated.
+func optional[T any](value T, err error) (T, error) {
+	if api.IsHresult(err, api.E_ADS_PROPERTY_NOT_FOUND) {
+		var zero T
+		return zero, nil
+	}
+	return value, err
+}
+
+// rootDSEInt converts a normalized value that holds an integer, or the string
+// form of an integer, into an int.
+func rootDSEInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// parseGeneralizedTime parses an LDAP GeneralizedTime value in UTC, such as
+// "20060102150405.0Z".
+func parseGeneralizedTime(s string) (time.Time, error) {
+	return time.Parse("20060102150405.0Z", s)
+}
+
+// containsString reports whether values contains s.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
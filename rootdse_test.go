@@ -0,0 +1,96 @@
+package adsi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRootDSEPredicates(t *testing.T) {
+	ad := &RootDSE{
+		SupportedControls:     []string{ControlPaging, ControlSort, ControlDirSync, ControlShowDeleted},
+		SupportedCapabilities: []string{CapabilityActiveDirectory, CapabilityPartialSecrets},
+	}
+	lds := &RootDSE{
+		SupportedControls:     []string{ControlPaging, ControlVLV},
+		SupportedCapabilities: []string{CapabilityADAM},
+	}
+	for _, tt := range []struct {
+		name string
+		r    *RootDSE
+		pred func(*RootDSE) bool
+		want bool
+	}{
+		{"ad paging", ad, (*RootDSE).SupportsPaging, true},
+		{"ad dirsync", ad, (*RootDSE).SupportsDirSync, true},
+		{"ad sort", ad, (*RootDSE).SupportsSort, true},
+		{"ad vlv", ad, (*RootDSE).SupportsVLV, false},
+		{"ad", ad, (*RootDSE).IsActiveDirectory, true},
+		{"lds paging", lds, (*RootDSE).SupportsPaging, true},
+		{"lds dirsync", lds, (*RootDSE).SupportsDirSync, false},
+		{"lds sort", lds, (*RootDSE).SupportsSort, false},
+		{"lds vlv", lds, (*RootDSE).SupportsVLV, true},
+		{"lds", lds, (*RootDSE).IsActiveDirectory, false},
+		{"empty paging", &RootDSE{}, (*RootDSE).SupportsPaging, false},
+		{"empty", &RootDSE{}, (*RootDSE).IsActiveDirectory, false},
+	} {
+		if got := tt.pred(tt.r); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !ad.SupportsControl(ControlShowDeleted) || ad.SupportsControl(ControlShowDeleted+".1") {
+		t.Error("SupportsControl does not match object identifiers exactly")
+	}
+	if !ad.SupportsCapability(CapabilityPartialSecrets) || lds.SupportsCapability(CapabilityPartialSecrets) {
+		t.Error("SupportsCapability does not match the advertised capabilities")
+	}
+}
+
+func TestFunctionalLevelString(t *testing.T) {
+	for _, tt := range []struct {
+		l    FunctionalLevel
+		want string
+	}{
+		{Windows2000, "Windows 2000"},
+		{Windows2016, "Windows Server 2016"},
+		{Windows2025, "Windows Server 2025"},
+		{UnknownFunctionality, "unknown"},
+		{FunctionalLevel(8), "FunctionalLevel(8)"},
+	} {
+		if got := tt.l.String(); got != tt.want {
+			t.Errorf("FunctionalLevel(%d).String() = %q, want %q", int(tt.l), got, tt.want)
+		}
+	}
+}
+
+func TestRootDSEInt(t *testing.T) {
+	for _, tt := range []struct {
+		value interface{}
+		want  int
+		ok    bool
+	}{
+		{int64(7), 7, true},
+		{"10", 10, true},
+		{" 3 ", 3, true},
+		{"x", 0, false},
+		{true, 0, false},
+		{nil, 0, false},
+	} {
+		got, ok := rootDSEInt(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("rootDSEInt(%#v) = %d, %v, want %d, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseGeneralizedTime(t *testing.T) {
+	got, err := parseGeneralizedTime("20261019174621.0Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 19, 17, 46, 21, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("parseGeneralizedTime() = %v, want %v", got, want)
+	}
+	if _, err := parseGeneralizedTime("2026-10-19"); err == nil {
+		t.Error("parseGeneralizedTime accepted an invalid time")
+	}
+}
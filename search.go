@@ -0,0 +1,294 @@
+package adsi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unsafe"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+)
+
+// defaultPageSize is the number of results that a search retrieves from the
+// directory at a time unless configured otherwise.
+const defaultPageSize = 1000
+
+// SearchScope is the scope of a directory search.
+type SearchScope int
+
+// Search scopes. The zero value searches the whole subtree.
+const (
+	ScopeSubtree SearchScope = iota
+	ScopeOneLevel
+	ScopeBase
+)
+
+// String returns the LDAP name of the scope.
+func (s SearchScope) String() string {
+	switch s {
+	case ScopeSubtree:
+		return "sub"
+	case ScopeOneLevel:
+		return "one"
+	case ScopeBase:
+		return "base"
+	default:
+		return fmt.Sprintf("SearchScope(%d)", int(s))
+	}
+}
+
+// ads returns the ADS_SCOPE value of the scope.
+func (s SearchScope) ads() int {
+	switch s {
+	case ScopeOneLevel:
+		return api.ADS_SCOPE_ONELEVEL
+	case ScopeBase:
+		return api.ADS_SCOPE_BASE
+	default:
+		return api.ADS_SCOPE_SUBTREE
+	}
+}
+
+// Query describes a directory search made by Client.Search.
+type Query struct {
+	// Filter is the LDAP filter that results must match. If it is empty,
+	// every object matches.
+	Filter string
+
+	// Attributes holds the names of the attributes to return. If it is
+	// empty, every attribute is returned. ADsPath is always returned.
+	Attributes []string
+
+	// Scope is the scope of the search.
+	Scope SearchScope
+
+	// PageSize is the number of results that are retrieved from the
+	// directory at a time. If it is zero, 1000 results are retrieved at a
+	// time.
+	PageSize int
+
+	// SizeLimit is the maximum number of results to return. If it is zero,
+	// the number of results is limited only by the server.
+	SizeLimit int
+}
+
+// SearchResult is an object returned by a directory search.
+type SearchResult struct {
+	// Path is the ADsPath of the object.
+	Path string
+
+	// Attrs holds the values of the returned attributes, keyed by the lower
+	// case name of the attribute.
+	Attrs map[string][]interface{}
+}
+
+// Values returns the values of the named attribute, or nil if it was not
+// returned.
+func (r *SearchResult) Values(name string) []interface{} {
+	return r.Attrs[strings.ToLower(name)]
+}
+
+// Strings returns the string values of the named attribute.
+func (r *SearchResult) Strings(name string) []string {
+	var values []string
+	for _, v := range r.Values(name) {
+		if s, ok := v.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// String returns the first string value of the named attribute, or an empty
+// string if it has none.
+func (r *SearchResult) String(name string) string {
+	if values := r.Strings(name); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Bytes returns the first octet string value of the named attribute, or nil
+// if it has none.
+func (r *SearchResult) Bytes(name string) []byte {
+	for _, v := range r.Values(name) {
+		if b, ok := v.([]byte); ok {
+			return b
+		}
+	}
+	return nil
+}
+
+// Search searches the directory below the object with the given path and
+// returns the objects that match the query. The existing security context of
+// the application and any flags specified via SetFlags are used when making
+// the connection.
+//
+// Search is supported by the LDAP and GC providers.
+func (c *Client) Search(path string, q Query) ([]SearchResult, error) {
+	iunknown, err := c.OpenInterface(path, comiid.IDirectorySearch)
+	if err != nil {
+		return nil, err
+	}
+	ds := (*api.IDirectorySearch)(unsafe.Pointer(iunknown))
+	defer ds.Release()
+	return search(ds, q)
+}
+
+// SearchURL runs the search described by an RFC 4516 LDAP URL, such as one
+// returned by adspath.ParseLDAPURL. The search starts at the base object of
+// the URL, which is bound to through the LDAP provider with the existing
+// security context of the application and any flags specified via SetFlags.
+// Connections for ldaps URLs are made with SSL.
+//
+// URLs with a critical extension are rejected with an error matching
+// ErrUnsupportedExtension, and ldapi URLs are not supported.
+func (c *Client) SearchURL(u *adspath.LDAPURL) ([]SearchResult, error) {
+	path, q, err := URLQuery(u)
+	if err != nil {
+		return nil, err
+	}
+	flags := c.Flags()
+	if u.Scheme == adspath.SchemeLDAPS {
+		flags |= api.ADS_USE_SSL
+	}
+	iunknown, err := c.OpenInterfaceSC(path, "", "", flags, comiid.IDirectorySearch)
+	if err != nil {
+		return nil, err
+	}
+	ds := (*api.IDirectorySearch)(unsafe.Pointer(iunknown))
+	defer ds.Release()
+	return search(ds, q)
+}
+
+// URLQuery returns the ADsPath of the base object and the query of the search
+// described by an RFC 4516 LDAP URL, for use with Client.Search or another
+// Searcher. As required by RFC 4516, the scope defaults to ScopeBase.
+func URLQuery(u *adspath.LDAPURL) (string, Query, error) {
+	for _, e := range u.Extensions {
+		if e.Critical {
+			return "", Query{}, fmt.Errorf("%w: %s", ErrUnsupportedExtension, e.Type)
+		}
+	}
+	p, err := u.ADsPath()
+	if err != nil {
+		return "", Query{}, err
+	}
+	q := Query{Filter: u.Filter, Attributes: u.Attributes, Scope: ScopeBase}
+	switch u.Scope {
+	case adspath.ScopeOne:
+		q.Scope = ScopeOneLevel
+	case adspath.ScopeSub:
+		q.Scope = ScopeSubtree
+	}
+	return p.String(), q, nil
+}
+
+// search runs the query with the given interface.
+func search(ds *api.IDirectorySearch, q Query) ([]SearchResult, error) {
+	pageSize := q.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	prefs := []api.SearchPreference{
+		{Option: api.ADS_SEARCHPREF_SEARCH_SCOPE, Value: q.Scope.ads()},
+		{Option: api.ADS_SEARCHPREF_PAGESIZE, Value: pageSize},
+	}
+	if q.SizeLimit > 0 {
+		prefs = append(prefs, api.SearchPreference{Option: api.ADS_SEARCHPREF_SIZE_LIMIT, Value: q.SizeLimit})
+	}
+	if err := ds.SetSearchPreferences(prefs); err != nil {
+		return nil, err
+	}
+
+	filter := q.Filter
+	if filter == "" {
+		filter = "(objectClass=*)"
+	}
+	attrs := q.Attributes
+	if len(attrs) > 0 && !containsFold(attrs, "ADsPath") {
+		attrs = append(append([]string(nil), attrs...), "ADsPath")
+	}
+	handle, err := ds.ExecuteSearch(filter, attrs)
+	if err != nil {
+		return nil, err
+	}
+	defer ds.CloseSearchHandle(handle)
+
+	var results []SearchResult
+	for {
+		ok, err := ds.GetNextRow(handle)
+		if err != nil {
+			return results, err
+		}
+		if !ok {
+			return results, nil
+		}
+		result, err := searchRow(ds, handle)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+}
+
+// searchRow reads the columns of the current row of a search.
+func searchRow(ds *api.IDirectorySearch, handle api.SearchHandle) (SearchResult, error) {
+	result := SearchResult{Attrs: make(map[string][]interface{})}
+	for {
+		name, ok, err := ds.GetNextColumnName(handle)
+		if err != nil {
+			return result, err
+		}
+		if !ok {
+			return result, nil
+		}
+		column, err := ds.GetColumn(handle, name)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", name, err)
+		}
+		if strings.EqualFold(name, "ADsPath") {
+			if len(column.Values) > 0 {
+				result.Path, _ = column.Values[0].(string)
+			}
+			continue
+		}
+		result.Attrs[strings.ToLower(name)] = column.Values
+	}
+}
+
+// GlobalCatalogPath returns the ADsPath of the root of the global catalog,
+// below which a subtree search covers every domain of the forest.
+func (c *Client) GlobalCatalogPath() (string, error) {
+	gc, err := c.OpenContainer("GC:")
+	if err != nil {
+		return "", err
+	}
+	defer gc.Close()
+	for obj, err := range gc.All() {
+		if err != nil {
+			return "", err
+		}
+		path, err := obj.Path()
+		obj.Close()
+		return path, err
+	}
+	return "", errors.New("no global catalog is available")
+}
+
+// EscapeFilter escapes a value for use in an LDAP search filter, as described
+// in RFC 4515.
+func EscapeFilter(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '*', '(', ')', '\\', 0:
+			fmt.Fprintf(&b, "\\%02x", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,417 @@
+package adsi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/go-ole/go-ole"
+	"github.com/google/uuid"
+	"github.com/scjalliance/comutil"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+)
+
+// ObjectAccess describes access that is granted to a trustee on a directory
+// object.
+type ObjectAccess struct {
+	// Trustee is the security identifier of the user or group that is
+	// granted access, in binary form as stored in objectSid.
+	Trustee []byte
+
+	// Mask holds the api.ADS_RIGHT values that are granted.
+	Mask uint32
+
+	// ObjectType identifies the property, property set, validated write or
+	// extended right that the access applies to. If it is the zero UUID the
+	// access applies to the object as a whole.
+	ObjectType uuid.UUID
+}
+
+// Security descriptor control flags.
+const (
+	sdDACLPresent       = 0x0004
+	sdDACLAutoInherited = 0x0400
+	sdDACLProtected     = 0x1000
+	sdSelfRelative      = 0x8000
+)
+
+// Access control entry types and flags.
+const (
+	aceTypeAccessAllowed          = 0x00
+	aceTypeAccessAllowedObject    = 0x05
+	aceFlagInherited              = 0x10
+	aceObjectTypePresent          = 0x1
+	aceInheritedObjectTypePresent = 0x2
+	aclRevision                   = 2
+	aclRevisionDS                 = 4
+)
+
+// SecurityDescriptor reads the discretionary access control list of the
+// object from the server and returns it as a self-relative security
+// descriptor. The owner, group and system access control list are not
+// included, so that no special privileges are required.
+//
+// SecurityDescriptor is only supported by the LDAP provider.
+func (o *object) SecurityDescriptor() (api.SecurityDescriptor, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	dirobj, err := o.daclObject()
+	if err != nil {
+		return nil, err
+	}
+	defer dirobj.Release()
+	return readSecurityDescriptor(dirobj)
+}
+
+// GrantAccess adds access control entries that allow the given access to the
+// discretionary access control list of the object. Access that is already
+// granted by an explicit entry is not added again. The new entries are
+// placed after the existing explicit entries and before the inherited ones,
+// which keeps the list in canonical order.
+//
+// The modification is reported to the write interceptor of the client as a
+// modification of nTSecurityDescriptor. It is not recorded as a reverse
+// change.
+//
+// GrantAccess is only supported by the LDAP provider.
+func (o *object) GrantAccess(grants ...ObjectAccess) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	if len(grants) == 0 {
+		return nil
+	}
+	dirobj, err := o.daclObject()
+	if err != nil {
+		return err
+	}
+	defer dirobj.Release()
+
+	sd, err := readSecurityDescriptor(dirobj)
+	if err != nil {
+		return err
+	}
+	updated, changed, err := grantAccess(sd, grants)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	e, err := o.writeEvent(WriteSetSecurity)
+	if err != nil {
+		return err
+	}
+	e.Changes = []Change{{Name: "nTSecurityDescriptor"}}
+	return o.client.intercept(e, o.user, func() error {
+		_, err := dirobj.SetObjectAttributes([]api.AttrModification{{
+			Name:        "nTSecurityDescriptor",
+			ControlCode: api.ADS_ATTR_UPDATE,
+			Values:      []interface{}{api.SecurityDescriptor(updated)},
+		}})
+		return err
+	})
+}
+
+// daclObject returns the IDirectoryObject interface of the object, with the
+// security mask of the object limited to the discretionary access control
+// list. The caller must hold a lock on the object and release the returned
+// interface.
+func (o *object) daclObject() (*api.IDirectoryObject, error) {
+	idispatch, err := o.iface.QueryInterface(comutil.GUID(comiid.IADsObjectOptions))
+	if err != nil {
+		return nil, err
+	}
+	options := (*api.IADsObjectOptions)(unsafe.Pointer(idispatch))
+	mask := ole.NewVariant(ole.VT_I4, api.ADS_SECURITY_INFO_DACL)
+	err = options.SetOption(api.ADS_OPTION_SECURITY_MASK, &mask)
+	options.Release()
+	if err != nil {
+		return nil, err
+	}
+
+	iunknown, err := o.iface.QueryInterface(comutil.GUID(comiid.IDirectoryObject))
+	if err != nil {
+		return nil, err
+	}
+	return (*api.IDirectoryObject)(unsafe.Pointer(iunknown)), nil
+}
+
+// readSecurityDescriptor reads nTSecurityDescriptor through the given
+// interface.
+func readSecurityDescriptor(dirobj *api.IDirectoryObject) (api.SecurityDescriptor, error) {
+	attrs, err := dirobj.GetObjectAttributes([]string{"nTSecurityDescriptor"})
+	if err != nil {
+		return nil, err
+	}
+	for _, attr := range attrs {
+		for _, value := range attr.Values {
+			if sd, ok := value.(api.SecurityDescriptor); ok {
+				return sd, nil
+			}
+		}
+	}
+	return nil, errors.New("nTSecurityDescriptor could not be read")
+}
+
+// grantAccess returns a copy of the given self-relative security descriptor
+// with entries that allow the given access added to its discretionary access
+// control list. The copy holds only the discretionary access control list.
+// The returned bool is false if every grant was already present.
+func grantAccess(sd []byte, grants []ObjectAccess) ([]byte, bool, error) {
+	if len(sd) < 20 {
+		return nil, false, errors.New("security descriptor is too short")
+	}
+	control := binary.LittleEndian.Uint16(sd[2:])
+	daclOffset := binary.LittleEndian.Uint32(sd[16:])
+
+	revision := byte(aclRevisionDS)
+	var aces [][]byte
+	if control&sdDACLPresent != 0 && daclOffset != 0 {
+		var err error
+		if revision, aces, err = parseACL(sd, int(daclOffset)); err != nil {
+			return nil, false, err
+		}
+		if revision < aclRevisionDS {
+			revision = aclRevisionDS
+		}
+	}
+
+	// New entries are inserted before the first inherited entry.
+	insert := len(aces)
+	for i, ace := range aces {
+		if ace[1]&aceFlagInherited != 0 {
+			insert = i
+			break
+		}
+	}
+	var added [][]byte
+	for _, g := range grants {
+		ace := allowedObjectACE(g)
+		if containsACE(aces[:insert], ace) || containsACE(added, ace) {
+			continue
+		}
+		added = append(added, ace)
+	}
+	if len(added) == 0 {
+		return sd, false, nil
+	}
+	aces = append(aces[:insert], append(added, aces[insert:]...)...)
+
+	out, err := newSecurityDescriptor(control&(sdDACLAutoInherited|sdDACLProtected), nil, revision, aces)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
+
+// newSecurityDescriptor returns a self-relative security descriptor with the
+// given control flags, owner and discretionary access control list. The owner
+// is omitted if it is nil.
+func newSecurityDescriptor(control uint16, owner []byte, revision byte, aces [][]byte) ([]byte, error) {
+	size := 8
+	for _, ace := range aces {
+		size += len(ace)
+	}
+	if size > 0xFFFF {
+		return nil, errors.New("access control list is too large")
+	}
+
+	var ownerOffset uint32
+	if owner != nil {
+		ownerOffset = 20
+	}
+	var out bytes.Buffer
+	out.Write([]byte{1, 0})
+	binary.Write(&out, binary.LittleEndian, sdSelfRelative|sdDACLPresent|control)
+	binary.Write(&out, binary.LittleEndian, [4]uint32{ownerOffset, 0, 0, 20 + uint32(len(owner))})
+	out.Write(owner)
+	out.Write([]byte{revision, 0})
+	binary.Write(&out, binary.LittleEndian, uint16(size))
+	binary.Write(&out, binary.LittleEndian, uint16(len(aces)))
+	out.Write([]byte{0, 0})
+	for _, ace := range aces {
+		out.Write(ace)
+	}
+	return out.Bytes(), nil
+}
+
+// allowedSIDs returns the security identifiers of the trustees that are
+// allowed access by the entries of the discretionary access control list of
+// the given self-relative security descriptor that grant all of the given
+// access rights.
+func allowedSIDs(sd []byte, mask uint32) ([][]byte, error) {
+	if len(sd) < 20 {
+		return nil, errors.New("security descriptor is too short")
+	}
+	control := binary.LittleEndian.Uint16(sd[2:])
+	daclOffset := binary.LittleEndian.Uint32(sd[16:])
+	if control&sdDACLPresent == 0 || daclOffset == 0 {
+		return nil, nil
+	}
+	_, aces, err := parseACL(sd, int(daclOffset))
+	if err != nil {
+		return nil, err
+	}
+	var sids [][]byte
+	for _, ace := range aces {
+		if len(ace) < 8 || binary.LittleEndian.Uint32(ace[4:])&mask != mask {
+			continue
+		}
+		switch ace[0] {
+		case aceTypeAccessAllowed:
+			sids = append(sids, trimSID(ace[8:]))
+		case aceTypeAccessAllowedObject:
+			if len(ace) < 12 {
+				continue
+			}
+			// The object flags determine which of the optional GUIDs are
+			// present before the security identifier.
+			pos := 12
+			flags := binary.LittleEndian.Uint32(ace[8:])
+			if flags&aceObjectTypePresent != 0 {
+				pos += 16
+			}
+			if flags&aceInheritedObjectTypePresent != 0 {
+				pos += 16
+			}
+			if pos < len(ace) {
+				sids = append(sids, trimSID(ace[pos:]))
+			}
+		}
+	}
+	return sids, nil
+}
+
+// trimSID returns the security identifier at the start of b without any data
+// that follows it in an access control entry.
+func trimSID(b []byte) []byte {
+	if len(b) >= 2 {
+		if n := 8 + 4*int(b[1]); n <= len(b) {
+			return b[:n]
+		}
+	}
+	return b
+}
+
+// allowedACE returns an ACCESS_ALLOWED_ACE that grants the given access to the
+// trustee with the given security identifier.
+func allowedACE(mask uint32, trustee []byte) []byte {
+	var ace bytes.Buffer
+	ace.Write([]byte{aceTypeAccessAllowed, 0, 0, 0})
+	binary.Write(&ace, binary.LittleEndian, mask)
+	ace.Write(trustee)
+	b := ace.Bytes()
+	binary.LittleEndian.PutUint16(b[2:], uint16(len(b)))
+	return b
+}
+
+// FormatSID returns the string form of a binary security identifier, such as
+// "S-1-5-21-1004336348-1177238915-682003330-512".
+func FormatSID(sid []byte) (string, error) {
+	s, ok := adspath.FormatSID(sid)
+	if !ok {
+		return "", ErrInvalidSID
+	}
+	return s, nil
+}
+
+// ParseSID returns the binary form of a security identifier in string form,
+// such as "S-1-5-32-544".
+func ParseSID(s string) ([]byte, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) < 3 || len(parts) > 18 || !strings.EqualFold(parts[0], "S") || parts[1] != "1" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSID, s)
+	}
+	// The identifier authority is decimal, or hexadecimal with a 0x prefix
+	// as written by ConvertSidToStringSid for values of 2^32 and above.
+	var (
+		authority uint64
+		err       error
+	)
+	if hex, ok := strings.CutPrefix(strings.ToLower(parts[2]), "0x"); ok {
+		authority, err = strconv.ParseUint(hex, 16, 48)
+	} else {
+		authority, err = strconv.ParseUint(parts[2], 10, 48)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidSID, s)
+	}
+	sid := []byte{1, byte(len(parts) - 3)}
+	for shift := 40; shift >= 0; shift -= 8 {
+		sid = append(sid, byte(authority>>shift))
+	}
+	for _, part := range parts[3:] {
+		sub, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSID, s)
+		}
+		sid = binary.LittleEndian.AppendUint32(sid, uint32(sub))
+	}
+	return sid, nil
+}
+
+// parseACL returns the revision and entries of the access control list at
+// the given offset of a self-relative security descriptor.
+func parseACL(sd []byte, offset int) (revision byte, aces [][]byte, err error) {
+	if offset+8 > len(sd) {
+		return 0, nil, errors.New("access control list is out of bounds")
+	}
+	revision = sd[offset]
+	count := int(binary.LittleEndian.Uint16(sd[offset+4:]))
+	pos := offset + 8
+	for i := 0; i < count; i++ {
+		if pos+4 > len(sd) {
+			return 0, nil, fmt.Errorf("access control entry %d is out of bounds", i)
+		}
+		size := int(binary.LittleEndian.Uint16(sd[pos+2:]))
+		if size < 4 || pos+size > len(sd) {
+			return 0, nil, fmt.Errorf("access control entry %d has an invalid size", i)
+		}
+		aces = append(aces, sd[pos:pos+size])
+		pos += size
+	}
+	return revision, aces, nil
+}
+
+// allowedObjectACE returns an ACCESS_ALLOWED_OBJECT_ACE that grants the given
+// access.
+func allowedObjectACE(g ObjectAccess) []byte {
+	var ace bytes.Buffer
+	ace.Write([]byte{aceTypeAccessAllowedObject, 0, 0, 0})
+	binary.Write(&ace, binary.LittleEndian, g.Mask)
+	if g.ObjectType == (uuid.UUID{}) {
+		binary.Write(&ace, binary.LittleEndian, uint32(0))
+	} else {
+		binary.Write(&ace, binary.LittleEndian, uint32(aceObjectTypePresent))
+		guid := adspath.WindowsGUID(g.ObjectType)
+		ace.Write(guid[:])
+	}
+	ace.Write(g.Trustee)
+	b := ace.Bytes()
+	binary.LittleEndian.PutUint16(b[2:], uint16(len(b)))
+	return b
+}
+
+// containsACE reports whether aces contains an entry identical to ace.
+func containsACE(aces [][]byte, ace []byte) bool {
+	for _, a := range aces {
+		if bytes.Equal(a, ace) {
+			return true
+		}
+	}
+	return false
+}
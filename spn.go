@@ -0,0 +1,330 @@
+package adsi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"github.com/scjalliance/comutil"
+
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+	"github.com/go-adsi/adsi/spn"
+)
+
+// DuplicateSPNError is returned when a service principal name cannot be added
+// to an object because it is already registered on another object in the
+// forest. It matches ErrDuplicateSPN with errors.Is.
+type DuplicateSPNError struct {
+	// SPN is the service principal name that is already registered.
+	SPN spn.SPN
+
+	// Owners holds the ADsPaths of the objects that the name is registered
+	// on, as returned by the global catalog.
+	Owners []string
+}
+
+// Error returns a description of the duplicate.
+func (e *DuplicateSPNError) Error() string {
+	return fmt.Sprintf("%v: %s is registered on %s", ErrDuplicateSPN, e.SPN, strings.Join(e.Owners, ", "))
+}
+
+// Is reports whether target is ErrDuplicateSPN.
+func (e *DuplicateSPNError) Is(target error) bool {
+	return target == ErrDuplicateSPN
+}
+
+// DuplicateSPN is a service principal name that is registered on more than
+// one object.
+type DuplicateSPN struct {
+	// SPN is the service principal name as it is stored on the first owner.
+	SPN string
+
+	// Owners holds the ADsPaths of the objects that the name is registered
+	// on, as returned by the global catalog.
+	Owners []string
+}
+
+// SPNs returns the service principal names of the object. Stored values that
+// cannot be parsed as service principal names are omitted; they can be read
+// from the servicePrincipalName attribute.
+//
+// SPNs is only supported by the LDAP provider.
+func (o *object) SPNs() ([]spn.SPN, error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+	values, err := o.storedSPNs()
+	if err != nil {
+		return nil, err
+	}
+	spns := make([]spn.SPN, 0, len(values))
+	for _, value := range values {
+		// A malformed value stored by another tool does not prevent the
+		// others from being read.
+		s, err := spn.Parse(value)
+		if err != nil {
+			continue
+		}
+		spns = append(spns, s)
+	}
+	return spns, nil
+}
+
+// AddSPNs adds service principal names to the object. Names that the object
+// already has are skipped. The names are written to the underlying directory
+// store immediately.
+//
+// Before anything is written, the global catalog is searched for other
+// objects in the forest that have any of the names. If one is found, nothing
+// is written and an error matching ErrDuplicateSPN is returned, which joins a
+// *DuplicateSPNError for each of the names that is registered elsewhere. The
+// search requires an object that was opened by a Client.
+//
+// The previous values are recorded as a reverse change that removes the
+// added names.
+//
+// AddSPNs is only supported by the LDAP provider.
+func (o *object) AddSPNs(spns ...spn.SPN) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	if o.client == nil {
+		return ErrNoClient
+	}
+	current, err := o.storedSPNs()
+	if err != nil {
+		return err
+	}
+	held := make(map[string]bool, len(current))
+	for _, value := range current {
+		held[spnKey(value)] = true
+	}
+	var added []spn.SPN
+	for _, s := range spns {
+		if !held[s.Key()] {
+			held[s.Key()] = true
+			added = append(added, s)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	guid, err := o.AttrBytes("objectGUID")
+	if err != nil {
+		return err
+	}
+	if err := o.client.checkSPNs(added, guid); err != nil {
+		return err
+	}
+	values := make([]interface{}, len(added))
+	for i, s := range added {
+		values[i] = s.String()
+	}
+	return o.modifySPNs(api.ADS_ATTR_APPEND, values)
+}
+
+// RemoveSPNs removes service principal names from the object. Names that the
+// object does not have are skipped. The names are written to the underlying
+// directory store immediately.
+//
+// The previous values are recorded as a reverse change that adds the removed
+// names back.
+//
+// RemoveSPNs is only supported by the LDAP provider.
+func (o *object) RemoveSPNs(spns ...spn.SPN) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	current, err := o.storedSPNs()
+	if err != nil {
+		return err
+	}
+	remove := make(map[string]bool, len(spns))
+	for _, s := range spns {
+		remove[s.Key()] = true
+	}
+	// The stored form of each name is removed, which may differ from the
+	// given one in case or formatting.
+	var values []interface{}
+	for _, value := range current {
+		if remove[spnKey(value)] {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return o.modifySPNs(api.ADS_ATTR_DELETE, values)
+}
+
+// storedSPNs reads the values of servicePrincipalName, along with objectGUID,
+// from the directory. The caller must hold a lock on the object.
+func (o *object) storedSPNs() ([]string, error) {
+	if err := o.Pull("servicePrincipalName", "objectGUID"); err != nil {
+		return nil, err
+	}
+	return optional(o.AttrStringSlice("servicePrincipalName"))
+}
+
+// modifySPNs appends or deletes the given values of servicePrincipalName. The
+// caller must hold a lock on the object.
+func (o *object) modifySPNs(control uint32, values []interface{}) error {
+	iunknown, err := o.iface.QueryInterface(comutil.GUID(comiid.IDirectoryObject))
+	if err != nil {
+		return err
+	}
+	dirobj := (*api.IDirectoryObject)(unsafe.Pointer(iunknown))
+	defer dirobj.Release()
+
+	e, err := o.writeEvent(WriteSetInfo)
+	if err != nil {
+		return err
+	}
+	change := Change{Name: "servicePrincipalName", New: values}
+	if control == api.ADS_ATTR_DELETE {
+		change = Change{Name: "servicePrincipalName", Old: values}
+	}
+	e.Changes = []Change{change}
+
+	err = o.client.intercept(e, o.user, func() error {
+		_, err := dirobj.SetObjectAttributes([]api.AttrModification{{
+			Name:        "servicePrincipalName",
+			ControlCode: control,
+			Values:      values,
+		}})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	// Replaying the reverse change deletes the added values or adds the
+	// removed ones.
+	return o.record(ReverseChange{Op: OpModify, Path: e.Path, Attrs: []Change{{Name: "servicePrincipalName", Old: change.New, New: change.Old}}})
+}
+
+// SPNOwners searches the global catalog for the objects in the forest that
+// have the given service principal name and returns their ADsPaths.
+func (c *Client) SPNOwners(s spn.SPN) ([]string, error) {
+	results, err := c.searchSPNs([]spn.SPN{s})
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(results))
+	for i, result := range results {
+		paths[i] = result.Path
+	}
+	return paths, nil
+}
+
+// DuplicateSPNs searches the global catalog for service principal names that
+// are registered on more than one object in the forest. The duplicates are
+// sorted by name and their owners by path.
+func (c *Client) DuplicateSPNs() ([]DuplicateSPN, error) {
+	gc, err := c.GlobalCatalogPath()
+	if err != nil {
+		return nil, err
+	}
+	results, err := c.Search(gc, Query{
+		Filter:     "(servicePrincipalName=*)",
+		Attributes: []string{"servicePrincipalName"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]*DuplicateSPN)
+	for _, result := range results {
+		for _, value := range result.Strings("servicePrincipalName") {
+			key := spnKey(value)
+			d := byKey[key]
+			if d == nil {
+				d = &DuplicateSPN{SPN: value}
+				byKey[key] = d
+			}
+			if !containsString(d.Owners, result.Path) {
+				d.Owners = append(d.Owners, result.Path)
+			}
+		}
+	}
+
+	var duplicates []DuplicateSPN
+	for _, d := range byKey {
+		if len(d.Owners) > 1 {
+			sort.Strings(d.Owners)
+			duplicates = append(duplicates, *d)
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		return strings.ToLower(duplicates[i].SPN) < strings.ToLower(duplicates[j].SPN)
+	})
+	return duplicates, nil
+}
+
+// checkSPNs returns a *DuplicateSPNError if any of the given service
+// principal names is registered on an object in the forest other than the
+// one with the given objectGUID.
+func (c *Client) checkSPNs(spns []spn.SPN, guid []byte) error {
+	results, err := c.searchSPNs(spns)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, s := range spns {
+		dup := &DuplicateSPNError{SPN: s}
+		for _, result := range results {
+			if bytes.Equal(result.Bytes("objectGUID"), guid) {
+				continue
+			}
+			for _, value := range result.Strings("servicePrincipalName") {
+				if spnKey(value) == s.Key() {
+					dup.Owners = append(dup.Owners, result.Path)
+					break
+				}
+			}
+		}
+		if len(dup.Owners) > 0 {
+			errs = append(errs, dup)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// searchSPNs searches the global catalog for the objects that have any of the
+// given service principal names.
+func (c *Client) searchSPNs(spns []spn.SPN) ([]SearchResult, error) {
+	gc, err := c.GlobalCatalogPath()
+	if err != nil {
+		return nil, err
+	}
+	var filter strings.Builder
+	filter.WriteString("(|")
+	for _, s := range spns {
+		fmt.Fprintf(&filter, "(servicePrincipalName=%s)", EscapeFilter(s.String()))
+	}
+	filter.WriteString(")")
+	return c.Search(gc, Query{
+		Filter:     filter.String(),
+		Attributes: []string{"servicePrincipalName", "objectGUID"},
+	})
+}
+
+// spnKey returns the key of a stored service principal name, which is equal
+// for names that the directory considers to be the same. Names that cannot be
+// parsed are compared without regard to case.
+func spnKey(value string) string {
+	if s, err := spn.Parse(value); err == nil {
+		return s.Key()
+	}
+	return strings.ToLower(value)
+}
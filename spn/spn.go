@@ -0,0 +1,189 @@
+// Package spn parses and formats Kerberos service principal names as they are
+// stored in the servicePrincipalName attribute of Active Directory accounts.
+//
+// A service principal name has the form
+//
+//	serviceclass/host[:port][/servicename]
+//
+// such as "HOST/ws01.example.com" or "MSSQLSvc/sql01.example.com:1433". The
+// port may also be the name of an instance of the service, as in
+// "MSSQLSvc/sql01.example.com:SQLEXPRESS".
+// Slashes, colons and backslashes that are part of a component are escaped
+// with a backslash.
+package spn
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalid is returned when a string cannot be parsed as a service
+// principal name.
+var ErrInvalid = errors.New("invalid service principal name")
+
+// SPN is a service principal name.
+type SPN struct {
+	// ServiceClass identifies the kind of service, such as "HOST", "HTTP"
+	// or "MSSQLSvc".
+	ServiceClass string
+
+	// Host is the name of the computer that runs the service.
+	Host string
+
+	// Port is the port of the service, or zero if the name has no port.
+	Port int
+
+	// Instance is the name of the instance of the service that is given in
+	// place of a port, such as "SQLEXPRESS" in
+	// "MSSQLSvc/sql01.example.com:SQLEXPRESS". It is empty if the name has a
+	// numeric port or none.
+	Instance string
+
+	// ServiceName identifies a replicable service or the domain that a
+	// service belongs to. It is empty for most services.
+	ServiceName string
+}
+
+// Parse parses a service principal name.
+func Parse(s string) (SPN, error) {
+	parts := split(s, '/')
+	if len(parts) < 2 || len(parts) > 3 {
+		return SPN{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	var spn SPN
+	spn.ServiceClass = unescape(parts[0])
+	hostport := split(parts[1], ':')
+	switch len(hostport) {
+	case 1:
+	case 2:
+		if hostport[1] == "" {
+			return SPN{}, fmt.Errorf("%w: %q has an empty port", ErrInvalid, s)
+		}
+		if port, err := strconv.Atoi(hostport[1]); isDigits(hostport[1]) && err == nil && port > 0 && port <= 65535 {
+			spn.Port = port
+		} else {
+			spn.Instance = unescape(hostport[1])
+		}
+	default:
+		return SPN{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	spn.Host = unescape(hostport[0])
+	if len(parts) == 3 {
+		spn.ServiceName = unescape(parts[2])
+		if spn.ServiceName == "" {
+			return SPN{}, fmt.Errorf("%w: %q has an empty service name", ErrInvalid, s)
+		}
+	}
+	if spn.ServiceClass == "" || spn.Host == "" {
+		return SPN{}, fmt.Errorf("%w: %q", ErrInvalid, s)
+	}
+	return spn, nil
+}
+
+// MustParse is like Parse but panics if the name cannot be parsed.
+func MustParse(s string) SPN {
+	spn, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return spn
+}
+
+// New returns the service principal name of a service with the given class
+// on the given host.
+func New(serviceClass, host string) SPN {
+	return SPN{ServiceClass: serviceClass, Host: host}
+}
+
+// String returns the service principal name in the form in which it is stored
+// in the directory.
+func (s SPN) String() string {
+	var b strings.Builder
+	b.WriteString(escape(s.ServiceClass))
+	b.WriteByte('/')
+	b.WriteString(escape(s.Host))
+	if s.Port != 0 {
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(s.Port))
+	} else if s.Instance != "" {
+		b.WriteByte(':')
+		b.WriteString(escape(s.Instance))
+	}
+	if s.ServiceName != "" {
+		b.WriteByte('/')
+		b.WriteString(escape(s.ServiceName))
+	}
+	return b.String()
+}
+
+// Key returns a form of the name that is equal for names that the directory
+// considers to be the same, which compares them without regard to case.
+func (s SPN) Key() string {
+	return strings.ToLower(s.String())
+}
+
+// Equal reports whether s and t are the same service principal name.
+func (s SPN) Equal(t SPN) bool {
+	return s.Key() == t.Key()
+}
+
+// split splits s at each unescaped occurrence of sep. Escape sequences are
+// retained in the parts.
+func split(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// isDigits reports whether s consists of decimal digits only.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// escape escapes the characters of a component that would otherwise be taken
+// as separators.
+func escape(s string) string {
+	if !strings.ContainsAny(s, `/:\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '/', ':', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// unescape removes the escape characters from a component.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
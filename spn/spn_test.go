@@ -0,0 +1,91 @@
+package spn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want SPN
+	}{
+		{"HOST/ws01.example.com", SPN{ServiceClass: "HOST", Host: "ws01.example.com"}},
+		{"HOST/WS01", SPN{ServiceClass: "HOST", Host: "WS01"}},
+		{"MSSQLSvc/sql01.example.com:1433", SPN{ServiceClass: "MSSQLSvc", Host: "sql01.example.com", Port: 1433}},
+		{"MSSQLSvc/sql01.example.com:SQLEXPRESS", SPN{ServiceClass: "MSSQLSvc", Host: "sql01.example.com", Instance: "SQLEXPRESS"}},
+		{"ldap/dc1.example.com/example.com", SPN{ServiceClass: "ldap", Host: "dc1.example.com", ServiceName: "example.com"}},
+		{"ldap/dc1.example.com:389/example.com", SPN{ServiceClass: "ldap", Host: "dc1.example.com", Port: 389, ServiceName: "example.com"}},
+		{`HTTP/a\/b`, SPN{ServiceClass: "HTTP", Host: "a/b"}},
+		{`HTTP/a\:b:8080`, SPN{ServiceClass: "HTTP", Host: "a:b", Port: 8080}},
+		{`svc/host:inst\:1`, SPN{ServiceClass: "svc", Host: "host", Instance: "inst:1"}},
+		{`x\\y/host`, SPN{ServiceClass: `x\y`, Host: "host"}},
+	} {
+		got, err := Parse(tt.s)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.s, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.s, got, tt.want)
+		}
+		if s := got.String(); s != tt.s {
+			t.Errorf("Parse(%q).String() = %q", tt.s, s)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"HOST",
+		"HOST/",
+		"/host",
+		"HOST/host/",
+		"a/b/c/d",
+		"HOST/host:",
+		"HOST/host:1:2",
+	} {
+		if _, err := Parse(s); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalid", s, err)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, tt := range []struct {
+		spn  SPN
+		want string
+	}{
+		{New("HOST", "ws01"), "HOST/ws01"},
+		{SPN{ServiceClass: "HTTP", Host: "a/b:c", Port: 80}, `HTTP/a\/b\:c:80`},
+		{SPN{ServiceClass: "MSSQLSvc", Host: "sql01", Instance: "A:B"}, `MSSQLSvc/sql01:A\:B`},
+		{SPN{ServiceClass: "MSSQLSvc", Host: "sql01", Port: 1433, Instance: "ignored"}, "MSSQLSvc/sql01:1433"},
+		{SPN{ServiceClass: "ldap", Host: "dc1", ServiceName: `a\b`}, `ldap/dc1/a\\b`},
+	} {
+		if got := tt.spn.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.spn, got, tt.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	for _, tt := range []struct {
+		a, b  string
+		equal bool
+	}{
+		{"HOST/ws01.example.com", "host/WS01.EXAMPLE.COM", true},
+		{"MSSQLSvc/sql01:SQLEXPRESS", "mssqlsvc/SQL01:sqlexpress", true},
+		{"MSSQLSvc/sql01:1433", "MSSQLSvc/sql01:1434", false},
+		{"MSSQLSvc/sql01:1433", "MSSQLSvc/sql01", false},
+		{"HOST/ws01", "HOST/ws01/example.com", false},
+	} {
+		a, b := MustParse(tt.a), MustParse(tt.b)
+		if got := a.Key() == b.Key(); got != tt.equal {
+			t.Errorf("Key(%q) == Key(%q) is %v, want %v", tt.a, tt.b, got, tt.equal)
+		}
+		if got := a.Equal(b); got != tt.equal {
+			t.Errorf("%q.Equal(%q) = %v, want %v", tt.a, tt.b, got, tt.equal)
+		}
+	}
+}
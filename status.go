@@ -0,0 +1,226 @@
+package adsi
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// DefaultPasswordExpiryWarning is the period before the expiry of a password
+// in which Status reports the password as expiring.
+const DefaultPasswordExpiryWarning = 14 * 24 * time.Hour
+
+// AccountStatus describes whether a user account can be used to log on.
+type AccountStatus struct {
+	// Enabled is true if the account is not disabled.
+	Enabled bool
+
+	// Locked is true if the account is locked out after too many failed
+	// logon attempts.
+	Locked bool
+
+	// UnlockTime is the time at which a locked out account is unlocked
+	// automatically. It is zero if the account is not locked out, remains
+	// locked out until an administrator unlocks it, or if the lockout policy
+	// that applies to it could not be read.
+	UnlockTime time.Time
+
+	// Expired is true if the account has expired.
+	Expired bool
+
+	// ExpiryTime is the time at which the account expires. It is zero if the
+	// account never expires.
+	ExpiryTime time.Time
+
+	// PasswordExpired is true if the password of the account has expired.
+	PasswordExpired bool
+
+	// PasswordExpiring is true if the password has not expired yet but
+	// expires within the warning period.
+	PasswordExpiring bool
+
+	// PasswordExpiryTime is the time at which the password expires. It is
+	// zero if the password never expires or must be changed at the next
+	// logon.
+	PasswordExpiryTime time.Time
+
+	// MustChangePassword is true if the password must be changed at the next
+	// logon.
+	MustChangePassword bool
+
+	// OutsideLogonHours is true if the account is not permitted to log on at
+	// the time of evaluation.
+	OutsideLogonHours bool
+}
+
+// CanLogon reports whether the status permits the account to log on with its
+// current password.
+func (s AccountStatus) CanLogon() bool {
+	return s.Enabled && !s.Locked && !s.Expired && !s.PasswordExpired && !s.MustChangePassword && !s.OutsideLogonHours
+}
+
+// statusAttrs holds the names of the attributes that are read by Status.
+var statusAttrs = []string{
+	"userAccountControl",
+	"msDS-User-Account-Control-Computed",
+	"msDS-UserPasswordExpiryTimeComputed",
+	"lockoutTime",
+	"accountExpires",
+	"pwdLastSet",
+	"logonHours",
+}
+
+// Status evaluates the status of the account at the current time, with a
+// password expiry warning period of DefaultPasswordExpiryWarning.
+//
+// See StatusAt for details.
+func (u *User) Status() (AccountStatus, error) {
+	return u.StatusAt(time.Now(), DefaultPasswordExpiryWarning)
+}
+
+// StatusAt evaluates the status of the account at the given time. Passwords
+// that expire within the given warning period are reported as expiring.
+//
+// The lockout and password expiry state computed by the domain controller in
+// msDS-User-Account-Control-Computed and msDS-UserPasswordExpiryTimeComputed
+// is used when available. Otherwise they are derived from lockoutTime and
+// pwdLastSet using the effective password policy of the user, which requires
+// the user to have been opened by a Client. The effective policy is also
+// needed to report the unlock time of a locked out account.
+//
+// Status is only supported by the LDAP provider.
+func (u *User) StatusAt(now time.Time, warning time.Duration) (s AccountStatus, err error) {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return s, ErrClosed
+	}
+	if err = u.Pull(statusAttrs...); err != nil {
+		return
+	}
+
+	ints := make(map[string]int64, len(statusAttrs))
+	present := make(map[string]bool, len(statusAttrs))
+	for _, name := range statusAttrs[:len(statusAttrs)-1] {
+		v, err := u.AttrInt64(name)
+		if api.IsHresult(err, api.E_ADS_PROPERTY_NOT_FOUND) {
+			continue
+		}
+		if err != nil {
+			return s, err
+		}
+		ints[name], present[name] = v, true
+	}
+	logonHours, err := optional(u.AttrBytes("logonHours"))
+	if err != nil {
+		return
+	}
+
+	// The effective policy is only read if it is needed.
+	var policy *EffectivePolicy
+	effectivePolicy := func() (*EffectivePolicy, error) {
+		if policy != nil {
+			return policy, nil
+		}
+		if u.client == nil {
+			return nil, ErrNoClient
+		}
+		path, err := u.iface.AdsPath()
+		if err != nil {
+			return nil, err
+		}
+		policy, err = u.client.EffectivePolicy(path)
+		return policy, err
+	}
+	return evaluateStatus(ints, present, logonHours, effectivePolicy, now, warning)
+}
+
+// evaluateStatus evaluates the status of an account at the given time from
+// the integer attributes in ints, which holds the attributes that are
+// present, and the logonHours attribute. The effective password policy of
+// the account is read with effectivePolicy if it is needed.
+func evaluateStatus(ints map[string]int64, present map[string]bool, logonHours []byte, effectivePolicy func() (*EffectivePolicy, error), now time.Time, warning time.Duration) (s AccountStatus, err error) {
+	uac := ints["userAccountControl"]
+	computed, hasComputed := ints["msDS-User-Account-Control-Computed"], present["msDS-User-Account-Control-Computed"]
+	lockoutTime := fileTime(ints["lockoutTime"])
+	pwdLastSet := ints["pwdLastSet"]
+
+	s.Enabled = uac&api.ADS_UF_ACCOUNTDISABLE == 0
+
+	if !lockoutTime.IsZero() {
+		// The lockout state is computed by the domain controller if it is
+		// present, in which case a policy that cannot be read only leaves
+		// the unlock time unknown.
+		p, err := effectivePolicy()
+		if err != nil && !hasComputed {
+			return s, err
+		}
+		if p != nil && p.Lockout.Duration > 0 {
+			s.UnlockTime = lockoutTime.Add(p.Lockout.Duration)
+		}
+		if hasComputed {
+			s.Locked = computed&api.ADS_UF_LOCKOUT != 0
+		} else {
+			s.Locked = s.UnlockTime.IsZero() || now.Before(s.UnlockTime)
+		}
+		if !s.Locked {
+			s.UnlockTime = time.Time{}
+		}
+	}
+
+	s.ExpiryTime = fileTime(ints["accountExpires"])
+	s.Expired = !s.ExpiryTime.IsZero() && !now.Before(s.ExpiryTime)
+
+	s.MustChangePassword = present["pwdLastSet"] && pwdLastSet == 0
+	switch {
+	case s.MustChangePassword, uac&api.ADS_UF_DONT_EXPIRE_PASSWD != 0:
+	case present["msDS-UserPasswordExpiryTimeComputed"]:
+		s.PasswordExpiryTime = fileTime(ints["msDS-UserPasswordExpiryTimeComputed"])
+	default:
+		p, err := effectivePolicy()
+		if err != nil {
+			return s, err
+		}
+		if p.Password.MaxAge > 0 {
+			s.PasswordExpiryTime = fileTime(pwdLastSet).Add(p.Password.MaxAge)
+		}
+	}
+	if hasComputed {
+		s.PasswordExpired = computed&api.ADS_UF_PASSWORD_EXPIRED != 0 && !s.MustChangePassword
+	} else {
+		s.PasswordExpired = !s.PasswordExpiryTime.IsZero() && !now.Before(s.PasswordExpiryTime)
+	}
+	s.PasswordExpiring = !s.PasswordExpired && !s.PasswordExpiryTime.IsZero() && s.PasswordExpiryTime.Sub(now) <= warning
+
+	s.OutsideLogonHours = !logonPermitted(logonHours, now)
+	return s, nil
+}
+
+// logonPermitted reports whether the given logonHours value permits a logon
+// at the given time. The value holds a bit for each hour of the week in UTC,
+// starting at midnight on Sunday with the least significant bit of the first
+// byte. A value that is not 21 bytes long permits logons at any time.
+func logonPermitted(hours []byte, t time.Time) bool {
+	if len(hours) != 21 {
+		return true
+	}
+	t = t.UTC()
+	hour := int(t.Weekday())*24 + t.Hour()
+	return hours[hour/8]&(1<<(hour%8)) != 0
+}
+
+// fileTimeEpoch is the number of 100-nanosecond intervals between the start
+// of 1601 and the start of 1970.
+const fileTimeEpoch = 116444736000000000
+
+// fileTime converts a time stored in the directory as the number of
+// 100-nanosecond intervals since the start of 1601 UTC into a time. Zero and
+// the largest value, which both mean never, are converted to the zero time.
+func fileTime(v int64) time.Time {
+	if v <= 0 || v == math.MaxInt64 {
+		return time.Time{}
+	}
+	v -= fileTimeEpoch
+	return time.Unix(v/1e7, (v%1e7)*100).UTC()
+}
@@ -0,0 +1,214 @@
+package adsi
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-adsi/adsi/api"
+)
+
+// toFileTime converts a time into the number of 100-nanosecond intervals
+// since the start of 1601 UTC.
+func toFileTime(t time.Time) int64 {
+	return t.UnixNano()/100 + fileTimeEpoch
+}
+
+func TestEvaluateStatus(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	policy := &EffectivePolicy{
+		Password: PasswordPolicy{MaxAge: 42 * day},
+		Lockout:  LockoutPolicy{Duration: 30 * time.Minute},
+	}
+	errPolicy := errors.New("policy unavailable")
+	allHours := make([]byte, 21)
+	for i := range allHours {
+		allHours[i] = 0xff
+	}
+	// Monday from 12:00 to 13:00 UTC is hour 36 of the week.
+	mondayNoon := make([]byte, 21)
+	mondayNoon[36/8] = 1 << (36 % 8)
+
+	const (
+		uac      = "userAccountControl"
+		computed = "msDS-User-Account-Control-Computed"
+		expiry   = "msDS-UserPasswordExpiryTimeComputed"
+	)
+	normal := int64(api.ADS_UF_NORMAL_ACCOUNT)
+	recent := toFileTime(now.Add(-10 * day))
+
+	for _, tt := range []struct {
+		name       string
+		attrs      map[string]int64
+		logonHours []byte
+		policy     *EffectivePolicy
+		policyErr  error
+		want       AccountStatus
+		wantErr    error
+	}{
+		{
+			name:  "usable",
+			attrs: map[string]int64{uac: normal, computed: 0, expiry: toFileTime(now.Add(60 * day)), "pwdLastSet": recent, "accountExpires": math.MaxInt64},
+			want:  AccountStatus{Enabled: true, PasswordExpiryTime: now.Add(60 * day)},
+		},
+		{
+			name:  "disabled",
+			attrs: map[string]int64{uac: normal | int64(api.ADS_UF_ACCOUNTDISABLE), computed: 0, expiry: math.MaxInt64, "pwdLastSet": recent},
+			want:  AccountStatus{},
+		},
+		{
+			name:   "locked out by the domain controller",
+			attrs:  map[string]int64{uac: normal, computed: int64(api.ADS_UF_LOCKOUT), expiry: math.MaxInt64, "pwdLastSet": recent, "lockoutTime": toFileTime(now.Add(-10 * time.Minute))},
+			policy: policy,
+			want:   AccountStatus{Enabled: true, Locked: true, UnlockTime: now.Add(20 * time.Minute)},
+		},
+		{
+			name:      "locked out without policy",
+			attrs:     map[string]int64{uac: normal, computed: int64(api.ADS_UF_LOCKOUT), expiry: math.MaxInt64, "pwdLastSet": recent, "lockoutTime": toFileTime(now.Add(-10 * time.Minute))},
+			policyErr: errPolicy,
+			want:      AccountStatus{Enabled: true, Locked: true},
+		},
+		{
+			name:   "lockout expired",
+			attrs:  map[string]int64{uac: normal, "pwdLastSet": recent, "lockoutTime": toFileTime(now.Add(-40 * time.Minute))},
+			policy: policy,
+			want:   AccountStatus{Enabled: true, PasswordExpiryTime: now.Add(32 * day)},
+		},
+		{
+			name:   "locked out until unlocked",
+			attrs:  map[string]int64{uac: normal | int64(api.ADS_UF_DONT_EXPIRE_PASSWD), "pwdLastSet": recent, "lockoutTime": toFileTime(now.Add(-40 * time.Minute))},
+			policy: &EffectivePolicy{},
+			want:   AccountStatus{Enabled: true, Locked: true},
+		},
+		{
+			name:      "lockout unknown",
+			attrs:     map[string]int64{uac: normal, "pwdLastSet": recent, "lockoutTime": toFileTime(now.Add(-10 * time.Minute))},
+			policyErr: errPolicy,
+			wantErr:   errPolicy,
+		},
+		{
+			name:  "account expired",
+			attrs: map[string]int64{uac: normal, computed: 0, expiry: math.MaxInt64, "pwdLastSet": recent, "accountExpires": toFileTime(now)},
+			want:  AccountStatus{Enabled: true, Expired: true, ExpiryTime: now},
+		},
+		{
+			name:  "must change password",
+			attrs: map[string]int64{uac: normal, computed: int64(api.ADS_UF_PASSWORD_EXPIRED), expiry: math.MaxInt64, "pwdLastSet": 0},
+			want:  AccountStatus{Enabled: true, MustChangePassword: true},
+		},
+		{
+			name:      "password never expires",
+			attrs:     map[string]int64{uac: normal | int64(api.ADS_UF_DONT_EXPIRE_PASSWD), "pwdLastSet": recent},
+			policyErr: errPolicy,
+			want:      AccountStatus{Enabled: true},
+		},
+		{
+			name:   "password expiring",
+			attrs:  map[string]int64{uac: normal, "pwdLastSet": toFileTime(now.Add(-40 * day))},
+			policy: policy,
+			want:   AccountStatus{Enabled: true, PasswordExpiring: true, PasswordExpiryTime: now.Add(2 * day)},
+		},
+		{
+			name:   "password expired",
+			attrs:  map[string]int64{uac: normal, "pwdLastSet": toFileTime(now.Add(-50 * day))},
+			policy: policy,
+			want:   AccountStatus{Enabled: true, PasswordExpired: true, PasswordExpiryTime: now.Add(-8 * day)},
+		},
+		{
+			name:  "password expired by the domain controller",
+			attrs: map[string]int64{uac: normal, computed: int64(api.ADS_UF_PASSWORD_EXPIRED), expiry: toFileTime(now.Add(-time.Hour)), "pwdLastSet": recent},
+			want:  AccountStatus{Enabled: true, PasswordExpired: true, PasswordExpiryTime: now.Add(-time.Hour)},
+		},
+		{
+			name:      "password expiry unknown",
+			attrs:     map[string]int64{uac: normal, "pwdLastSet": recent},
+			policyErr: errPolicy,
+			wantErr:   errPolicy,
+		},
+		{
+			name:       "outside logon hours",
+			attrs:      map[string]int64{uac: normal, computed: 0, expiry: math.MaxInt64, "pwdLastSet": recent},
+			logonHours: make([]byte, 21),
+			want:       AccountStatus{Enabled: true, OutsideLogonHours: true},
+		},
+		{
+			name:       "within logon hours",
+			attrs:      map[string]int64{uac: normal, computed: 0, expiry: math.MaxInt64, "pwdLastSet": recent},
+			logonHours: mondayNoon,
+			want:       AccountStatus{Enabled: true},
+		},
+		{
+			name:       "all logon hours",
+			attrs:      map[string]int64{uac: normal, computed: 0, expiry: math.MaxInt64, "pwdLastSet": recent},
+			logonHours: allHours,
+			want:       AccountStatus{Enabled: true},
+		},
+	} {
+		present := make(map[string]bool, len(tt.attrs))
+		for name := range tt.attrs {
+			present[name] = true
+		}
+		effectivePolicy := func() (*EffectivePolicy, error) {
+			if tt.policyErr != nil {
+				return nil, tt.policyErr
+			}
+			if tt.policy == nil {
+				t.Errorf("%s: the effective policy was read", tt.name)
+				return &EffectivePolicy{}, nil
+			}
+			return tt.policy, nil
+		}
+		got, err := evaluateStatus(tt.attrs, present, tt.logonHours, effectivePolicy, now, 14*day)
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got\n%+v\nwant\n%+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAccountStatusCanLogon(t *testing.T) {
+	ok := AccountStatus{Enabled: true, PasswordExpiring: true, PasswordExpiryTime: time.Now()}
+	if !ok.CanLogon() {
+		t.Errorf("%+v.CanLogon() = false", ok)
+	}
+	for _, s := range []AccountStatus{
+		{},
+		{Enabled: true, Locked: true},
+		{Enabled: true, Expired: true},
+		{Enabled: true, PasswordExpired: true},
+		{Enabled: true, MustChangePassword: true},
+		{Enabled: true, OutsideLogonHours: true},
+	} {
+		if s.CanLogon() {
+			t.Errorf("%+v.CanLogon() = true", s)
+		}
+	}
+}
+
+func TestFileTime(t *testing.T) {
+	for _, tt := range []struct {
+		v    int64
+		want time.Time
+	}{
+		{0, time.Time{}},
+		{-1, time.Time{}},
+		{math.MaxInt64, time.Time{}},
+		{fileTimeEpoch, time.Unix(0, 0).UTC()},
+		{133000000000000000, time.Date(2022, 6, 18, 4, 26, 40, 0, time.UTC)},
+	} {
+		if got := fileTime(tt.v); !got.Equal(tt.want) {
+			t.Errorf("fileTime(%d) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
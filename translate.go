@@ -24,10 +24,15 @@ See also https://docs.microsoft.com/en-us/windows/win32/api/iads/nn-iads-iadsnam
 package adsi
 
 import (
+	"errors"
+	"fmt"
 	"sync"
+	"unsafe"
 
 	"github.com/go-adsi/adsi/api"
+	"github.com/go-ole/go-ole"
 	"github.com/scjalliance/comshim"
+	"github.com/scjalliance/comutil"
 )
 
 // NameTranslator provides active directory name translation services via the
@@ -100,3 +105,205 @@ func (nt *NameTranslator) Set(adsPath string, setType uint32) error {
 	}
 	return nt.iface.Set(adsPath, setType)
 }
+
+// InitEx initializes the translator like Init, binding to the directory with
+// the given credentials instead of those of the current user.
+func (nt *NameTranslator) InitEx(adsPath string, initType uint32, user, domain, password string) error {
+	nt.m.Lock()
+	defer nt.m.Unlock()
+	if nt.closed() {
+		return ErrClosed
+	}
+	return nt.iface.InitEx(adsPath, initType, user, domain, password)
+}
+
+// ChaseReferral sets whether the translator follows referrals to other
+// domains and forests. See ADS_CHASE_REFERRALS_ENUM for possible values.
+// ChaseReferral must be called after Init.
+func (nt *NameTranslator) ChaseReferral(referral uint32) error {
+	nt.m.Lock()
+	defer nt.m.Unlock()
+	if nt.closed() {
+		return ErrClosed
+	}
+	return nt.iface.ChaseReferral(int32(referral))
+}
+
+// SetEx sets several input objects to be translated, in the format matching
+// setType. If any of the names cannot be resolved, an error is returned.
+// SetEx must be called after Init and before GetEx.
+func (nt *NameTranslator) SetEx(names []string, setType uint32) error {
+	nt.m.Lock()
+	defer nt.m.Unlock()
+	if nt.closed() {
+		return ErrClosed
+	}
+	return nt.setEx(names, setType)
+}
+
+// GetEx returns the names of the objects set by SetEx in the specified
+// format, in the order in which they were set. GetEx must be called last.
+func (nt *NameTranslator) GetEx(formatType uint32) ([]string, error) {
+	nt.m.Lock()
+	defer nt.m.Unlock()
+	if nt.closed() {
+		return nil, ErrClosed
+	}
+	return nt.getEx(formatType)
+}
+
+// TranslateResult is the outcome of translating a single name.
+type TranslateResult struct {
+	// Name is the name that was translated.
+	Name string
+
+	// Result is the translated name. It is empty if Err is not nil.
+	Result string
+
+	// Err is the error that prevented the name from being translated, if
+	// any. It matches ErrNameNotFound if no object has the name and
+	// ErrNameNotUnique if more than one does.
+	Err error
+}
+
+// Translate translates each of the names from one format to another and
+// returns a result for each name, in the same order. Init must have been
+// called first.
+//
+// The names are translated in a single request. If that fails because one of
+// them cannot be translated, each name is translated separately so that the
+// others still produce results.
+//
+// NameTypeDNSDomain is supported in either direction and is converted with
+// ConvertName, with the directory translating to or from NameType1779 as
+// needed.
+func (nt *NameTranslator) Translate(names []string, from, to NameType) ([]TranslateResult, error) {
+	nt.m.Lock()
+	defer nt.m.Unlock()
+	if nt.closed() {
+		return nil, ErrClosed
+	}
+
+	results := make([]TranslateResult, len(names))
+	input := make([]string, 0, len(names))
+	index := make([]int, 0, len(names))
+	dsFrom, dsTo := from, to
+	if from == NameTypeDNSDomain {
+		dsFrom = NameType1779
+	}
+	if to == NameTypeDNSDomain {
+		dsTo = NameType1779
+	}
+	for i, name := range names {
+		results[i].Name = name
+		if from == NameTypeDNSDomain {
+			dn, err := ConvertName(name, from, NameType1779)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			name = dn
+		}
+		input = append(input, name)
+		index = append(index, i)
+	}
+
+	var (
+		output []string
+		errs   []error
+	)
+	if dsFrom == dsTo {
+		output, errs = input, make([]error, len(input))
+	} else {
+		output, errs = nt.translate(input, uint32(dsFrom), uint32(dsTo))
+	}
+	for j, i := range index {
+		if errs[j] != nil {
+			results[i].Err = errs[j]
+			continue
+		}
+		result := output[j]
+		if to == NameTypeDNSDomain {
+			var err error
+			if result, err = ConvertName(result, NameType1779, to); err != nil {
+				results[i].Err = err
+				continue
+			}
+		}
+		results[i].Result = result
+	}
+	return results, nil
+}
+
+// translate translates the names in a single request, falling back to
+// translating them one at a time if that fails. It returns the translated
+// names and the error for each of them.
+func (nt *NameTranslator) translate(names []string, from, to uint32) ([]string, []error) {
+	output := make([]string, len(names))
+	errs := make([]error, len(names))
+	if len(names) == 0 {
+		return output, errs
+	}
+	if err := nt.setEx(names, from); err == nil {
+		if values, err := nt.getEx(to); err == nil && len(values) == len(names) {
+			return values, errs
+		}
+	}
+	for i, name := range names {
+		err := nt.iface.Set(name, from)
+		if err == nil {
+			output[i], err = nt.iface.Get(to)
+		}
+		if err != nil {
+			errs[i] = translateError(name, err)
+		}
+	}
+	return output, errs
+}
+
+func (nt *NameTranslator) setEx(names []string, setType uint32) error {
+	array := comutil.SafeArrayFromStringSlice(names)
+	variant := ole.NewVariant(ole.VT_ARRAY|ole.VT_BSTR, int64(uintptr(unsafe.Pointer(array))))
+	v := &variant
+	defer v.Clear()
+	return nt.iface.SetEx(setType, v)
+}
+
+func (nt *NameTranslator) getEx(formatType uint32) ([]string, error) {
+	variant, err := nt.iface.GetEx(formatType)
+	if err != nil {
+		return nil, err
+	}
+	defer variant.Clear()
+
+	array := variant.ToArray()
+	if array == nil {
+		return nil, errors.New("translated names are not an array")
+	}
+	values, err := comutil.SafeArrayToVariantSlice(array)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(values))
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("translated name %d is a %T", i, value)
+		}
+		names[i] = s
+	}
+	return names, nil
+}
+
+// translateError returns the error for a name that could not be translated,
+// wrapping ErrNameNotFound or ErrNameNotUnique where appropriate.
+func translateError(name string, err error) error {
+	switch {
+	case api.IsHresult(err, api.E_DS_NAME_ERROR_NOT_FOUND), api.IsHresult(err, api.E_DS_NAME_ERROR_NO_MAPPING):
+		return fmt.Errorf("%w: %q: %v", ErrNameNotFound, name, err)
+	case api.IsHresult(err, api.E_DS_NAME_ERROR_NOT_UNIQUE):
+		return fmt.Errorf("%w: %q: %v", ErrNameNotUnique, name, err)
+	default:
+		return fmt.Errorf("%q: %w", name, err)
+	}
+}
@@ -0,0 +1,615 @@
+package adsi
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unsafe"
+
+	"github.com/go-adsi/adsi/adspath"
+	"github.com/go-adsi/adsi/api"
+	"github.com/go-adsi/adsi/comiid"
+	"github.com/scjalliance/comutil"
+)
+
+// ReverseOp identifies the kind of operation performed by a ReverseChange.
+type ReverseOp int
+
+// Reverse change operations.
+const (
+	// OpModify modifies the attributes of the object at Path.
+	OpModify ReverseOp = iota + 1
+
+	// OpAdd creates an object of class Class with the relative name Name in
+	// the container at Parent and populates it with Attrs.
+	OpAdd
+
+	// OpDelete deletes the object at Path, along with any objects that it
+	// contains.
+	OpDelete
+
+	// OpMove moves the object at Path into the container at Parent and gives
+	// it the relative name Name.
+	OpMove
+
+	// OpAddMember adds the object at Name to the group at Path.
+	OpAddMember
+
+	// OpRemoveMember removes the object at Name from the group at Path.
+	OpRemoveMember
+)
+
+var reverseOpNames = map[ReverseOp]string{
+	OpModify:       "modify",
+	OpAdd:          "add",
+	OpDelete:       "delete",
+	OpMove:         "move",
+	OpAddMember:    "addmember",
+	OpRemoveMember: "removemember",
+}
+
+// String returns the name of the operation.
+func (op ReverseOp) String() string {
+	if name, ok := reverseOpNames[op]; ok {
+		return name
+	}
+	return fmt.Sprintf("ReverseOp(%d)", int(op))
+}
+
+// MarshalText returns the name of the operation.
+func (op ReverseOp) MarshalText() ([]byte, error) {
+	if _, ok := reverseOpNames[op]; !ok {
+		return nil, fmt.Errorf("invalid reverse operation %d", int(op))
+	}
+	return []byte(op.String()), nil
+}
+
+// UnmarshalText parses the name of an operation.
+func (op *ReverseOp) UnmarshalText(text []byte) error {
+	for value, name := range reverseOpNames {
+		if name == string(text) {
+			*op = value
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid reverse operation %q", text)
+}
+
+// ReverseChange describes a directory operation that reverses a modification
+// made through this package. Reverse changes can be replayed with
+// Client.Replay, written as LDIF with WriteLDIF or persisted in an
+// UndoJournal.
+type ReverseChange struct {
+	// Op is the operation to perform.
+	Op ReverseOp `json:"op"`
+
+	// Path is the ADsPath of the object that the operation applies to. For
+	// OpAdd it is the path that the object had before it was deleted.
+	Path string `json:"path"`
+
+	// Class is the schema class of the object to create for OpAdd. It is
+	// informational for other operations.
+	Class string `json:"class,omitempty"`
+
+	// Parent is the ADsPath of the container that the object is created in
+	// or moved to for OpAdd and OpMove.
+	Parent string `json:"parent,omitempty"`
+
+	// Name is the relative name of the object for OpAdd and OpMove, such as
+	// "CN=Jane Doe". For OpAddMember and OpRemoveMember it is the ADsPath of
+	// the member.
+	Name string `json:"name,omitempty"`
+
+	// Attrs holds the attribute modifications to apply for OpModify and the
+	// attribute values to populate for OpAdd.
+	//
+	// When replayed, values in Old that are not in New are deleted and the
+	// values in New are added. If both Old and New are populated the
+	// attribute is replaced with New.
+	Attrs []Change `json:"attrs,omitempty"`
+
+	// Incomplete is set for OpAdd when the attributes or descendants of the
+	// deleted object could not be read before it was deleted. Replaying the
+	// change recreates the object without them, if at all.
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// String returns a short human-readable description of the reverse change.
+func (rc ReverseChange) String() string {
+	switch rc.Op {
+	case OpAdd:
+		if rc.Incomplete {
+			return fmt.Sprintf("add %s %s in %s (incomplete)", rc.Class, rc.Name, rc.Parent)
+		}
+		return fmt.Sprintf("add %s %s in %s", rc.Class, rc.Name, rc.Parent)
+	case OpMove:
+		return fmt.Sprintf("move %s to %s in %s", rc.Path, rc.Name, rc.Parent)
+	case OpAddMember:
+		return fmt.Sprintf("add %s to %s", rc.Name, rc.Path)
+	case OpRemoveMember:
+		return fmt.Sprintf("remove %s from %s", rc.Name, rc.Path)
+	default:
+		return fmt.Sprintf("%s %s", rc.Op, rc.Path)
+	}
+}
+
+// Undo returns the reverse changes that restore the state of the directory
+// before the modifications made through the object, in the order in which
+// they must be replayed. The most recent modification is reversed first.
+//
+// Reverse changes are recorded by SetInfo, SetInfoIf, CompareAndSwap, Delete
+// and MoveTo. Groups also record reverse changes for Add and Remove.
+func (o *object) Undo() []ReverseChange {
+	o.m.RLock()
+	defer o.m.RUnlock()
+	return copyReverseChanges(o.undo)
+}
+
+// record adds reverse changes to the undo history of the object and to the
+// undo journal of its client, if any. The changes must be in replay order.
+// The caller must hold a lock on the object.
+func (o *object) record(changes ...ReverseChange) error {
+	o.undo = append(copyReverseChanges(changes), o.undo...)
+	return o.client.recordUndo(changes)
+}
+
+// create writes an object returned by Container.Create to the directory. The
+// caller must hold a lock on the object.
+func (o *object) create() error {
+	rc := ReverseChange{Op: OpDelete}
+	var err error
+	if rc.Path, err = o.iface.AdsPath(); err != nil {
+		return err
+	}
+	if rc.Class, err = o.iface.Class(); err != nil {
+		return err
+	}
+	if rc.Name, err = o.iface.Name(); err != nil {
+		return err
+	}
+
+	e := WriteEvent{Op: WriteCreate, Path: rc.Path, Class: rc.Class, Changes: o.changes}
+	if err := o.client.intercept(e, o.user, o.iface.SetInfo); err != nil {
+		return err
+	}
+	o.created = false
+	o.changes = nil
+	o.conditions = nil
+	return o.record(rc)
+}
+
+// Delete deletes the object from the underlying directory store, along with
+// any objects that it contains. Once deleted, the object should be closed.
+//
+// Before the object is deleted, the attributes of the object and its
+// descendants are read and recorded as reverse changes that recreate them.
+// Recreation is a best effort: attributes that are maintained by the
+// directory, such as objectGUID and objectSid, cannot be restored and group
+// memberships are restored by adding the object back to its groups. If the
+// attributes can't be read, the object is deleted anyway and the reverse
+// change that recreates it is marked as Incomplete. Where the Active
+// Directory Recycle Bin is available, restoring a deleted object from it
+// preserves its identity.
+//
+// The LDAP provider deletes the object with the IADsDeleteOps interface.
+// Other providers delete the object through its parent container, which
+// requires an object opened by a Client.
+func (o *object) Delete() error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	path, err := o.iface.AdsPath()
+	if err != nil {
+		return err
+	}
+	class, err := o.iface.Class()
+	if err != nil {
+		return err
+	}
+	var reverse []ReverseChange
+	e := WriteEvent{Op: WriteDelete, Path: path, Class: class}
+	err = o.client.intercept(e, o.user, func() error {
+		reverse = o.deleteImage()
+		return o.deleteObject()
+	})
+	if err != nil {
+		return err
+	}
+	o.changes = nil
+	o.conditions = nil
+	return o.record(reverse...)
+}
+
+// deleteObject deletes the object and its descendants. The caller must hold a
+// lock on the object.
+func (o *object) deleteObject() error {
+	idispatch, err := o.iface.QueryInterface(comutil.GUID(comiid.IADsDeleteOps))
+	if err == nil {
+		defer idispatch.Release()
+		ops := (*api.IADsDeleteOps)(unsafe.Pointer(idispatch))
+		return ops.DeleteObject(0)
+	}
+	if o.client == nil {
+		return err
+	}
+
+	parent, err := o.iface.Parent()
+	if err != nil {
+		return err
+	}
+	class, err := o.iface.Class()
+	if err != nil {
+		return err
+	}
+	name, err := o.iface.Name()
+	if err != nil {
+		return err
+	}
+	c, err := o.client.OpenContainer(parent)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	return c.iface.Delete(class, name)
+}
+
+// MoveTo moves the object into the given container and returns the object at
+// its new location. If a relative name is provided the object is renamed as
+// well. Once moved, the original object should be closed.
+//
+// The move is recorded as a reverse change. The returned object carries the
+// undo history of the original object.
+func (o *object) MoveTo(dest *Container, name string) (moved *Object, err error) {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return nil, ErrClosed
+	}
+
+	path, err := o.iface.AdsPath()
+	if err != nil {
+		return
+	}
+	rc := ReverseChange{Op: OpMove}
+	if rc.Parent, err = o.iface.Parent(); err != nil {
+		return
+	}
+	if rc.Class, err = o.iface.Class(); err != nil {
+		return
+	}
+	if rc.Name, err = o.iface.Name(); err != nil {
+		return
+	}
+
+	target, err := dest.Path()
+	if err != nil {
+		return
+	}
+	e := WriteEvent{Op: WriteMove, Path: path, Class: rc.Class, Target: target, Name: name}
+	err = o.client.intercept(e, o.user, func() (err error) {
+		moved, err = dest.moveHere(path, name)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rc.Path, err = moved.iface.AdsPath(); err != nil {
+		moved.Close()
+		return nil, err
+	}
+	if err = o.record(rc); err != nil {
+		moved.Close()
+		return nil, err
+	}
+	moved.undo = copyReverseChanges(o.undo)
+	return moved, nil
+}
+
+// deleteImage returns the reverse changes that recreate the object and its
+// descendants once it is deleted. If they can't be read, it returns a single
+// incomplete change that recreates the object alone. The caller must hold a
+// lock on the object.
+func (o *object) deleteImage() []ReverseChange {
+	reverse, err := o.preImage()
+	if err == nil {
+		return reverse
+	}
+	rc := ReverseChange{Op: OpAdd, Incomplete: true}
+	rc.Path, _ = o.iface.AdsPath()
+	rc.Parent, _ = o.iface.Parent()
+	rc.Class, _ = o.iface.Class()
+	rc.Name, _ = o.iface.Name()
+	return []ReverseChange{rc}
+}
+
+// preImage returns the reverse changes that recreate the object and its
+// descendants, in replay order. The caller must hold a lock on the object.
+func (o *object) preImage() ([]ReverseChange, error) {
+	if err := o.iface.GetInfo(); err != nil {
+		return nil, err
+	}
+	rc := ReverseChange{Op: OpAdd}
+	var err error
+	if rc.Path, err = o.iface.AdsPath(); err != nil {
+		return nil, err
+	}
+	if rc.Parent, err = o.iface.Parent(); err != nil {
+		return nil, err
+	}
+	if rc.Class, err = o.iface.Class(); err != nil {
+		return nil, err
+	}
+	if rc.Name, err = o.iface.Name(); err != nil {
+		return nil, err
+	}
+	attrs, err := o.attributes()
+	if err != nil {
+		return nil, err
+	}
+
+	naming, _, _ := strings.Cut(rc.Name, "=")
+	var links []ReverseChange
+	for _, attr := range attrs {
+		values := normalizeValues(attr.Values)
+		switch {
+		case len(values) == 0:
+		case strings.EqualFold(attr.Name, "memberOf"):
+			links = append(links, memberOfReverse(rc.Path, values)...)
+		case strings.EqualFold(attr.Name, naming) || !restorable(attr.Name):
+		default:
+			rc.Attrs = append(rc.Attrs, Change{Name: attr.Name, New: values})
+		}
+	}
+
+	children, err := o.childPreImages()
+	if err != nil {
+		return nil, err
+	}
+	changes := append([]ReverseChange{rc}, children...)
+	return append(changes, links...), nil
+}
+
+// childPreImages returns the reverse changes that recreate the descendants of
+// the object, in replay order. The caller must hold a lock on the object.
+func (o *object) childPreImages() (changes []ReverseChange, err error) {
+	idispatch, err := o.iface.QueryInterface(comutil.GUID(comiid.IADsContainer))
+	if err != nil {
+		// Objects that aren't containers have no descendants.
+		return nil, nil
+	}
+	c := NewContainer((*api.IADsContainer)(unsafe.Pointer(idispatch)))
+	defer c.Close()
+
+	iter, err := c.Children()
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	for {
+		child, err := iter.Next()
+		if err == io.EOF {
+			return changes, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		image, err := child.preImage()
+		child.Close()
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, image...)
+	}
+}
+
+// unrestorableAttrs holds the lower case names of attributes that are
+// maintained by the directory and cannot be written when an object is
+// recreated.
+var unrestorableAttrs = map[string]bool{
+	"admincount":                 true,
+	"badpasswordtime":            true,
+	"badpwdcount":                true,
+	"distinguishedname":          true,
+	"dscorepropagationdata":      true,
+	"instancetype":               true,
+	"iscriticalsystemobject":     true,
+	"isdeleted":                  true,
+	"lastknownparent":            true,
+	"lastlogoff":                 true,
+	"lastlogon":                  true,
+	"lastlogontimestamp":         true,
+	"lockouttime":                true,
+	"logoncount":                 true,
+	"msds-keyversionnumber":      true,
+	"name":                       true,
+	"ntsecuritydescriptor":       true,
+	"objectcategory":             true,
+	"objectclass":                true,
+	"objectguid":                 true,
+	"objectsid":                  true,
+	"primarygroupid":             true,
+	"pwdlastset":                 true,
+	"replpropertymetadata":       true,
+	"samaccounttype":             true,
+	"sidhistory":                 true,
+	"systemflags":                true,
+	"usnchanged":                 true,
+	"usncreated":                 true,
+	"whenchanged":                true,
+	"whencreated":                true,
+	"msds-replattributemetadata": true,
+}
+
+// restorable reports whether the named attribute can be written when an
+// object is recreated. Secret attributes are not restored, so that their
+// values are never held in undo history.
+func restorable(name string) bool {
+	name = strings.ToLower(name)
+	return !unrestorableAttrs[name] && !secretAttrs[name] && !strings.HasPrefix(name, "msds-user-account-control-computed")
+}
+
+// memberOfReverse returns reverse changes that add the object at path back to
+// each of the groups with the given distinguished names.
+func memberOfReverse(path string, groups []interface{}) []ReverseChange {
+	p, err := adspath.Parse(path)
+	if err != nil {
+		return nil
+	}
+	var changes []ReverseChange
+	for _, group := range groups {
+		dn, ok := group.(string)
+		if !ok {
+			continue
+		}
+		gp := adspath.FromDN(p.Scheme, p.Host, dn)
+		changes = append(changes, ReverseChange{Op: OpAddMember, Path: gp.String(), Name: path})
+	}
+	return changes
+}
+
+// modifyReverse returns a reverse change that undoes the given committed
+// attribute modifications of the object at path. Modifications of secret
+// attributes, such as passwords, are not reversible and are left out, so that
+// their values are never held in undo history, journals or LDIF.
+func modifyReverse(path string, changes []Change) ReverseChange {
+	rc := ReverseChange{Op: OpModify, Path: path}
+	for _, c := range changes {
+		if secretAttrs[strings.ToLower(c.Name)] {
+			continue
+		}
+		rc.Attrs = append(rc.Attrs, Change{Name: c.Name, Old: c.New, New: c.Old})
+	}
+	return rc
+}
+
+// copyReverseChanges returns a deep copy of the given reverse changes.
+func copyReverseChanges(changes []ReverseChange) []ReverseChange {
+	if len(changes) == 0 {
+		return nil
+	}
+	result := make([]ReverseChange, len(changes))
+	for i, rc := range changes {
+		result[i] = rc
+		if rc.Attrs != nil {
+			result[i].Attrs = make([]Change, len(rc.Attrs))
+			for j, c := range rc.Attrs {
+				result[i].Attrs[j] = Change{
+					Name: c.Name,
+					Old:  append([]interface{}(nil), c.Old...),
+					New:  append([]interface{}(nil), c.New...),
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Replay performs the given reverse changes in order, such as those returned
+// by Undo or ReadUndoJournal. It stops at the first change that fails and
+// returns an error that identifies it.
+//
+// Replayed changes are themselves recorded in the undo journal of the client,
+// if any, so that they can be undone in turn.
+func (c *Client) Replay(changes []ReverseChange) error {
+	for i, rc := range changes {
+		if err := c.replay(rc); err != nil {
+			return fmt.Errorf("replay change %d (%v): %w", i, rc, err)
+		}
+	}
+	return nil
+}
+
+func (c *Client) replay(rc ReverseChange) error {
+	switch rc.Op {
+	case OpModify:
+		obj, err := c.Open(rc.Path)
+		if err != nil {
+			return err
+		}
+		defer obj.Close()
+		if err := obj.applyChanges(rc.Attrs); err != nil {
+			return err
+		}
+		return obj.SetInfo()
+	case OpAdd:
+		parent, err := c.OpenContainer(rc.Parent)
+		if err != nil {
+			return err
+		}
+		defer parent.Close()
+		obj, err := parent.Create(rc.Class, rc.Name)
+		if err != nil {
+			return err
+		}
+		defer obj.Close()
+		if err := obj.applyChanges(rc.Attrs); err != nil {
+			return err
+		}
+		return obj.SetInfo()
+	case OpDelete:
+		obj, err := c.Open(rc.Path)
+		if err != nil {
+			return err
+		}
+		defer obj.Close()
+		return obj.Delete()
+	case OpMove:
+		parent, err := c.OpenContainer(rc.Parent)
+		if err != nil {
+			return err
+		}
+		defer parent.Close()
+		moved, err := parent.MoveHere(rc.Path, rc.Name)
+		if err != nil {
+			return err
+		}
+		moved.Close()
+		return nil
+	case OpAddMember, OpRemoveMember:
+		obj, err := c.Open(rc.Path)
+		if err != nil {
+			return err
+		}
+		defer obj.Close()
+		g, err := obj.ToGroup()
+		if err != nil {
+			return err
+		}
+		defer g.Close()
+		if rc.Op == OpAddMember {
+			return g.Add(rc.Name)
+		}
+		return g.Remove(rc.Name)
+	default:
+		return fmt.Errorf("invalid reverse operation %d", int(rc.Op))
+	}
+}
+
+// applyChanges stages the given attribute modifications in the property
+// cache. Values in Old that are not in New are deleted and the values in New
+// are added. If both are populated the attribute is replaced with New.
+func (o *object) applyChanges(changes []Change) error {
+	o.m.Lock()
+	defer o.m.Unlock()
+	if o.closed() {
+		return ErrClosed
+	}
+	for _, c := range changes {
+		var err error
+		switch {
+		case len(c.New) == 0 && len(c.Old) == 0:
+		case len(c.New) == 0:
+			err = o.putEx(c.Name, api.ADS_PROPERTY_DELETE, c.Old)
+		case len(c.Old) == 0 && !o.created:
+			err = o.putEx(c.Name, api.ADS_PROPERTY_APPEND, c.New)
+		default:
+			err = o.putEx(c.Name, api.ADS_PROPERTY_UPDATE, c.New)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package adsi
 
 import (
+	"github.com/go-adsi/adsi/adspath"
 	"github.com/go-adsi/adsi/api"
 	"github.com/scjalliance/comshim"
 )
@@ -45,14 +46,54 @@ func (u *User) AccountDisabled() (disabled bool, err error) {
 	return u.iface.AccountDisabled()
 }
 
-// SetAccountDisabled sets an account as disabled.
+// SetAccountDisabled sets an account as disabled. The value must be
+// commited with SetInfo to be made persistent.
+//
+// The modification is tracked as a change of the userAccountControl
+// attribute, or of UserFlags for the WinNT provider, and can be inspected
+// with Changes.
 func (u *User) SetAccountDisabled(val bool) (err error) {
 	u.m.Lock()
 	defer u.m.Unlock()
 	if u.closed() {
 		return ErrClosed
 	}
-	return u.iface.SetAccountDisabled(val)
+	name, err := u.flagsAttr()
+	if err != nil {
+		return err
+	}
+	next := func(current []interface{}) []interface{} {
+		var flags int64
+		if len(current) > 0 {
+			flags, _ = current[0].(int64)
+		}
+		if val {
+			flags |= api.ADS_UF_ACCOUNTDISABLE
+		} else {
+			flags &^= api.ADS_UF_ACCOUNTDISABLE
+		}
+		return []interface{}{flags}
+	}
+	return u.stage(name, next, func() error {
+		return u.iface.SetAccountDisabled(val)
+	})
+}
+
+// flagsAttr returns the name of the attribute that holds the account flags
+// of the user. The caller must hold a lock on the user.
+func (u *User) flagsAttr() (string, error) {
+	path, err := u.iface.AdsPath()
+	if err != nil {
+		return "", err
+	}
+	p, err := adspath.Parse(path)
+	if err != nil {
+		return "", err
+	}
+	if p.Scheme == adspath.WinNT {
+		return "UserFlags", nil
+	}
+	return "userAccountControl", nil
 }
 
 // FullName returns the user's FullName property.
@@ -64,3 +105,52 @@ func (u *User) FullName() (string, error) {
 	}
 	return u.iface.FullName()
 }
+
+// SetPassword sets the password of the user. The password is written to the
+// underlying directory store immediately rather than being cached until
+// SetInfo is called.
+//
+// Password operations are reported to the write interceptor of the client
+// without the password. They are not recorded as reverse changes because the
+// previous password cannot be read.
+func (u *User) SetPassword(password string) error {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return ErrClosed
+	}
+	return u.setPassword(password)
+}
+
+// setPassword sets the password of the user. The caller must hold a lock on
+// the user.
+func (u *User) setPassword(password string) error {
+	e, err := u.writeEvent(WriteSetPassword)
+	if err != nil {
+		return err
+	}
+	return u.client.intercept(e, u.user, func() error {
+		return u.iface.SetPassword(password)
+	})
+}
+
+// ChangePassword changes the password of the user from old to new. The
+// password is written to the underlying directory store immediately.
+//
+// Like SetPassword, password changes are reported to the write interceptor
+// of the client without the passwords and are not recorded as reverse
+// changes.
+func (u *User) ChangePassword(old, new string) error {
+	u.m.Lock()
+	defer u.m.Unlock()
+	if u.closed() {
+		return ErrClosed
+	}
+	e, err := u.writeEvent(WriteChangePassword)
+	if err != nil {
+		return err
+	}
+	return u.client.intercept(e, u.user, func() error {
+		return u.iface.ChangePassword(old, new)
+	})
+}
@@ -2,6 +2,8 @@ package adsi
 
 import (
 	"errors"
+	"math"
+	"time"
 	"unsafe"
 
 	ole "github.com/go-ole/go-ole"
@@ -18,6 +20,83 @@ func reverseUint32(v uint32) uint32 {
 	return v>>24&0x000000ff | v>>8&0x0000ff00 | v<<8&0x00ff0000 | v<<24&0xff000000
 }
 
+// newVariant returns a VARIANT holding the given value. The value must be a
+// string, bool, int, int32, uint32, int64, []byte or time.Time.
+//
+// It is the caller's responsibility to clear the returned variant.
+func newVariant(value interface{}) (*ole.VARIANT, error) {
+	var v ole.VARIANT
+	switch x := value.(type) {
+	case string:
+		v = ole.NewVariant(ole.VT_BSTR, int64(uintptr(unsafe.Pointer(ole.SysAllocStringLen(x)))))
+	case bool:
+		if x {
+			v = ole.NewVariant(ole.VT_BOOL, -1)
+		} else {
+			v = ole.NewVariant(ole.VT_BOOL, 0)
+		}
+	case int:
+		v = ole.NewVariant(ole.VT_I4, int64(x))
+	case int32:
+		v = ole.NewVariant(ole.VT_I4, int64(x))
+	case uint32:
+		v = ole.NewVariant(ole.VT_I4, int64(int32(x)))
+	case int64:
+		v = ole.NewVariant(ole.VT_I8, x)
+	case time.Time:
+		v = ole.NewVariant(ole.VT_DATE, int64(math.Float64bits(oleDate(x))))
+	case []byte:
+		array, err := comutil.SafeArrayCreateVector(ole.VT_UI1, 0, uint32(len(x)))
+		if err != nil {
+			return nil, err
+		}
+		v = ole.NewVariant(ole.VT_ARRAY|ole.VT_UI1, int64(uintptr(unsafe.Pointer(array))))
+		for i := range x {
+			if err := comutil.SafeArrayPutElement(array, int32(i), unsafe.Pointer(&x[i])); err != nil {
+				v.Clear()
+				return nil, err
+			}
+		}
+	default:
+		return nil, ErrUnsupportedValue
+	}
+	return &v, nil
+}
+
+// oleDate returns the OLE automation date for the given time, which is the
+// number of days since midnight on 30 December 1899 in UTC.
+func oleDate(t time.Time) float64 {
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	return t.Sub(epoch).Hours() / 24
+}
+
+// newVariantArray returns a VARIANT array holding the given values, each of
+// which is stored as a VARIANT. The values must be of a type supported by
+// newVariant.
+//
+// It is the caller's responsibility to clear the returned variant.
+func newVariantArray(values []interface{}) (*ole.VARIANT, error) {
+	array, err := comutil.SafeArrayCreateVector(ole.VT_VARIANT, 0, uint32(len(values)))
+	if err != nil {
+		return nil, err
+	}
+	v := ole.NewVariant(ole.VT_ARRAY|ole.VT_VARIANT, int64(uintptr(unsafe.Pointer(array))))
+	for i, value := range values {
+		element, err := newVariant(value)
+		if err != nil {
+			v.Clear()
+			return nil, err
+		}
+		err = comutil.SafeArrayPutElement(array, int32(i), unsafe.Pointer(element))
+		element.Clear()
+		if err != nil {
+			v.Clear()
+			return nil, err
+		}
+	}
+	return &v, nil
+}
+
 func dispatchToInt64(v *ole.IDispatch) (value int64, err error) {
 	if v == nil {
 		return 0, errors.New("nil IDispatch interface")
@@ -39,3 +118,24 @@ func dispatchToInt64(v *ole.IDispatch) (value int64, err error) {
 
 	return 0, errors.New("unsupported COM interface for integer conversion")
 }
+
+// releaseValues releases any IUnknown or IDispatch members of the given
+// attribute values.
+func releaseValues(values []interface{}) {
+	for _, value := range values {
+		switch v := value.(type) {
+		case *ole.IUnknown:
+			v.Release()
+		case *ole.IDispatch:
+			v.Release()
+		}
+	}
+}
+
+// releaseAttributes releases any IUnknown or IDispatch members of the values
+// of the given attributes.
+func releaseAttributes(attrs []Attribute) {
+	for _, attr := range attrs {
+		releaseValues(attr.Values)
+	}
+}
@@ -0,0 +1,225 @@
+package adsi
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// SkipDir is used as a return value from a WalkFunc to indicate that the
+	// children of the object named in the call are to be skipped. It is not
+	// returned as an error by any function.
+	SkipDir = errors.New("skip this container")
+
+	// SkipAll is used as a return value from a WalkFunc to indicate that all
+	// remaining objects are to be skipped. It is not returned as an error by
+	// any function.
+	SkipAll = errors.New("skip everything and stop the walk")
+)
+
+// WalkFunc is the type of the function called by Walk to visit each object.
+//
+// The path argument is the ADsPath of the object and depth is its distance
+// from the root of the walk, which has a depth of zero.
+//
+// The object is only valid for the duration of the call. Walk releases it
+// once its children have been enumerated, so the function must not retain it.
+// Objects that are needed after the walk should be opened again by path.
+//
+// The error result returned by the function controls how Walk continues. If
+// the function returns SkipDir, Walk skips the children of the object. If the
+// function returns SkipAll, Walk skips all remaining objects and returns nil.
+// If the function returns any other non-nil error, Walk stops entirely and
+// returns that error.
+//
+// The err argument reports an error related to the object, in which case the
+// function decides how that error is handled. Walk calls the function with a
+// non-nil err in two cases:
+//
+// First, if the path or class of the object cannot be retrieved, the function
+// is called with that error and path may be empty. If the function returns
+// nil, Walk still attempts to visit the children of the object.
+//
+// Second, if the children of the object cannot be enumerated, the function is
+// called a second time for the same object with the enumeration error. Any
+// children visited before the failure remain visited.
+type WalkFunc func(path string, obj *Object, depth int, err error) error
+
+// WalkOptions control the behavior of Walk.
+type WalkOptions struct {
+	// MaxDepth limits the depth of the walk. Objects deeper than MaxDepth are
+	// not visited. The root has a depth of zero. A value of zero or less means
+	// that the depth is unlimited.
+	MaxDepth int
+
+	// Classes restricts the objects passed to the walk function to those with
+	// one of the given classes. The comparison is case-insensitive. The
+	// filter does not prevent Walk from visiting the children of an object
+	// with some other class.
+	Classes []string
+
+	// Workers is the maximum number of goroutines used to visit objects. When
+	// it is less than 2, objects are visited sequentially in enumeration order
+	// and the walk function is never called concurrently.
+	Workers int
+}
+
+// Walk walks the directory tree rooted at root, calling fn for each object in
+// the tree, including root.
+//
+// When opts.Workers is greater than one, containers are enumerated
+// concurrently and fn may be called from several goroutines at once. The
+// order in which objects are visited is then unspecified.
+//
+// Walk releases every object it acquires, including when fn returns an error.
+// The root object is owned by the caller and is not closed by Walk.
+func Walk(root *Object, fn WalkFunc, opts *WalkOptions) error {
+	w := newWalker(fn, opts)
+	w.visit(root, 0)
+	w.wg.Wait()
+	return w.err
+}
+
+type walker struct {
+	fn       WalkFunc
+	maxDepth int
+	classes  map[string]bool
+	sem      chan struct{}
+	wg       sync.WaitGroup
+	stopped  atomic.Bool
+	once     sync.Once
+	err      error
+}
+
+func newWalker(fn WalkFunc, opts *WalkOptions) *walker {
+	w := &walker{fn: fn}
+	if opts == nil {
+		return w
+	}
+	w.maxDepth = opts.MaxDepth
+	if len(opts.Classes) > 0 {
+		w.classes = make(map[string]bool, len(opts.Classes))
+		for _, class := range opts.Classes {
+			w.classes[strings.ToLower(class)] = true
+		}
+	}
+	if opts.Workers > 1 {
+		// The calling goroutine counts as a worker.
+		w.sem = make(chan struct{}, opts.Workers-1)
+	}
+	return w
+}
+
+// stop halts the walk. The first error recorded is the one returned by Walk.
+func (w *walker) stop(err error) {
+	w.once.Do(func() {
+		w.err = err
+		w.stopped.Store(true)
+	})
+}
+
+func (w *walker) done() bool {
+	return w.stopped.Load()
+}
+
+// call invokes the walk function and reports whether the children of the
+// object should be visited.
+func (w *walker) call(path string, obj *Object, depth int, err error) bool {
+	if w.done() {
+		return false
+	}
+	switch result := w.fn(path, obj, depth, err); result {
+	case nil:
+		return true
+	case SkipDir:
+		return false
+	case SkipAll:
+		w.stop(nil)
+		return false
+	default:
+		w.stop(result)
+		return false
+	}
+}
+
+// matches reports whether the given object passes the class filter.
+func (w *walker) matches(obj *Object) (bool, error) {
+	if w.classes == nil {
+		return true, nil
+	}
+	class, err := obj.Class()
+	if err != nil {
+		return true, err
+	}
+	return w.classes[strings.ToLower(class)], nil
+}
+
+func (w *walker) visit(obj *Object, depth int) {
+	if w.done() {
+		return
+	}
+
+	path, err := obj.Path()
+	match := true
+	if err == nil {
+		match, err = w.matches(obj)
+	}
+	if match && !w.call(path, obj, depth, err) {
+		return
+	}
+
+	if w.maxDepth > 0 && depth >= w.maxDepth {
+		return
+	}
+
+	c, err := obj.ToContainer()
+	if err != nil {
+		// Objects that aren't containers have no children to visit.
+		return
+	}
+	defer c.Close()
+
+	iter, err := c.Children()
+	if err != nil {
+		w.call(path, obj, depth, err)
+		return
+	}
+	defer iter.Close()
+
+	for !w.done() {
+		child, err := iter.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			w.call(path, obj, depth, err)
+			return
+		}
+		w.dispatch(child, depth+1)
+	}
+}
+
+// dispatch visits the given child on a new goroutine if a worker is
+// available, otherwise it visits the child on the current goroutine. The
+// child is closed once it has been visited.
+func (w *walker) dispatch(child *Object, depth int) {
+	select {
+	case w.sem <- struct{}{}:
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			defer func() { <-w.sem }()
+			w.visitAndClose(child, depth)
+		}()
+	default:
+		w.visitAndClose(child, depth)
+	}
+}
+
+func (w *walker) visitAndClose(obj *Object, depth int) {
+	defer obj.Close()
+	w.visit(obj, depth)
+}